@@ -0,0 +1,168 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/pusher/navarchos/pkg/apis"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const usage = `kubectl navarchos operates NodeRollouts and NodeReplacements.
+
+Usage:
+  kubectl navarchos <command> [flags]
+
+Commands:
+  status <rollout>         Show a NodeRollout and its NodeReplacements
+  pause <rollout>          Pause a NodeRollout
+  resume <rollout>         Resume a paused NodeRollout
+  abort <rollout>          Abort a NodeRollout
+  retry-failed <rollout>   Replace the failed NodeReplacements of a NodeRollout
+  replace <node>           Create a NodeReplacement for a single node
+  version                  Print the version of the plugin
+
+Use "kubectl navarchos <command> -h" for the flags of a command.
+`
+
+// scheme knows the Kubernetes and Navarchos types used by the plugin
+var scheme = k8sruntime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apis.AddToScheme(scheme)
+}
+
+// command runs a subcommand with a client for the cluster and the positional
+// arguments, writing its output to out
+type command func(c client.Client, args []string, out io.Writer) error
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	var err error
+	switch name {
+	case "status":
+		err = run(name, args, 1, runStatus)
+	case "pause":
+		err = run(name, args, 1, runPause)
+	case "resume":
+		err = run(name, args, 1, runResume)
+	case "abort":
+		err = run(name, args, 1, runAbort)
+	case "retry-failed":
+		err = run(name, args, 1, runRetryFailed)
+	case "replace":
+		err = runReplace(args)
+	case "version":
+		fmt.Printf("kubectl-navarchos %s (built with %s)\n", VERSION, runtime.Version())
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the flags of a subcommand that takes no flags of its own, checks
+// it was given the expected number of arguments and runs it
+func run(name string, args []string, nArgs int, cmd command) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	clientFlags := addClientFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err == flag.ErrHelp {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) != nArgs {
+		return fmt.Errorf("%s requires exactly %d argument(s), got %d", name, nArgs, len(positional))
+	}
+
+	c, err := clientFlags.newClient()
+	if err != nil {
+		return err
+	}
+	return cmd(c, positional, os.Stdout)
+}
+
+// clientFlags are the flags selecting the cluster the plugin talks to
+type clientFlags struct {
+	kubeconfig *string
+	context    *string
+}
+
+// addClientFlags adds the flags selecting the cluster to the FlagSet
+func addClientFlags(fs *flag.FlagSet) *clientFlags {
+	return &clientFlags{
+		kubeconfig: fs.String("kubeconfig", "", "Path to the kubeconfig file, defaults to the kubeconfig used by kubectl"),
+		context:    fs.String("context", "", "Name of the kubeconfig context to use"),
+	}
+}
+
+// newClient creates a client for the cluster selected by the flags, loading
+// the kubeconfig the same way kubectl does
+func (f *clientFlags) newClient() (client.Client, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = *f.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: *f.context}
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig: %v", err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %v", err)
+	}
+	return c, nil
+}
+
+// parseArgs parses the flags of the FlagSet wherever they appear in the
+// arguments, as kubectl does, and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
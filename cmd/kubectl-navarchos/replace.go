@@ -0,0 +1,95 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// runReplace creates a NodeReplacement for the node given in the arguments.
+// The NodeReplacement is not part of any NodeRollout
+func runReplace(args []string) error {
+	fs := flag.NewFlagSet("replace", flag.ContinueOnError)
+	clientFlags := addClientFlags(fs)
+	priority := fs.Int("priority", 0, "Priority of the NodeReplacement, higher priorities are replaced sooner")
+	positional, err := parseArgs(fs, args)
+	if err == flag.ErrHelp {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("replace requires exactly 1 argument(s), got %d", len(positional))
+	}
+
+	c, err := clientFlags.newClient()
+	if err != nil {
+		return err
+	}
+
+	node := &corev1.Node{}
+	err = c.Get(context.Background(), types.NamespacedName{Name: positional[0]}, node)
+	if err != nil {
+		return fmt.Errorf("error getting node %s: %v", positional[0], err)
+	}
+
+	replacement := newNodeReplacement(node, *priority)
+	err = c.Create(context.Background(), replacement)
+	if err != nil {
+		return fmt.Errorf("error creating NodeReplacement: %v", err)
+	}
+	fmt.Fprintf(os.Stdout, "nodereplacement.navarchos.pusher.com/%s created\n", replacement.GetName())
+	return nil
+}
+
+// newNodeReplacement returns a NodeReplacement for the node with the given
+// priority, owned by the node
+func newNodeReplacement(node *corev1.Node, priority int) *navarchosv1alpha1.NodeReplacement {
+	isController := false
+	blockOwnerDeletion := false
+	return &navarchosv1alpha1.NodeReplacement{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", node.GetName()),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         "v1",
+					Kind:               "Node",
+					Name:               node.GetName(),
+					UID:                node.GetUID(),
+					Controller:         &isController,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: navarchosv1alpha1.NodeReplacementSpec{
+			NodeName: node.GetName(),
+			NodeUID:  node.GetUID(),
+			ReplacementSpec: navarchosv1alpha1.ReplacementSpec{
+				Priority: &priority,
+			},
+		},
+	}
+}
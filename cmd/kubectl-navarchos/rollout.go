@@ -0,0 +1,168 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runPause pauses the NodeRollout
+func runPause(c client.Client, args []string, out io.Writer) error {
+	err := updateRollout(c, args[0], func(rollout *navarchosv1alpha1.NodeRollout) {
+		rollout.Spec.Paused = true
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "noderollout.navarchos.pusher.com/%s paused\n", args[0])
+	return nil
+}
+
+// runResume resumes the NodeRollout
+func runResume(c client.Client, args []string, out io.Writer) error {
+	err := updateRollout(c, args[0], func(rollout *navarchosv1alpha1.NodeRollout) {
+		rollout.Spec.Paused = false
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "noderollout.navarchos.pusher.com/%s resumed\n", args[0])
+	return nil
+}
+
+// runAbort aborts the NodeRollout
+func runAbort(c client.Client, args []string, out io.Writer) error {
+	err := updateRollout(c, args[0], func(rollout *navarchosv1alpha1.NodeRollout) {
+		rollout.Spec.Abort = true
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "noderollout.navarchos.pusher.com/%s aborted\n", args[0])
+	return nil
+}
+
+// updateRollout gets the NodeRollout, applies the mutation to it and updates
+// it, retrying on conflicts
+func updateRollout(c client.Client, name string, mutate func(*navarchosv1alpha1.NodeRollout)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		rollout, err := getRollout(c, name)
+		if err != nil {
+			return err
+		}
+		mutate(rollout)
+		return c.Update(context.Background(), rollout)
+	})
+}
+
+// runRetryFailed replaces each failed NodeReplacement of the NodeRollout with
+// a new NodeReplacement for the same node. The new NodeReplacement starts
+// from the beginning, the failed one is deleted once it has been created.
+// A NodeRollout finishes as soon as none of its NodeReplacements are still
+// running, so once it has finished its failed nodes must be retried by a new
+// NodeRollout instead
+func runRetryFailed(c client.Client, args []string, out io.Writer) error {
+	rollout, err := getRollout(c, args[0])
+	if err != nil {
+		return err
+	}
+	if rollout.Status.CompletionTimestamp != nil {
+		if len(rollout.Status.ReplacementsFailed) == 0 {
+			return fmt.Errorf("NodeRollout %s has already finished in phase %s", rollout.GetName(), rollout.Status.Phase)
+		}
+		return fmt.Errorf("NodeRollout %s has already finished in phase %s, create a new NodeRollout to retry node(s): %s", rollout.GetName(), rollout.Status.Phase, strings.Join(rollout.Status.ReplacementsFailed, ", "))
+	}
+
+	replacements, err := ownedReplacements(c, rollout)
+	if err != nil {
+		return err
+	}
+
+	retried := 0
+	for _, replacement := range replacements {
+		if replacement.Status.Phase != navarchosv1alpha1.ReplacementPhaseFailed {
+			continue
+		}
+		renewed := newRetryReplacement(&replacement)
+		err = c.Create(context.Background(), renewed)
+		if err != nil {
+			return fmt.Errorf("error creating NodeReplacement for node %s: %v", replacement.Spec.NodeName, err)
+		}
+		err = c.Delete(context.Background(), &replacement)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting failed NodeReplacement %s: %v", replacement.GetName(), err)
+		}
+		fmt.Fprintf(out, "nodereplacement.navarchos.pusher.com/%s replaced by %s\n", replacement.GetName(), renewed.GetName())
+		retried++
+	}
+
+	if retried == 0 {
+		fmt.Fprintf(out, "NodeRollout %s has no failed NodeReplacements\n", rollout.GetName())
+	}
+	return nil
+}
+
+// newRetryReplacement returns a new NodeReplacement with the spec and owners
+// of the failed NodeReplacement
+func newRetryReplacement(failed *navarchosv1alpha1.NodeReplacement) *navarchosv1alpha1.NodeReplacement {
+	return &navarchosv1alpha1.NodeReplacement{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    fmt.Sprintf("%s-", failed.Spec.NodeName),
+			Labels:          failed.GetLabels(),
+			Annotations:     failed.GetAnnotations(),
+			OwnerReferences: failed.GetOwnerReferences(),
+		},
+		Spec: *failed.Spec.DeepCopy(),
+	}
+}
+
+// getRollout gets the NodeRollout with the given name
+func getRollout(c client.Client, name string) (*navarchosv1alpha1.NodeRollout, error) {
+	rollout := &navarchosv1alpha1.NodeRollout{}
+	err := c.Get(context.Background(), types.NamespacedName{Name: name}, rollout)
+	if err != nil {
+		return nil, fmt.Errorf("error getting NodeRollout %s: %v", name, err)
+	}
+	return rollout, nil
+}
+
+// ownedReplacements lists the NodeReplacements controlled by the NodeRollout
+func ownedReplacements(c client.Client, rollout *navarchosv1alpha1.NodeRollout) ([]navarchosv1alpha1.NodeReplacement, error) {
+	replacementList := &navarchosv1alpha1.NodeReplacementList{}
+	err := c.List(context.Background(), replacementList)
+	if err != nil {
+		return nil, fmt.Errorf("error listing NodeReplacements: %v", err)
+	}
+
+	owned := []navarchosv1alpha1.NodeReplacement{}
+	for _, replacement := range replacementList.Items {
+		if metav1.IsControlledBy(&replacement, rollout) {
+			owned = append(owned, replacement)
+		}
+	}
+	return owned, nil
+}
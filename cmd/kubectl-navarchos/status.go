@@ -0,0 +1,150 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runStatus prints the NodeRollout and its NodeReplacements as a tree, along
+// with the progress of the NodeRollout
+func runStatus(c client.Client, args []string, out io.Writer) error {
+	rollout, err := getRollout(c, args[0])
+	if err != nil {
+		return err
+	}
+	replacements, err := ownedReplacements(c, rollout)
+	if err != nil {
+		return err
+	}
+	printTree(out, rollout, replacements)
+	return nil
+}
+
+// printTree writes the NodeRollout followed by its NodeReplacements, highest
+// priority first, one per line
+func printTree(out io.Writer, rollout *navarchosv1alpha1.NodeRollout, replacements []navarchosv1alpha1.NodeReplacement) {
+	sort.Slice(replacements, func(i, j int) bool {
+		pi, pj := replacementPriority(&replacements[i]), replacementPriority(&replacements[j])
+		if pi != pj {
+			return pi > pj
+		}
+		return replacements[i].GetName() < replacements[j].GetName()
+	})
+
+	phases := make(map[navarchosv1alpha1.NodeReplacementPhase]int)
+	for _, replacement := range replacements {
+		phases[replacement.Status.Phase]++
+	}
+	fmt.Fprintf(out, "NodeRollout %s  %s  %s\n", rollout.GetName(), rolloutPhase(rollout), progress(len(replacements), phases))
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for i, replacement := range replacements {
+		branch := "├──"
+		if i == len(replacements)-1 {
+			branch = "└──"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\tpriority %d\t%s\t%s\n", branch, replacement.GetName(), replacement.Spec.NodeName, replacementPriority(&replacement), replacementPhase(&replacement), replacementMessage(&replacement))
+	}
+	w.Flush()
+}
+
+// rolloutPhase returns the phase of the NodeRollout, noting whether it is a
+// dry run, paused or being aborted
+func rolloutPhase(rollout *navarchosv1alpha1.NodeRollout) string {
+	phase := string(rollout.Status.Phase)
+	if phase == "" {
+		phase = string(navarchosv1alpha1.RolloutPhaseNew)
+	}
+	if rollout.Status.CompletionTimestamp != nil {
+		return phase
+	}
+	switch {
+	case rollout.Spec.Abort:
+		return phase + " (aborting)"
+	case rollout.Spec.DryRun:
+		return fmt.Sprintf("%s (dry run, %d node(s) planned)", phase, len(rollout.Status.Plan))
+	case rollout.Spec.Paused:
+		return phase + " (paused)"
+	}
+	return phase
+}
+
+// progress summarises how many of the NodeReplacements have finished
+func progress(total int, phases map[navarchosv1alpha1.NodeReplacementPhase]int) string {
+	finished := phases[navarchosv1alpha1.ReplacementPhaseCompleted] + phases[navarchosv1alpha1.ReplacementPhaseFailed] + phases[navarchosv1alpha1.ReplacementPhaseAborted]
+	parts := []string{fmt.Sprintf("%d/%d finished", finished, total)}
+	for _, phase := range []navarchosv1alpha1.NodeReplacementPhase{
+		navarchosv1alpha1.ReplacementPhaseCompleted,
+		navarchosv1alpha1.ReplacementPhaseFailed,
+		navarchosv1alpha1.ReplacementPhaseAborted,
+	} {
+		if phases[phase] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", phases[phase], strings.ToLower(string(phase))))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// replacementPriority returns the priority of the NodeReplacement, defaulting
+// to 0
+func replacementPriority(replacement *navarchosv1alpha1.NodeReplacement) int {
+	if replacement.Spec.ReplacementSpec.Priority == nil {
+		return 0
+	}
+	return *replacement.Spec.ReplacementSpec.Priority
+}
+
+// replacementPhase returns the phase of the NodeReplacement, New if it has
+// not been handled yet
+func replacementPhase(replacement *navarchosv1alpha1.NodeReplacement) navarchosv1alpha1.NodeReplacementPhase {
+	if replacement.Status.Phase == "" {
+		return navarchosv1alpha1.ReplacementPhaseNew
+	}
+	return replacement.Status.Phase
+}
+
+// replacementMessage returns the message of the most recently updated
+// condition of the NodeReplacement that is not True, explaining what it is
+// waiting for or why it failed. Completed NodeReplacements have no message
+func replacementMessage(replacement *navarchosv1alpha1.NodeReplacement) string {
+	if replacement.Status.Phase == navarchosv1alpha1.ReplacementPhaseCompleted {
+		return ""
+	}
+	var latest *navarchosv1alpha1.NodeReplacementCondition
+	for i := range replacement.Status.Conditions {
+		condition := &replacement.Status.Conditions[i]
+		if condition.Status == corev1.ConditionTrue || condition.Message == "" {
+			continue
+		}
+		if latest == nil || latest.LastUpdateTime.Before(&condition.LastUpdateTime) {
+			latest = condition
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Message
+}
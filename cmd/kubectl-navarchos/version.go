@@ -0,0 +1,4 @@
+package main
+
+// VERSION contains version information
+var VERSION = "undefined"
@@ -21,12 +21,16 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/go-logr/glogr"
 	"github.com/pusher/navarchos/pkg/apis"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller"
+	"github.com/pusher/navarchos/pkg/provider"
 	"github.com/pusher/navarchos/pkg/webhook"
+	"k8s.io/apimachinery/pkg/labels"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -35,12 +39,34 @@ import (
 )
 
 var (
-	leaderElection          = flag.Bool("leader-election", false, "Should the controller use leader election")
-	leaderElectionID        = flag.String("leader-election-id", "", "Name of the configmap used by the leader election system")
-	leaderElectionNamespace = flag.String("leader-election-namespace", "", "Namespace for the configmap used by the leader election system")
-	syncPeriod              = flag.Duration("sync-period", 5*time.Minute, "Reconcile sync period")
-	showVersion             = flag.Bool("version", false, "Show version and exit")
-	metricsAddr             = flag.String("metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	leaderElection           = flag.Bool("leader-election", false, "Should the controller use leader election")
+	leaderElectionID         = flag.String("leader-election-id", "", "Name of the configmap used by the leader election system")
+	leaderElectionNamespace  = flag.String("leader-election-namespace", "", "Namespace for the configmap used by the leader election system")
+	syncPeriod               = flag.Duration("sync-period", 5*time.Minute, "Reconcile sync period")
+	showVersion              = flag.Bool("version", false, "Show version and exit")
+	metricsAddr              = flag.String("metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	evictionGracePeriod      = flag.Duration("eviction-grace-period", -1*time.Second, "How long each evicted pod is given to terminate, unless set on the NodeReplacement. A negative value uses the grace period of the pod")
+	drainTimeout             = flag.Duration("drain-timeout", 15*time.Minute, "How long a single attempt to drain a node may take, unless set on the NodeReplacement. Zero means infinite")
+	ignoreAllDaemonSets      = flag.Bool("ignore-all-daemonsets", true, "Ignore pods managed by a DaemonSet when draining a node, unless set on the NodeReplacement")
+	deleteLocalData          = flag.Bool("delete-local-data", true, "Evict pods using emptyDir volumes when draining a node, unless set on the NodeReplacement")
+	forcePodDeletion         = flag.Bool("force-pod-deletion", false, "Evict pods not managed by a controller when draining a node, unless set on the NodeReplacement")
+	evictionOrder            = flag.String("eviction-order", "None", "Whether pods are evicted in waves when draining a node, unless set on the NodeReplacement. One of None, PriorityClass or Annotation")
+	waitForJobs              = flag.Bool("wait-for-jobs", false, "Wait for pods owned by a Job to complete before draining a node, unless set on the NodeReplacement")
+	blockingPodsTimeout      = flag.Duration("blocking-pods-timeout", 0, "How long after cordoning a node the controller waits for blocking pods to finish before draining it anyway, unless set on the NodeReplacement. Zero means infinite")
+	pdbPolicy                = flag.String("pdb-policy", "Ignore", "What happens when PodDisruptionBudgets would block the drain of a node before it is cordoned, unless set on the NodeReplacement. One of Ignore, Wait, Skip or Fail")
+	capacityPolicy           = flag.String("capacity-policy", "Ignore", "What happens when the pods on a node would not fit on the remaining nodes before it is cordoned, unless set on the NodeReplacement. One of Ignore, Wait or Fail")
+	pendingPodsGracePeriod   = flag.Duration("pending-pods-grace-period", 0, "How long a pod must have been unschedulable before it blocks new NodeReplacements")
+	pendingPodsIgnoreNS      = flag.String("pending-pods-ignore-namespaces", "", "Comma separated list of namespaces whose unschedulable pods never block new NodeReplacements")
+	pendingPodsIgnoreLabels  = flag.String("pending-pods-ignore-selector", "", "Label selector for unschedulable pods that never block new NodeReplacements")
+	maxDrainAttempts         = flag.Int("max-drain-attempts", 0, "How many times the controller attempts to drain a node before marking its NodeReplacement as failed. Zero means infinite")
+	maxDrainDuration         = flag.Duration("max-drain-duration", 0, "How long after cordoning a node the controller attempts to drain it before marking its NodeReplacement as failed. Zero means infinite")
+	drainBackoff             = flag.Duration("drain-backoff", 30*time.Second, "How long the controller waits before retrying a failed drain, doubled after every failed attempt")
+	maxDrainBackoff          = flag.Duration("max-drain-backoff", 10*time.Minute, "The maximum time the controller waits before retrying a failed drain")
+	replacementTimeout       = flag.Duration("replacement-timeout", 30*time.Minute, "How long a NodeReplacement waits for a replacement node to become Ready, unless set on the NodeReplacement")
+	approvalWebhookURL       = flag.String("approval-webhook-url", "", "URL of the webhook that must approve the disruption of each node, unless set on the NodeReplacement. No approval is required if unset")
+	approvalTimeout          = flag.Duration("approval-timeout", 10*time.Second, "How long the controller waits for a response from the approval webhook")
+	scheduleStartingDeadline = flag.Duration("schedule-starting-deadline", time.Hour, "How late a NodeRolloutSchedule may create a NodeRollout after it was due. NodeRollouts missed by more than this are skipped")
+	providerName             = flag.String("provider", "", fmt.Sprintf("Infrastructure provider used to terminate drained nodes, one of %v. Node termination is disabled if unset", provider.Names()))
 )
 
 func main() {
@@ -85,9 +111,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	log.Info("setting up provider")
+	p, err := provider.New(*providerName)
+	if err != nil {
+		log.Error(err, "unable to set up provider")
+		os.Exit(1)
+	}
+
 	// Setup all Controllers
 	log.Info("Setting up controller")
-	if err := controller.AddToManager(mgr); err != nil {
+	opts := &controller.Options{}
+	opts.NodeReplacementOptions.Provider = p
+	opts.NodeReplacementOptions.EvictionGracePeriod = evictionGracePeriod
+	opts.NodeReplacementOptions.DrainTimeout = drainTimeout
+	opts.NodeReplacementOptions.IgnoreAllDaemonSets = ignoreAllDaemonSets
+	opts.NodeReplacementOptions.DeleteLocalData = deleteLocalData
+	opts.NodeReplacementOptions.ForcePodDeletion = forcePodDeletion
+	order := navarchosv1alpha1.EvictionOrder(*evictionOrder)
+	opts.NodeReplacementOptions.EvictionOrder = &order
+	opts.NodeReplacementOptions.WaitForJobs = waitForJobs
+	opts.NodeReplacementOptions.BlockingPodsTimeout = blockingPodsTimeout
+	policy := navarchosv1alpha1.PDBPolicy(*pdbPolicy)
+	opts.NodeReplacementOptions.PDBPolicy = &policy
+	capacity := navarchosv1alpha1.CapacityPolicy(*capacityPolicy)
+	opts.NodeReplacementOptions.CapacityPolicy = &capacity
+	opts.NodeReplacementOptions.PendingPodsGracePeriod = pendingPodsGracePeriod
+	if *pendingPodsIgnoreNS != "" {
+		opts.NodeReplacementOptions.PendingPodsIgnoreNamespaces = strings.Split(*pendingPodsIgnoreNS, ",")
+	}
+	if *pendingPodsIgnoreLabels != "" {
+		selector, err := labels.Parse(*pendingPodsIgnoreLabels)
+		if err != nil {
+			log.Error(err, "unable to parse pending pods ignore selector")
+			os.Exit(1)
+		}
+		opts.NodeReplacementOptions.PendingPodsIgnoreSelector = selector
+	}
+	opts.NodeReplacementOptions.MaxDrainAttempts = maxDrainAttempts
+	opts.NodeReplacementOptions.MaxDrainDuration = maxDrainDuration
+	opts.NodeReplacementOptions.DrainBackoff = drainBackoff
+	opts.NodeReplacementOptions.MaxDrainBackoff = maxDrainBackoff
+	opts.NodeReplacementOptions.ReplacementTimeout = replacementTimeout
+	opts.NodeReplacementOptions.ApprovalWebhookURL = approvalWebhookURL
+	opts.NodeReplacementOptions.ApprovalTimeout = approvalTimeout
+	opts.NodeRolloutScheduleOptions.StartingDeadline = scheduleStartingDeadline
+	if err := controller.AddToManager(mgr, opts); err != nil {
 		log.Error(err, "unable to register controllers to the manager")
 		os.Exit(1)
 	}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pusher/navarchos/pkg/apis"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scheme knows the Kubernetes and Navarchos types read by navarchosctl
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apis.AddToScheme(scheme)
+}
+
+// clusterState contains the objects of a cluster needed to plan a NodeRollout
+type clusterState struct {
+	nodes corev1.NodeList
+	pods  []corev1.Pod
+	pdbs  []policyv1beta1.PodDisruptionBudget
+}
+
+// readNodeRollout reads the NodeRollout from the manifest at the given path
+func readNodeRollout(path string) (*navarchosv1alpha1.NodeRollout, error) {
+	objs, err := readObjects(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if rollout, ok := obj.(*navarchosv1alpha1.NodeRollout); ok {
+			return rollout, nil
+		}
+	}
+	return nil, fmt.Errorf("no NodeRollout found in %s", path)
+}
+
+// readClusterState reads the Nodes, Pods and PodDisruptionBudgets from the
+// YAML dump at the given path. Objects of any other kind are ignored
+func readClusterState(path string) (*clusterState, error) {
+	objs, err := readObjects(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &clusterState{}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *corev1.Node:
+			state.nodes.Items = append(state.nodes.Items, *o)
+		case *corev1.NodeList:
+			state.nodes.Items = append(state.nodes.Items, o.Items...)
+		case *corev1.Pod:
+			state.pods = append(state.pods, *o)
+		case *corev1.PodList:
+			state.pods = append(state.pods, o.Items...)
+		case *policyv1beta1.PodDisruptionBudget:
+			state.pdbs = append(state.pdbs, *o)
+		case *policyv1beta1.PodDisruptionBudgetList:
+			state.pdbs = append(state.pdbs, o.Items...)
+		}
+	}
+	return state, nil
+}
+
+// fetchClusterState lists the Nodes, Pods and PodDisruptionBudgets of the
+// cluster configured in the kubeconfig at the given path
+func fetchClusterState(kubeconfig string) (*clusterState, error) {
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig: %v", err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %v", err)
+	}
+
+	state := &clusterState{}
+	err = c.List(context.Background(), &state.nodes)
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %v", err)
+	}
+	pods := &corev1.PodList{}
+	err = c.List(context.Background(), pods)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+	state.pods = pods.Items
+	pdbs := &policyv1beta1.PodDisruptionBudgetList{}
+	err = c.List(context.Background(), pdbs)
+	if err != nil {
+		return nil, fmt.Errorf("error listing PodDisruptionBudgets: %v", err)
+	}
+	state.pdbs = pdbs.Items
+	return state, nil
+}
+
+// readObjects decodes every object in the YAML or JSON file at the given
+// path. The file may contain several documents, and the items of a List are
+// decoded individually. Objects of unknown kinds are skipped
+func readObjects(path string) ([]runtime.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := yaml.NewYAMLReader(bufio.NewReader(f))
+	objs := []runtime.Object{}
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		decoded, err := decodeObjects(decoder, doc)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding %s: %v", path, err)
+		}
+		objs = append(objs, decoded...)
+	}
+}
+
+// decodeObjects decodes the object in the document, or each of its items if
+// it is a List
+func decodeObjects(decoder runtime.Decoder, doc []byte) ([]runtime.Object, error) {
+	obj, _, err := decoder.Decode(doc, nil, nil)
+	if runtime.IsNotRegisteredError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := obj.(*corev1.List)
+	if !ok {
+		return []runtime.Object{obj}, nil
+	}
+	objs := []runtime.Object{}
+	for _, item := range list.Items {
+		decoded, err := decodeObjects(decoder, item.Raw)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, decoded...)
+	}
+	return objs, nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	replacementhandler "github.com/pusher/navarchos/pkg/controller/nodereplacement/handler"
+	rollouthandler "github.com/pusher/navarchos/pkg/controller/noderollout/handler"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+)
+
+// plan is the replacement plan of a NodeRollout printed by the plan command
+type plan struct {
+	// Nodes lists the nodes the NodeRollout replaces, in the order they are
+	// replaced
+	Nodes []plannedNode `json:"nodes"`
+
+	// ExcludedNodes lists the nodes selected by the NodeRollout that are
+	// excluded from it, and why
+	ExcludedNodes []navarchosv1alpha1.ExcludedNode `json:"excludedNodes,omitempty"`
+}
+
+// plannedNode is a node in the plan along with the pods affected by its drain
+type plannedNode struct {
+	navarchosv1alpha1.PlannedNode `json:",inline"`
+
+	// EvictedPods lists the pods evicted when the node is drained, as
+	// namespace/name
+	EvictedPods []string `json:"evictedPods"`
+
+	// BlockedPods lists the evicted pods whose eviction is currently blocked
+	// by a PodDisruptionBudget, and why
+	BlockedPods []navarchosv1alpha1.PodReason `json:"blockedPods"`
+}
+
+// runPlan runs the plan command with the given arguments, writing the plan to
+// out
+func runPlan(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	filename := fs.String("f", "", "Path to the NodeRollout manifest to plan")
+	kubeconfig := fs.String("kubeconfig", "", "Path to the kubeconfig of the cluster to read Nodes, Pods and PodDisruptionBudgets from")
+	stateFile := fs.String("state", "", "Path to a YAML dump of the Nodes, Pods and PodDisruptionBudgets of the cluster, as written by 'kubectl get nodes,pods,pdb --all-namespaces -o yaml'")
+	output := fs.String("o", "table", "Output format, one of table or json")
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if *filename == "" {
+		return fmt.Errorf("a NodeRollout manifest must be given with -f")
+	}
+	if (*kubeconfig == "") == (*stateFile == "") {
+		return fmt.Errorf("exactly one of -kubeconfig or -state must be given")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("invalid output format %q, must be table or json", *output)
+	}
+
+	rollout, err := readNodeRollout(*filename)
+	if err != nil {
+		return err
+	}
+	var state *clusterState
+	if *kubeconfig != "" {
+		state, err = fetchClusterState(*kubeconfig)
+	} else {
+		state, err = readClusterState(*stateFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	p, err := newPlan(rollout, state)
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return printJSON(out, p)
+	}
+	return printTable(out, p)
+}
+
+// newPlan computes the plan of the NodeRollout with the same node selection
+// as the NodeRollout controller and lists the pods affected by the drain of
+// each planned node
+func newPlan(rollout *navarchosv1alpha1.NodeRollout, state *clusterState) (*plan, error) {
+	plannedNodes, excluded, err := rollouthandler.Plan(rollout, &state.nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	podsByNode := make(map[string][]corev1.Pod)
+	for _, pod := range state.pods {
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+	pdbsByNamespace := make(map[string][]policyv1beta1.PodDisruptionBudget)
+	for _, pdb := range state.pdbs {
+		pdbsByNamespace[pdb.GetNamespace()] = append(pdbsByNamespace[pdb.GetNamespace()], pdb)
+	}
+
+	p := &plan{
+		Nodes:         []plannedNode{},
+		ExcludedNodes: excluded,
+	}
+	for _, node := range plannedNodes {
+		planned := plannedNode{
+			PlannedNode: node,
+			EvictedPods: []string{},
+			BlockedPods: []navarchosv1alpha1.PodReason{},
+		}
+		for _, pod := range podsByNode[node.Name] {
+			if !replacementhandler.IsEvictable(&pod) {
+				continue
+			}
+			name := fmt.Sprintf("%s/%s", pod.GetNamespace(), pod.GetName())
+			planned.EvictedPods = append(planned.EvictedPods, name)
+			if reason, ok := replacementhandler.DisruptionBlockedReason(&pod, pdbsByNamespace[pod.GetNamespace()]); ok {
+				planned.BlockedPods = append(planned.BlockedPods, navarchosv1alpha1.PodReason{Name: pod.GetName(), Namespace: pod.GetNamespace(), Reason: reason})
+			}
+		}
+		p.Nodes = append(p.Nodes, planned)
+	}
+	return p, nil
+}
+
+// printJSON writes the plan to out as indented JSON
+func printJSON(out io.Writer, p *plan) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding plan: %v", err)
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// printTable writes the plan to out as a table, followed by the excluded
+// nodes. Evicted pods are counted, blocked pods are listed by name
+func printTable(out io.Writer, p *plan) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ORDER\tNODE\tPRIORITY\tSELECTOR\tEVICTED PODS\tBLOCKED PODS")
+	for _, node := range p.Nodes {
+		blocked := "<none>"
+		if len(node.BlockedPods) > 0 {
+			names := []string{}
+			for _, pod := range node.BlockedPods {
+				names = append(names, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+			}
+			blocked = strings.Join(names, ",")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%d\t%s\n", node.Order, node.Name, node.Priority, node.Selector, len(node.EvictedPods), blocked)
+	}
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+
+	if len(p.ExcludedNodes) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "\nExcluded nodes:")
+	for _, node := range p.ExcludedNodes {
+		fmt.Fprintf(out, "  %s: %s\n", node.Name, node.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeFleetPolicySpec defines the desired state of NodeFleetPolicy
+type NodeFleetPolicySpec struct {
+	// NodeSelector selects the nodes of the node group the policy applies to.
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+
+	// KubeletVersion is the kubelet version every node should run, as reported
+	// in the node's status.nodeInfo.kubeletVersion (ex: v1.14.3).
+	// If unset the kubelet version is not checked.
+	KubeletVersion string `json:"kubeletVersion,omitempty"`
+
+	// OSImage is the OS image every node should run, as reported in the node's
+	// status.nodeInfo.osImage.
+	// If unset the OS image is not checked.
+	OSImage string `json:"osImage,omitempty"`
+
+	// RequiredLabels are labels every node should have, with the given values.
+	RequiredLabels map[string]string `json:"requiredLabels,omitempty"`
+
+	// MaxNodeAge is the longest a node may run after it was created before it
+	// is replaced.
+	// If unset the age of nodes is not checked.
+	MaxNodeAge *metav1.Duration `json:"maxNodeAge,omitempty"`
+
+	// Suspend stops the policy from creating NodeRollouts while it is set.
+	// Drifted nodes are still reported.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Replacement is used for the NodeReplacement of each drifted node.
+	Replacement ReplacementSpec `json:"replacement,omitempty"`
+
+	// RolloutTemplate describes the NodeRollouts created for drifted nodes.
+	// Its NodeSelectors and NodeNames are ignored, the drifted nodes are
+	// selected by name instead.
+	RolloutTemplate *NodeRolloutTemplateSpec `json:"rolloutTemplate,omitempty"`
+}
+
+// NodeFleetPolicyStatus defines the observed state of NodeFleetPolicy
+type NodeFleetPolicyStatus struct {
+	// NodesCount is the number of nodes selected by the policy.
+	NodesCount int `json:"nodesCount,omitempty"`
+
+	// DriftedNodes lists the nodes that do not match the policy.
+	DriftedNodes []DriftedNode `json:"driftedNodes,omitempty"`
+
+	// DriftedNodesCount is the count of DriftedNodes.
+	// This is used for printing in kubectl.
+	DriftedNodesCount int `json:"driftedNodesCount,omitempty"`
+
+	// DriftedReplacements lists the drifted nodes created since the previous
+	// NodeRollout of the policy started. They are most likely its replacements,
+	// launched by the node group with the old configuration, so they are not
+	// replaced again until the policy changes.
+	DriftedReplacements []string `json:"driftedReplacements,omitempty"`
+
+	// ActiveRollout is the name of the NodeRollout created by the policy that
+	// has not yet finished.
+	ActiveRollout string `json:"activeRollout,omitempty"`
+
+	// LastRollout is the name of the most recent NodeRollout created by the
+	// policy. It is kept after the NodeRollout itself has been deleted.
+	LastRollout string `json:"lastRollout,omitempty"`
+
+	// LastRolloutHash is the hash of the policy the most recent NodeRollout
+	// was created for. Drifted nodes launched since LastRolloutTime are not
+	// replaced again while the policy still has this hash.
+	LastRolloutHash string `json:"lastRolloutHash,omitempty"`
+
+	// LastRolloutTime is the time the most recent NodeRollout was created.
+	LastRolloutTime *metav1.Time `json:"lastRolloutTime,omitempty"`
+
+	// Conditions gives detailed condition information about the
+	// NodeFleetPolicy
+	Conditions []NodeFleetPolicyCondition `json:"conditions,omitempty"`
+}
+
+// DriftedNode describes a node that does not match its NodeFleetPolicy
+type DriftedNode struct {
+	// Name of the node
+	Name string `json:"name"`
+
+	// Reasons describe how the node differs from the policy
+	Reasons []string `json:"reasons"`
+}
+
+// NodeFleetPolicyConditionType is the type of a NodeFleetPolicyCondition
+type NodeFleetPolicyConditionType string
+
+const (
+	// DriftDetectedType refers to whether any node selected by the policy does
+	// not match it
+	DriftDetectedType NodeFleetPolicyConditionType = "DriftDetected"
+	// RolloutCreatedType refers to whether the controller successfully
+	// created a NodeRollout for the drifted nodes
+	RolloutCreatedType NodeFleetPolicyConditionType = "RolloutCreated"
+)
+
+// NodeFleetPolicyConditionReason represents a valid condition reason for a
+// NodeFleetPolicy
+type NodeFleetPolicyConditionReason string
+
+// NodeFleetPolicyCondition is a status condition for a NodeFleetPolicy
+type NodeFleetPolicyCondition struct {
+	// Type of this condition
+	Type NodeFleetPolicyConditionType `json:"type"`
+
+	// Status of this condition
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastUpdateTime of this condition
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// LastTransitionTime of this condition
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason for the current status of this condition
+	Reason NodeFleetPolicyConditionReason `json:"reason,omitempty"`
+
+	// Message associated with this condition
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// NodeFleetPolicy is the Schema for the nodefleetpolicies API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=nodefleetpolicies,shortName=nfp;nfps
+// +kubebuilder:printcolumn:name="Nodes",type="integer",JSONPath=".status.nodesCount",description="Number of nodes selected by the policy"
+// +kubebuilder:printcolumn:name="Drifted",type="integer",JSONPath=".status.driftedNodesCount",description="Number of nodes that do not match the policy"
+// +kubebuilder:printcolumn:name="Active Rollout",type="string",JSONPath=".status.activeRollout",description="The NodeRollout replacing drifted nodes"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend",priority="1"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type NodeFleetPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeFleetPolicySpec   `json:"spec,omitempty"`
+	Status NodeFleetPolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// NodeFleetPolicyList contains a list of NodeFleetPolicy
+type NodeFleetPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeFleetPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeFleetPolicy{}, &NodeFleetPolicyList{})
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -41,6 +42,188 @@ type ReplacementSpec struct {
 	// Priority determines the priority of this NodeReplacement.
 	// Higher priorities should be replaced sooner.
 	Priority *int `json:"priority,omitempty"`
+
+	// WaitForReplacement, if set, makes the NodeReplacement wait for a new node
+	// to join the cluster and become Ready before it is completed.
+	WaitForReplacement *WaitForReplacementSpec `json:"waitForReplacement,omitempty"`
+
+	// Hooks are Jobs run by the NodeReplacement before the node is cordoned,
+	// before it is drained and after it has been drained.
+	Hooks *ReplacementHooks `json:"hooks,omitempty"`
+
+	// Approval, if set, makes the NodeReplacement request approval from a
+	// webhook before the node is cordoned. Defaults to the controller's
+	// approval webhook, if any.
+	Approval *ApprovalWebhook `json:"approval,omitempty"`
+
+	// Drain overrides how the node is drained. Any setting that is not set
+	// defaults to the controller's drain settings.
+	Drain *DrainSpec `json:"drain,omitempty"`
+}
+
+// DrainSpec configures how a node is drained
+type DrainSpec struct {
+	// EvictionGracePeriod is how long each pod is given to terminate when it
+	// is evicted. A negative value uses the grace period of the pod.
+	EvictionGracePeriod *metav1.Duration `json:"evictionGracePeriod,omitempty"`
+
+	// Timeout is how long a single attempt to drain the node may take. Zero
+	// means infinite.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// IgnoreAllDaemonSets ignores pods managed by a DaemonSet.
+	IgnoreAllDaemonSets *bool `json:"ignoreAllDaemonSets,omitempty"`
+
+	// DeleteLocalData evicts pods using emptyDir volumes, deleting their data.
+	DeleteLocalData *bool `json:"deleteLocalData,omitempty"`
+
+	// ForcePodDeletion evicts pods that are not managed by a
+	// ReplicationController, ReplicaSet, Job, DaemonSet or StatefulSet.
+	ForcePodDeletion *bool `json:"forcePodDeletion,omitempty"`
+
+	// EvictionOrder determines whether pods are evicted in waves, one of None,
+	// PriorityClass or Annotation. Waves are evicted lowest order first, each
+	// wave must finish before the next one starts.
+	EvictionOrder EvictionOrder `json:"evictionOrder,omitempty"`
+
+	// WaitForJobs makes the drain wait for pods owned by a Job to complete.
+	// Pods with the DoNotDisruptAnnotation are always waited for.
+	WaitForJobs *bool `json:"waitForJobs,omitempty"`
+
+	// BlockingPodsTimeout is how long after the node was cordoned the drain
+	// waits for blocking pods to finish. Once it has passed the node is
+	// drained regardless. Zero means no limit.
+	BlockingPodsTimeout *metav1.Duration `json:"blockingPodsTimeout,omitempty"`
+
+	// PDBPolicy determines what happens when PodDisruptionBudgets would block
+	// the eviction of pods on the node before it is cordoned, one of Ignore,
+	// Wait, Skip or Fail.
+	PDBPolicy PDBPolicy `json:"pdbPolicy,omitempty"`
+
+	// CapacityPolicy determines what happens when the pods on the node would
+	// not fit on the remaining nodes before it is cordoned, one of Ignore,
+	// Wait or Fail.
+	CapacityPolicy CapacityPolicy `json:"capacityPolicy,omitempty"`
+}
+
+// PDBPolicy determines what happens to a NodeReplacement when
+// PodDisruptionBudgets would block the drain of its node
+type PDBPolicy string
+
+const (
+	// PDBPolicyIgnore cordons and drains the node regardless
+	PDBPolicyIgnore PDBPolicy = "Ignore"
+
+	// PDBPolicyWait waits until the PodDisruptionBudgets allow the pods to be
+	// evicted before cordoning the node
+	PDBPolicyWait PDBPolicy = "Wait"
+
+	// PDBPolicySkip aborts the NodeReplacement without cordoning the node
+	PDBPolicySkip PDBPolicy = "Skip"
+
+	// PDBPolicyFail fails the NodeReplacement without cordoning the node
+	PDBPolicyFail PDBPolicy = "Fail"
+)
+
+// DoNotDisruptAnnotation is the pod annotation that makes the drain of the
+// pod's node wait for the pod to finish when set to "true"
+const DoNotDisruptAnnotation = "navarchos.pusher.com/do-not-disrupt"
+
+// CapacityPolicy determines what happens to a NodeReplacement when the pods on
+// its node would not fit on the remaining nodes
+type CapacityPolicy string
+
+const (
+	// CapacityPolicyIgnore cordons and drains the node regardless
+	CapacityPolicyIgnore CapacityPolicy = "Ignore"
+
+	// CapacityPolicyWait waits until the pods would fit before cordoning the
+	// node
+	CapacityPolicyWait CapacityPolicy = "Wait"
+
+	// CapacityPolicyFail fails the NodeReplacement without cordoning the node
+	CapacityPolicyFail CapacityPolicy = "Fail"
+)
+
+// EvictionOrder determines how the pods on a node are grouped into waves
+type EvictionOrder string
+
+const (
+	// EvictionOrderNone evicts all pods at once
+	EvictionOrderNone EvictionOrder = "None"
+
+	// EvictionOrderByPriorityClass evicts pods in waves by the value of their
+	// PriorityClass
+	EvictionOrderByPriorityClass EvictionOrder = "PriorityClass"
+
+	// EvictionOrderByAnnotation evicts pods in waves by the integer value of
+	// their EvictionOrderAnnotation. Pods without the annotation are in wave 0
+	EvictionOrderByAnnotation EvictionOrder = "Annotation"
+)
+
+// EvictionOrderAnnotation is the pod annotation used to order evictions when
+// the EvictionOrder is Annotation
+const EvictionOrderAnnotation = "navarchos.pusher.com/eviction-order"
+
+// EvictionWave is a group of pods on a node that are evicted together
+type EvictionWave struct {
+	// Order is the PriorityClass value or eviction order annotation shared by
+	// the pods in the wave.
+	Order int32 `json:"order"`
+
+	// Pods lists the pods in the wave.
+	Pods []string `json:"pods"`
+
+	// Completed is set once every pod in the wave has been evicted.
+	Completed bool `json:"completed,omitempty"`
+}
+
+// ApprovalWebhook configures the webhook that approves the disruption of a
+// node
+type ApprovalWebhook struct {
+	// URL is the endpoint the NodeReplacement and node are POSTed to. It must
+	// respond with a decision of pending, approve or deny.
+	URL string `json:"url"`
+}
+
+// ReplacementHooks configures the Jobs run during a NodeReplacement. Each hook
+// must succeed before the NodeReplacement continues. If a hook fails the
+// NodeReplacement fails
+type ReplacementHooks struct {
+	// PreCordon is run before the node is cordoned.
+	PreCordon *Hook `json:"preCordon,omitempty"`
+
+	// PreDrain is run once the node has been cordoned, before it is drained.
+	PreDrain *Hook `json:"preDrain,omitempty"`
+
+	// PostDrain is run once the node has been drained.
+	PostDrain *Hook `json:"postDrain,omitempty"`
+}
+
+// Hook describes a Job run by a NodeReplacement
+type Hook struct {
+	// Namespace is the namespace the Job is created in.
+	Namespace string `json:"namespace"`
+
+	// Template describes the Job that is created. The name of the node being
+	// replaced is set in the NODE_NAME environment variable of every container
+	// of the Job.
+	Template batchv1.JobTemplateSpec `json:"template"`
+}
+
+// WaitForReplacementSpec configures how the controller waits for a node to
+// replace the node in the NodeReplacement
+type WaitForReplacementSpec struct {
+	// Selector selects the nodes that can replace the node. When the
+	// NodeReplacement is created by a NodeRollout this defaults to the label
+	// selector that matched the node, otherwise it defaults to the instance
+	// type, zone, region, role and node group labels of the node being
+	// replaced. It must be set if the node has none of these labels.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Timeout determines how long the controller waits for a replacement node
+	// to become Ready. Defaults to the controller's replacement timeout.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // NodeReplacementPhase determines the phase in which the NodeRollout currently is
@@ -48,9 +231,13 @@ type NodeReplacementPhase string
 
 // The following ReplacementPhases enumerate all possible NodeReplacementPhases
 const (
-	ReplacementPhaseNew        NodeReplacementPhase = "New"
-	ReplacementPhaseInProgress NodeReplacementPhase = "InProgress"
-	ReplacementPhaseCompleted  NodeReplacementPhase = "Completed"
+	ReplacementPhaseNew                   NodeReplacementPhase = "New"
+	ReplacementPhaseInProgress            NodeReplacementPhase = "InProgress"
+	ReplacementPhaseTerminating           NodeReplacementPhase = "Terminating"
+	ReplacementPhaseWaitingForReplacement NodeReplacementPhase = "WaitingForReplacement"
+	ReplacementPhaseCompleted             NodeReplacementPhase = "Completed"
+	ReplacementPhaseAborted               NodeReplacementPhase = "Aborted"
+	ReplacementPhaseFailed                NodeReplacementPhase = "Failed"
 )
 
 // NodeReplacementStatus defines the observed state of NodeReplacement
@@ -78,12 +265,73 @@ type NodeReplacementStatus struct {
 	// IgnoredPodsCount is the count of IgnoredPods.
 	IgnoredPodsCount int `json:"ignoredPodsCount,omitempty"`
 
+	// DisruptionBlockedPods lists the pods whose eviction would be blocked by
+	// a PodDisruptionBudget when the node was last checked before cordoning.
+	DisruptionBlockedPods []PodReason `json:"disruptionBlockedPods,omitempty"`
+
+	// DisruptionBlockedPodsCount is the count of DisruptionBlockedPods.
+	DisruptionBlockedPodsCount int `json:"disruptionBlockedPodsCount,omitempty"`
+
+	// UnschedulablePods lists the pods on the node that would not fit on the
+	// remaining nodes when the node was last checked before cordoning.
+	UnschedulablePods []PodReason `json:"unschedulablePods,omitempty"`
+
+	// UnschedulablePodsCount is the count of UnschedulablePods.
+	UnschedulablePodsCount int `json:"unschedulablePodsCount,omitempty"`
+
+	// BlockingPods lists the pods the controller is waiting for to finish
+	// before draining the node.
+	BlockingPods []PodReason `json:"blockingPods,omitempty"`
+
+	// BlockingPodsCount is the count of BlockingPods.
+	BlockingPodsCount int `json:"blockingPodsCount,omitempty"`
+
 	// FailedPods lists all pods the controller has failed to evict.
 	FailedPods []PodReason `json:"failedPods,omitempty"`
 
 	// FailedPodsCount is the count of FailedPods.
 	FailedPodsCount int `json:"failedPodsCount,omitempty"`
 
+	// CordonTimestamp is a timestamp for when the controller cordoned the node
+	CordonTimestamp *metav1.Time `json:"cordonTimestamp,omitempty"`
+
+	// CordonedByController is true if the node was schedulable until the
+	// controller cordoned it. Only such nodes are uncordoned when the
+	// NodeRollout is aborted.
+	CordonedByController bool `json:"cordonedByController,omitempty"`
+
+	// DrainStartTimestamp is a timestamp for when the controller started
+	// evicting the pods of the node
+	DrainStartTimestamp *metav1.Time `json:"drainStartTimestamp,omitempty"`
+
+	// Attempts is the number of times the controller has failed to drain the
+	// node.
+	Attempts int `json:"attempts,omitempty"`
+
+	// LastAttemptTime is a timestamp for when the controller last failed to
+	// drain the node
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+
+	// NextAttemptTime is a timestamp for when the controller will next attempt
+	// to drain the node after a failed attempt
+	NextAttemptTime *metav1.Time `json:"nextAttemptTime,omitempty"`
+
+	// EvictionWaves lists the waves the pods on the node are evicted in, when
+	// the drain settings set an EvictionOrder.
+	EvictionWaves []EvictionWave `json:"evictionWaves,omitempty"`
+
+	// Drain records the settings used to drain the node, including the
+	// controller's defaults for any setting not set in the ReplacementSpec.
+	Drain *DrainSpec `json:"drain,omitempty"`
+
+	// ReplacementNodeSelector is the selector used to find the node replacing
+	// the node in the NodeReplacement.
+	ReplacementNodeSelector *metav1.LabelSelector `json:"replacementNodeSelector,omitempty"`
+
+	// ReplacementNode is the name of the node that replaced the node in the
+	// NodeReplacement.
+	ReplacementNode string `json:"replacementNode,omitempty"`
+
 	// CompletionTimestamp is a timestamp for when the replacement has completed
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
 
@@ -96,6 +344,9 @@ type PodReason struct {
 	// Name is the name of the pod
 	Name string `json:"name"`
 
+	// Namespace is the namespace of the pod
+	Namespace string `json:"namespace,omitempty"`
+
 	// Reason is the message to display to the user as to why this Pod is ignored/failed
 	Reason string `json:"reason"`
 }
@@ -107,6 +358,55 @@ const (
 	// NodeCordonedType refers to the type of condition where the controller
 	// successfully managed to cordon the node
 	NodeCordonedType NodeReplacementConditionType = "NodeCordoned"
+
+	// InstanceTerminatedType refers to the type of condition where the
+	// infrastructure provider has terminated the instance backing the node
+	InstanceTerminatedType NodeReplacementConditionType = "InstanceTerminated"
+
+	// ReplacementNodeReadyType refers to the type of condition where a new node
+	// replacing the node has joined the cluster and is Ready
+	ReplacementNodeReadyType NodeReplacementConditionType = "ReplacementNodeReady"
+
+	// ReplacementPausedType refers to the type of condition where the
+	// NodeRollout that created the NodeReplacement is paused
+	ReplacementPausedType NodeReplacementConditionType = "Paused"
+
+	// NodeRestoredType refers to the type of condition where the controller
+	// made a node it had cordoned schedulable again after the NodeRollout was
+	// aborted
+	NodeRestoredType NodeReplacementConditionType = "NodeRestored"
+
+	// NodeDrainedType refers to the type of condition where the controller
+	// successfully drained the node
+	NodeDrainedType NodeReplacementConditionType = "NodeDrained"
+
+	// PreCordonHookType refers to the type of condition where the pre-cordon
+	// hook Job succeeded
+	PreCordonHookType NodeReplacementConditionType = "PreCordonHook"
+
+	// PreDrainHookType refers to the type of condition where the pre-drain
+	// hook Job succeeded
+	PreDrainHookType NodeReplacementConditionType = "PreDrainHook"
+
+	// PostDrainHookType refers to the type of condition where the post-drain
+	// hook Job succeeded
+	PostDrainHookType NodeReplacementConditionType = "PostDrainHook"
+
+	// ApprovedType refers to the type of condition where the approval webhook
+	// approved the disruption of the node
+	ApprovedType NodeReplacementConditionType = "Approved"
+
+	// DrainFeasibleType refers to whether PodDisruptionBudgets allow the pods
+	// on the node to be evicted
+	DrainFeasibleType NodeReplacementConditionType = "DrainFeasible"
+
+	// PodsSchedulableType refers to whether the pods on the node would fit on
+	// the remaining nodes
+	PodsSchedulableType NodeReplacementConditionType = "PodsSchedulable"
+
+	// PendingPodsClearedType refers to whether no unschedulable pods in the
+	// cluster block the NodeReplacement from starting
+	PendingPodsClearedType NodeReplacementConditionType = "PendingPodsCleared"
 )
 
 const (
@@ -116,6 +416,166 @@ const (
 
 	// ReasonErrorCordoningNode is a replacement condition for a failed node cordon
 	ReasonErrorCordoningNode NodeReplacementConditionReason = "ErrorCordoningNode"
+
+	// ReasonNodeExcluded represents the fact that the node was not cordoned as
+	// it is excluded from replacement
+	ReasonNodeExcluded NodeReplacementConditionReason = "NodeExcluded"
+
+	// ReasonInstanceTerminating is a replacement condition for when the
+	// controller is waiting for the provider to terminate the instance
+	ReasonInstanceTerminating NodeReplacementConditionReason = "InstanceTerminating"
+
+	// ReasonInstanceTerminated is a replacement condition for when the provider
+	// reports the instance as terminated
+	ReasonInstanceTerminated NodeReplacementConditionReason = "InstanceTerminated"
+
+	// ReasonErrorTerminatingInstance is a replacement condition for a failed
+	// instance termination
+	ReasonErrorTerminatingInstance NodeReplacementConditionReason = "ErrorTerminatingInstance"
+
+	// ReasonMissingProviderID is a replacement condition for when the node has
+	// no providerID, so its instance cannot be terminated
+	ReasonMissingProviderID NodeReplacementConditionReason = "MissingProviderID"
+
+	// ReasonWaitingForReplacementNode is a replacement condition for when the
+	// controller is waiting for a replacement node to become Ready
+	ReasonWaitingForReplacementNode NodeReplacementConditionReason = "WaitingForReplacementNode"
+
+	// ReasonReplacementNodeReady is a replacement condition for when a
+	// replacement node has become Ready
+	ReasonReplacementNodeReady NodeReplacementConditionReason = "ReplacementNodeReady"
+
+	// ReasonReplacementNodeTimedOut is a replacement condition for when no
+	// replacement node became Ready before the timeout
+	ReasonReplacementNodeTimedOut NodeReplacementConditionReason = "ReplacementNodeTimedOut"
+
+	// ReasonErrorFindingReplacementNode is a replacement condition for a failure
+	// when looking for a replacement node
+	ReasonErrorFindingReplacementNode NodeReplacementConditionReason = "ErrorFindingReplacementNode"
+
+	// ReasonRolloutPaused is a replacement condition for when the NodeRollout
+	// that created the NodeReplacement is paused
+	ReasonRolloutPaused NodeReplacementConditionReason = "RolloutPaused"
+
+	// ReasonRolloutResumed is a replacement condition for when the NodeRollout
+	// that created the NodeReplacement has been resumed
+	ReasonRolloutResumed NodeReplacementConditionReason = "RolloutResumed"
+
+	// ReasonNodeRestored is a replacement condition for when the controller
+	// uncordoned the node after the NodeRollout was aborted
+	ReasonNodeRestored NodeReplacementConditionReason = "NodeRestored"
+
+	// ReasonErrorRestoringNode is a replacement condition for a failed node
+	// uncordon
+	ReasonErrorRestoringNode NodeReplacementConditionReason = "ErrorRestoringNode"
+
+	// ReasonNodeDrained is a replacement condition for when the controller
+	// drained the node
+	ReasonNodeDrained NodeReplacementConditionReason = "NodeDrained"
+
+	// ReasonErrorDrainingNode is a replacement condition for a failed drain
+	// that will be retried
+	ReasonErrorDrainingNode NodeReplacementConditionReason = "ErrorDrainingNode"
+
+	// ReasonDrainFailed is a replacement condition for when the controller has
+	// given up draining the node
+	ReasonDrainFailed NodeReplacementConditionReason = "DrainFailed"
+
+	// ReasonHookRunning is a replacement condition for when the controller is
+	// waiting for a hook Job to complete
+	ReasonHookRunning NodeReplacementConditionReason = "HookRunning"
+
+	// ReasonHookSucceeded is a replacement condition for a hook Job that
+	// succeeded
+	ReasonHookSucceeded NodeReplacementConditionReason = "HookSucceeded"
+
+	// ReasonHookFailed is a replacement condition for a hook Job that failed
+	ReasonHookFailed NodeReplacementConditionReason = "HookFailed"
+
+	// ReasonErrorRunningHook is a replacement condition for when the
+	// controller failed to create or get a hook Job
+	ReasonErrorRunningHook NodeReplacementConditionReason = "ErrorRunningHook"
+
+	// ReasonApprovalPending is a replacement condition for when the approval
+	// webhook has not yet decided whether the node may be disrupted
+	ReasonApprovalPending NodeReplacementConditionReason = "ApprovalPending"
+
+	// ReasonApproved is a replacement condition for when the approval webhook
+	// approved the disruption of the node
+	ReasonApproved NodeReplacementConditionReason = "Approved"
+
+	// ReasonApprovalDenied is a replacement condition for when the approval
+	// webhook denied the disruption of the node
+	ReasonApprovalDenied NodeReplacementConditionReason = "ApprovalDenied"
+
+	// ReasonErrorRequestingApproval is a replacement condition for a failed
+	// request to the approval webhook
+	ReasonErrorRequestingApproval NodeReplacementConditionReason = "ErrorRequestingApproval"
+
+	// ReasonDrainFeasible is a replacement condition for when no
+	// PodDisruptionBudget blocks the eviction of the pods on the node
+	ReasonDrainFeasible NodeReplacementConditionReason = "DrainFeasible"
+
+	// ReasonDrainBlocked is a replacement condition for when
+	// PodDisruptionBudgets block the eviction of pods on the node
+	ReasonDrainBlocked NodeReplacementConditionReason = "DrainBlocked"
+
+	// ReasonErrorCheckingDrain is a replacement condition for when the
+	// controller failed to check the PodDisruptionBudgets of the pods on the
+	// node
+	ReasonErrorCheckingDrain NodeReplacementConditionReason = "ErrorCheckingDrain"
+
+	// ReasonPodsSchedulable is a replacement condition for when the pods on
+	// the node would fit on the remaining nodes
+	ReasonPodsSchedulable NodeReplacementConditionReason = "PodsSchedulable"
+
+	// ReasonPodsUnschedulable is a replacement condition for when some of the
+	// pods on the node would not fit on the remaining nodes
+	ReasonPodsUnschedulable NodeReplacementConditionReason = "PodsUnschedulable"
+
+	// ReasonErrorSimulatingScheduling is a replacement condition for when the
+	// controller failed to simulate rescheduling the pods on the node
+	ReasonErrorSimulatingScheduling NodeReplacementConditionReason = "ErrorSimulatingScheduling"
+
+	// ReasonNoPendingPods is a replacement condition for when no unschedulable
+	// pods block the NodeReplacement from starting
+	ReasonNoPendingPods NodeReplacementConditionReason = "NoPendingPods"
+
+	// ReasonPendingPods is a replacement condition for when unschedulable pods
+	// block the NodeReplacement from starting
+	ReasonPendingPods NodeReplacementConditionReason = "PendingPods"
+
+	// ReasonErrorListingPendingPods is a replacement condition for when the
+	// controller failed to list the pending pods
+	ReasonErrorListingPendingPods NodeReplacementConditionReason = "ErrorListingPendingPods"
+)
+
+// These reasons do not set a condition, they are only used as the cause of
+// requeueing a NodeReplacement when counting requeues by reason
+const (
+	// ReasonRolloutFailed is used when the NodeRollout controlling the
+	// NodeReplacement has failed, so new replacements are held back
+	ReasonRolloutFailed NodeReplacementConditionReason = "RolloutFailed"
+
+	// ReasonReplacementsInProgress is used when other NodeReplacements of
+	// higher priority, or beyond the rollout's concurrency, are in progress
+	ReasonReplacementsInProgress NodeReplacementConditionReason = "ReplacementsInProgress"
+
+	// ReasonOutsideMaintenanceWindow is used when the NodeReplacement is
+	// waiting for a maintenance window to open
+	ReasonOutsideMaintenanceWindow NodeReplacementConditionReason = "OutsideMaintenanceWindow"
+
+	// ReasonBlockingPods is used when the drain is waiting for pods that must
+	// not be disrupted
+	ReasonBlockingPods NodeReplacementConditionReason = "BlockingPods"
+
+	// ReasonDrainBackoff is used when the NodeReplacement is waiting to retry
+	// a failed drain
+	ReasonDrainBackoff NodeReplacementConditionReason = "DrainBackoff"
+
+	// ReasonEvictionWaves is used when pods remain to be evicted in further
+	// eviction waves
+	ReasonEvictionWaves NodeReplacementConditionReason = "EvictionWaves"
 )
 
 // NodeReplacementConditionReason represents a valid condition reason for a NodeReplacement
@@ -154,7 +614,10 @@ type NodeReplacementCondition struct {
 // +kubebuilder:printcolumn:name="Evicted Pods",type="integer",JSONPath=".status.evictedPodsCount",description="Number of pods evicted"
 // +kubebuilder:printcolumn:name="Failed Pods",type="integer",JSONPath=".status.failedPodsCount",description="Number of pods failed"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Attempts",type="integer",JSONPath=".status.attempts",description="Number of failed attempts to drain the node",priority="1"
+// +kubebuilder:printcolumn:name="Next Attempt",type="date",JSONPath=".status.nextAttemptTime",description="The time of the next attempt to drain the node",priority="1"
 // +kubebuilder:printcolumn:name="Priority",type="integer",JSONPath=".spec.replacement.priority",description="The priority of the replacement",priority="1"
+// +kubebuilder:printcolumn:name="Replacement Node",type="string",JSONPath=".status.replacementNode",description="The node that replaced the node",priority="1"
 // +kubebuilder:printcolumn:name="Completed",type="date",JSONPath=".status.completionTimestamp",description="The time since the replacement completed"
 // +kubebuilder:printcolumn:name="Owners",type="string",JSONPath=".metadata.ownerReferences[].name",description="The owner of the replacement",priority="1"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
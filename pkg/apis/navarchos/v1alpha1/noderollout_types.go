@@ -19,8 +19,20 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// ExcludeAnnotation is the annotation that excludes a node from every
+// NodeRollout when set to "true"
+const ExcludeAnnotation = "navarchos.pusher.com/exclude"
+
+// HasExcludeAnnotation returns true if the node has the ExcludeAnnotation set
+// to "true"
+func HasExcludeAnnotation(node *corev1.Node) bool {
+	return node.GetAnnotations()[ExcludeAnnotation] == "true"
+}
+
 // NodeRolloutSpec defines the desired state of NodeRollout
 type NodeRolloutSpec struct {
 	// NodeSelectors uses label selectors to select a group of nodes.
@@ -32,12 +44,175 @@ type NodeRolloutSpec struct {
 	// The priority set on the name will be passed to the NodeReplacement.
 	// NodeName priorities always override NodeSelector priorities.
 	NodeNames []NodeName `json:"nodeNames,omitempty"`
+
+	// ExcludeSelectors uses label selectors to exclude nodes from the
+	// NodeRollout. Nodes matching any of the selectors are never replaced, even
+	// if they are selected by NodeSelectors or NodeNames.
+	ExcludeSelectors []metav1.LabelSelector `json:"excludeSelectors,omitempty"`
+
+	// Strategy determines how the NodeReplacements created by the NodeRollout
+	// are processed.
+	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+
+	// Paused prevents any NodeReplacement created by the NodeRollout from
+	// starting while it is set. NodeReplacements that have already started are
+	// not affected. When unset the rollout resumes where it left off.
+	Paused bool `json:"paused,omitempty"`
+
+	// Abort stops the NodeRollout. NodeReplacements that have not started are
+	// not started and NodeReplacements that are draining their node are
+	// stopped. Nodes cordoned by those NodeReplacements are made schedulable
+	// again. NodeReplacements that have already drained their node are not
+	// affected.
+	Abort bool `json:"abort,omitempty"`
+
+	// FailurePolicy determines how the NodeRollout reacts to failed
+	// NodeReplacements.
+	FailurePolicy *FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// MaintenanceWindows restrict when NodeReplacements created by the
+	// NodeRollout may start. A NodeReplacement only cordons its node while one
+	// of the windows is open. NodeReplacements that have already started are
+	// allowed to finish. If unset, NodeReplacements may start at any time.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// DryRun, if set, only computes the plan of the NodeRollout, recording
+	// which nodes would be replaced and in which order in the status without
+	// creating any NodeReplacements. Once unset, the nodes of the recorded plan
+	// are replaced.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// MaintenanceWindow describes a recurring period of time during which nodes
+// may be disrupted
+type MaintenanceWindow struct {
+	// Schedule is a cron expression for the start of the window, with the
+	// fields minute, hour, day of month, month and day of week.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open after it starts.
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA name of the time zone the schedule is evaluated in.
+	// Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// RolloutStrategy describes how the replacements of a NodeRollout are processed
+type RolloutStrategy struct {
+	// MaxUnavailable is the maximum number of NodeReplacements of the same
+	// priority, created by this NodeRollout, that may be in progress at the same
+	// time. Value can be an absolute number (ex: 5) or a percentage of the
+	// NodeReplacements of that priority (ex: 10%). Percentages are rounded down,
+	// but at least one replacement is always allowed.
+	// Defaults to 1.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// FailurePolicy describes how a NodeRollout reacts to failed NodeReplacements
+type FailurePolicy struct {
+	// MaxFailedReplacements is the number of NodeReplacements that may fail
+	// before the NodeRollout is halted. Once exceeded, NodeReplacements that
+	// have not started are not started.
+	// If unset the NodeRollout is never halted.
+	MaxFailedReplacements *int `json:"maxFailedReplacements,omitempty"`
 }
 
-// NodeLabelSelector adds a ReplacementSpec field to the metav1.LabelSelector
+// NodeLabelSelector adds a ReplacementSpec field to the metav1.LabelSelector.
+// Nodes must match the labels, conditions and taints to be selected
 type NodeLabelSelector struct {
 	metav1.LabelSelector `json:",inline"`
-	ReplacementSpec      ReplacementSpec `json:"replacement,omitempty"`
+
+	// MatchConditions is a list of node condition requirements. The
+	// requirements are ANDed.
+	MatchConditions []NodeConditionRequirement `json:"matchConditions,omitempty"`
+
+	// MatchTaints is a list of taint requirements. The requirements are ANDed.
+	MatchTaints []TaintRequirement `json:"matchTaints,omitempty"`
+
+	// MatchFields is a list of requirements on the node's status.nodeInfo
+	// fields. The requirements are ANDed.
+	MatchFields []NodeFieldRequirement `json:"matchFields,omitempty"`
+
+	// MinAge, if set, only selects nodes that were created at least this long
+	// ago.
+	MinAge *metav1.Duration `json:"minAge,omitempty"`
+
+	ReplacementSpec ReplacementSpec `json:"replacement,omitempty"`
+}
+
+// NodeConditionRequirement selects nodes by the status of one of their
+// conditions
+type NodeConditionRequirement struct {
+	// Type of the node condition (ex: Ready, KernelDeadlock).
+	Type corev1.NodeConditionType `json:"type"`
+
+	// Status the node condition must have, one of True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// For, if set, requires the condition to have had the status for at least
+	// this long, based on its lastTransitionTime.
+	For *metav1.Duration `json:"for,omitempty"`
+}
+
+// TaintRequirement selects nodes by one of their taints
+type TaintRequirement struct {
+	// Key of the taint.
+	Key string `json:"key"`
+
+	// Value of the taint. If unset any value matches.
+	Value string `json:"value,omitempty"`
+
+	// Effect of the taint, one of NoSchedule, PreferNoSchedule or NoExecute.
+	// If unset any effect matches.
+	Effect corev1.TaintEffect `json:"effect,omitempty"`
+}
+
+// NodeField is a field of a node's status.nodeInfo that nodes can be selected
+// by
+type NodeField string
+
+const (
+	// NodeFieldKubeletVersion selects nodes by status.nodeInfo.kubeletVersion
+	NodeFieldKubeletVersion NodeField = "kubeletVersion"
+	// NodeFieldOSImage selects nodes by status.nodeInfo.osImage
+	NodeFieldOSImage NodeField = "osImage"
+	// NodeFieldContainerRuntimeVersion selects nodes by
+	// status.nodeInfo.containerRuntimeVersion
+	NodeFieldContainerRuntimeVersion NodeField = "containerRuntimeVersion"
+	// NodeFieldKernelVersion selects nodes by status.nodeInfo.kernelVersion
+	NodeFieldKernelVersion NodeField = "kernelVersion"
+)
+
+// NodeFieldOperator is the relationship between a node field and the values of
+// a NodeFieldRequirement
+type NodeFieldOperator string
+
+const (
+	// NodeFieldOpIn matches if the field is one of the values
+	NodeFieldOpIn NodeFieldOperator = "In"
+	// NodeFieldOpNotIn matches if the field is not one of the values
+	NodeFieldOpNotIn NodeFieldOperator = "NotIn"
+	// NodeFieldOpLt matches if the field is a lower version than the value
+	NodeFieldOpLt NodeFieldOperator = "Lt"
+	// NodeFieldOpGt matches if the field is a higher version than the value
+	NodeFieldOpGt NodeFieldOperator = "Gt"
+)
+
+// NodeFieldRequirement selects nodes by one of their status.nodeInfo fields
+type NodeFieldRequirement struct {
+	// Key is the node field the requirement applies to, one of kubeletVersion,
+	// osImage, containerRuntimeVersion or kernelVersion.
+	Key NodeField `json:"key"`
+
+	// Operator represents the field's relationship to the values, one of In,
+	// NotIn, Lt or Gt. Lt and Gt compare versions and are only valid for
+	// kubeletVersion.
+	Operator NodeFieldOperator `json:"operator"`
+
+	// Values is a list of values to compare the field against. Lt and Gt
+	// require exactly one value (ex: v1.16).
+	Values []string `json:"values"`
 }
 
 // NodeName pairs a Name with ReplacementSpec
@@ -54,6 +229,8 @@ const (
 	RolloutPhaseNew        NodeRolloutPhase = "New"
 	RolloutPhaseInProgress NodeRolloutPhase = "InProgress"
 	RolloutPhaseCompleted  NodeRolloutPhase = "Completed"
+	RolloutPhaseAborted    NodeRolloutPhase = "Aborted"
+	RolloutPhaseFailed     NodeRolloutPhase = "Failed"
 )
 
 // NodeRolloutStatus defines the observed state of NodeRollout
@@ -78,13 +255,78 @@ type NodeRolloutStatus struct {
 	// This is used for printing in kubectl.
 	ReplacementsCompletedCount int `json:"replacementsCompletedCount,omitempty"`
 
-	// CompletionTimestamp is a timestamp for when the rollout has completed
+	// ReplacementsFailed lists the names of all nodes whose NodeReplacements
+	// have failed to replace them.
+	ReplacementsFailed []string `json:"replacementsFailed,omitempty"`
+
+	// ReplacementsFailedCount is the count of ReplacementsFailed.
+	// This is used for printing in kubectl.
+	ReplacementsFailedCount int `json:"replacementsFailedCount,omitempty"`
+
+	// ExcludedNodes lists the nodes selected by the NodeRollout that were
+	// excluded from it, and why.
+	ExcludedNodes []ExcludedNode `json:"excludedNodes,omitempty"`
+
+	// ExcludedNodesCount is the count of ExcludedNodes.
+	// This is used for printing in kubectl.
+	ExcludedNodesCount int `json:"excludedNodesCount,omitempty"`
+
+	// Plan lists the nodes replaced by the NodeRollout, in the order they are
+	// replaced. It is computed before any NodeReplacement is created and kept
+	// up to date while the NodeRollout is a dry run.
+	Plan []PlannedNode `json:"plan,omitempty"`
+
+	// PlannedNodesCount is the count of Plan.
+	// This is used for printing in kubectl.
+	PlannedNodesCount int `json:"plannedNodesCount,omitempty"`
+
+	// NextMaintenanceWindow is a timestamp for when the next maintenance
+	// window of the NodeRollout starts
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+
+	// RestoredNodes lists the names of all nodes that were cordoned by the
+	// NodeRollout and made schedulable again when it was aborted.
+	RestoredNodes []string `json:"restoredNodes,omitempty"`
+
+	// CompletionTimestamp is a timestamp for when the rollout has completed,
+	// has failed or has finished aborting
 	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
 
 	// Conditions gives detailed condition information about the NodeRollout
 	Conditions []NodeRolloutCondition `json:"conditions,omitempty"`
 }
 
+// ExcludedNode is a node that was excluded from a NodeRollout
+type ExcludedNode struct {
+	// Name of the node.
+	Name string `json:"name"`
+
+	// Reason the node was excluded.
+	Reason string `json:"reason"`
+}
+
+// PlannedNode is a node in the plan of a NodeRollout
+type PlannedNode struct {
+	// Name of the node.
+	Name string `json:"name"`
+
+	// UID of the node. A node recreated with the same name after the plan was
+	// computed is not replaced.
+	UID types.UID `json:"uid,omitempty"`
+
+	// Priority of the NodeReplacement created for the node.
+	Priority int `json:"priority"`
+
+	// Selector is the entry of the NodeRollout that selected the node, either
+	// nodeSelectors[i] or nodeNames[i].
+	Selector string `json:"selector"`
+
+	// Order in which the node is replaced, starting at 1. Nodes with the same
+	// order share a priority and may be replaced at the same time, up to the
+	// maxUnavailable of the NodeRollout.
+	Order int `json:"order"`
+}
+
 // NodeRolloutConditionType is the type of a NodeRolloutCondition
 type NodeRolloutConditionType string
 
@@ -96,11 +338,48 @@ const (
 	// ReplacementsInProgressType refers to whether the controller is currently
 	// processing replacements
 	ReplacementsInProgressType NodeRolloutConditionType = "ReplacementsInProgress"
+
+	// RolloutPausedType refers to whether the NodeRollout is paused
+	RolloutPausedType NodeRolloutConditionType = "Paused"
+
+	// ReplacementsFailedType refers to whether any of the NodeReplacements of
+	// the NodeRollout have failed
+	ReplacementsFailedType NodeRolloutConditionType = "ReplacementsFailed"
+
+	// MaintenanceWindowOpenType refers to whether one of the maintenance
+	// windows of the NodeRollout is currently open
+	MaintenanceWindowOpenType NodeRolloutConditionType = "MaintenanceWindowOpen"
 )
 
 // NodeRolloutConditionReason represents a valid condition reason for a NodeRollout
 type NodeRolloutConditionReason string
 
+const (
+	// RolloutReasonPaused is a rollout condition for when the NodeRollout is
+	// paused
+	RolloutReasonPaused NodeRolloutConditionReason = "Paused"
+
+	// RolloutReasonResumed is a rollout condition for when the NodeRollout
+	// has been unpaused
+	RolloutReasonResumed NodeRolloutConditionReason = "Resumed"
+
+	// RolloutReasonReplacementsFailed is a rollout condition for when some of
+	// the NodeReplacements have failed, within the failure budget
+	RolloutReasonReplacementsFailed NodeRolloutConditionReason = "ReplacementsFailed"
+
+	// RolloutReasonFailureBudgetExceeded is a rollout condition for when more
+	// NodeReplacements have failed than the failure policy allows
+	RolloutReasonFailureBudgetExceeded NodeRolloutConditionReason = "FailureBudgetExceeded"
+
+	// RolloutReasonNoReplacementsFailed is a rollout condition for when the
+	// NodeReplacements that had failed have been retried
+	RolloutReasonNoReplacementsFailed NodeRolloutConditionReason = "NoReplacementsFailed"
+
+	// RolloutReasonFailed is a rollout condition for when the NodeRollout has
+	// failed
+	RolloutReasonFailed NodeRolloutConditionReason = "RolloutFailed"
+)
+
 // NodeRolloutCondition is a status condition for a NodeRollout
 type NodeRolloutCondition struct {
 	// Type of this condition
@@ -131,7 +410,12 @@ type NodeRolloutCondition struct {
 // +kubebuilder:resource:path=noderollouts,shortName=nroll;nrolls;nr;nrs
 // +kubebuilder:printcolumn:name="Replacements created",type="integer",JSONPath=".status.replacementsCreatedCount",description="Number of replacements created"
 // +kubebuilder:printcolumn:name="Replacements completed",type="integer",JSONPath=".status.replacementsCompletedCount",description="Number of replacements completed"
+// +kubebuilder:printcolumn:name="Replacements failed",type="integer",JSONPath=".status.replacementsFailedCount",description="Number of replacements failed",priority="1"
+// +kubebuilder:printcolumn:name="Excluded",type="integer",JSONPath=".status.excludedNodesCount",description="Number of nodes excluded",priority="1"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Paused",type="boolean",JSONPath=".spec.paused",priority="1"
+// +kubebuilder:printcolumn:name="Dry Run",type="boolean",JSONPath=".spec.dryRun",priority="1"
+// +kubebuilder:printcolumn:name="Next Window",type="date",JSONPath=".status.nextMaintenanceWindow",description="The time until the next maintenance window starts",priority="1"
 // +kubebuilder:printcolumn:name="Completed",type="date",JSONPath=".status.completionTimestamp",description="The time since the rollout completed"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type NodeRollout struct {
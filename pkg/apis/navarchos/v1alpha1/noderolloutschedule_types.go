@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeRolloutScheduleSpec defines the desired state of NodeRolloutSchedule
+type NodeRolloutScheduleSpec struct {
+	// Schedule is a cron expression for when NodeRollouts are created, with the
+	// fields minute, hour, day of month, month and day of week.
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the IANA name of the time zone the schedule is evaluated in.
+	// Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// ConcurrencyPolicy determines what happens when a NodeRollout is due while
+	// a NodeRollout created by the schedule is still running. Forbid skips the
+	// new NodeRollout, Replace aborts the running NodeRollouts and creates the
+	// new one.
+	// Defaults to Forbid.
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend stops the schedule from creating NodeRollouts while it is set.
+	// NodeRollouts that have already been created are not affected.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// SuccessfulRolloutsHistoryLimit is the number of completed NodeRollouts
+	// to keep. NodeRollouts created by the schedule are not deleted once they
+	// reach the controller's maximum age, only by the history limits.
+	// Defaults to 3.
+	SuccessfulRolloutsHistoryLimit *int `json:"successfulRolloutsHistoryLimit,omitempty"`
+
+	// FailedRolloutsHistoryLimit is the number of failed or aborted
+	// NodeRollouts to keep.
+	// Defaults to 1.
+	FailedRolloutsHistoryLimit *int `json:"failedRolloutsHistoryLimit,omitempty"`
+
+	// RolloutTemplate describes the NodeRollouts created by the schedule.
+	RolloutTemplate NodeRolloutTemplateSpec `json:"rolloutTemplate"`
+}
+
+// NodeRolloutTemplateSpec describes the NodeRollouts created by a
+// NodeRolloutSchedule
+type NodeRolloutTemplateSpec struct {
+	// Labels are added to the NodeRollouts created from the template.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the NodeRollouts created from the template.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Spec is the spec of the NodeRollouts created from the template.
+	Spec NodeRolloutSpec `json:"spec"`
+}
+
+// ConcurrencyPolicy describes how a NodeRolloutSchedule treats NodeRollouts
+// that are due while an earlier NodeRollout is still running
+type ConcurrencyPolicy string
+
+// The following ConcurrencyPolicies enumerate all possible ConcurrencyPolicies
+const (
+	ForbidConcurrent  ConcurrencyPolicy = "Forbid"
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// NodeRolloutScheduleStatus defines the observed state of NodeRolloutSchedule
+type NodeRolloutScheduleStatus struct {
+	// Active lists the names of the NodeRollouts created by the schedule that
+	// have not yet finished.
+	Active []string `json:"active,omitempty"`
+
+	// ActiveCount is the count of Active.
+	// This is used for printing in kubectl.
+	ActiveCount int `json:"activeCount,omitempty"`
+
+	// LastScheduleTime is a timestamp for when a NodeRollout was last due,
+	// whether or not it was created.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastRollout is the name of the last NodeRollout created by the schedule.
+	LastRollout string `json:"lastRollout,omitempty"`
+
+	// NextScheduleTime is a timestamp for when the next NodeRollout is due.
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// Conditions gives detailed condition information about the
+	// NodeRolloutSchedule
+	Conditions []NodeRolloutScheduleCondition `json:"conditions,omitempty"`
+}
+
+// NodeRolloutScheduleConditionType is the type of a
+// NodeRolloutScheduleCondition
+type NodeRolloutScheduleConditionType string
+
+const (
+	// RolloutScheduledType refers to whether the controller successfully
+	// created the last NodeRollout that was due
+	RolloutScheduledType NodeRolloutScheduleConditionType = "RolloutScheduled"
+)
+
+// NodeRolloutScheduleConditionReason represents a valid condition reason for
+// a NodeRolloutSchedule
+type NodeRolloutScheduleConditionReason string
+
+// NodeRolloutScheduleCondition is a status condition for a
+// NodeRolloutSchedule
+type NodeRolloutScheduleCondition struct {
+	// Type of this condition
+	Type NodeRolloutScheduleConditionType `json:"type"`
+
+	// Status of this condition
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastUpdateTime of this condition
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// LastTransitionTime of this condition
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason for the current status of this condition
+	Reason NodeRolloutScheduleConditionReason `json:"reason,omitempty"`
+
+	// Message associated with this condition
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// NodeRolloutSchedule is the Schema for the noderolloutschedules API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=noderolloutschedules,shortName=nrsched;nrscheds
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Active",type="integer",JSONPath=".status.activeCount",description="Number of running NodeRollouts",priority="1"
+// +kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime",description="The time since a NodeRollout was last due"
+// +kubebuilder:printcolumn:name="Next Schedule",type="date",JSONPath=".status.nextScheduleTime",description="The time until the next NodeRollout is due",priority="1"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type NodeRolloutSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeRolloutScheduleSpec   `json:"spec,omitempty"`
+	Status NodeRolloutScheduleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// NodeRolloutScheduleList contains a list of NodeRolloutSchedule
+type NodeRolloutScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeRolloutSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeRolloutSchedule{}, &NodeRolloutScheduleList{})
+}
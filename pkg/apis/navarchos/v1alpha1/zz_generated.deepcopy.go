@@ -20,13 +20,414 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalWebhook) DeepCopyInto(out *ApprovalWebhook) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalWebhook.
+func (in *ApprovalWebhook) DeepCopy() *ApprovalWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainSpec) DeepCopyInto(out *DrainSpec) {
+	*out = *in
+	if in.EvictionGracePeriod != nil {
+		in, out := &in.EvictionGracePeriod, &out.EvictionGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.IgnoreAllDaemonSets != nil {
+		in, out := &in.IgnoreAllDaemonSets, &out.IgnoreAllDaemonSets
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeleteLocalData != nil {
+		in, out := &in.DeleteLocalData, &out.DeleteLocalData
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ForcePodDeletion != nil {
+		in, out := &in.ForcePodDeletion, &out.ForcePodDeletion
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WaitForJobs != nil {
+		in, out := &in.WaitForJobs, &out.WaitForJobs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BlockingPodsTimeout != nil {
+		in, out := &in.BlockingPodsTimeout, &out.BlockingPodsTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainSpec.
+func (in *DrainSpec) DeepCopy() *DrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedNode) DeepCopyInto(out *DriftedNode) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedNode.
+func (in *DriftedNode) DeepCopy() *DriftedNode {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionWave) DeepCopyInto(out *EvictionWave) {
+	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionWave.
+func (in *EvictionWave) DeepCopy() *EvictionWave {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExcludedNode) DeepCopyInto(out *ExcludedNode) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExcludedNode.
+func (in *ExcludedNode) DeepCopy() *ExcludedNode {
+	if in == nil {
+		return nil
+	}
+	out := new(ExcludedNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailurePolicy) DeepCopyInto(out *FailurePolicy) {
+	*out = *in
+	if in.MaxFailedReplacements != nil {
+		in, out := &in.MaxFailedReplacements, &out.MaxFailedReplacements
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailurePolicy.
+func (in *FailurePolicy) DeepCopy() *FailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hook) DeepCopyInto(out *Hook) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hook.
+func (in *Hook) DeepCopy() *Hook {
+	if in == nil {
+		return nil
+	}
+	out := new(Hook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConditionRequirement) DeepCopyInto(out *NodeConditionRequirement) {
+	*out = *in
+	if in.For != nil {
+		in, out := &in.For, &out.For
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConditionRequirement.
+func (in *NodeConditionRequirement) DeepCopy() *NodeConditionRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConditionRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFieldRequirement) DeepCopyInto(out *NodeFieldRequirement) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFieldRequirement.
+func (in *NodeFieldRequirement) DeepCopy() *NodeFieldRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFieldRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFleetPolicy) DeepCopyInto(out *NodeFleetPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFleetPolicy.
+func (in *NodeFleetPolicy) DeepCopy() *NodeFleetPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFleetPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeFleetPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFleetPolicyCondition) DeepCopyInto(out *NodeFleetPolicyCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFleetPolicyCondition.
+func (in *NodeFleetPolicyCondition) DeepCopy() *NodeFleetPolicyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFleetPolicyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFleetPolicyList) DeepCopyInto(out *NodeFleetPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeFleetPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFleetPolicyList.
+func (in *NodeFleetPolicyList) DeepCopy() *NodeFleetPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFleetPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeFleetPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFleetPolicySpec) DeepCopyInto(out *NodeFleetPolicySpec) {
+	*out = *in
+	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxNodeAge != nil {
+		in, out := &in.MaxNodeAge, &out.MaxNodeAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.Replacement.DeepCopyInto(&out.Replacement)
+	if in.RolloutTemplate != nil {
+		in, out := &in.RolloutTemplate, &out.RolloutTemplate
+		*out = new(NodeRolloutTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFleetPolicySpec.
+func (in *NodeFleetPolicySpec) DeepCopy() *NodeFleetPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFleetPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFleetPolicyStatus) DeepCopyInto(out *NodeFleetPolicyStatus) {
+	*out = *in
+	if in.DriftedNodes != nil {
+		in, out := &in.DriftedNodes, &out.DriftedNodes
+		*out = make([]DriftedNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DriftedReplacements != nil {
+		in, out := &in.DriftedReplacements, &out.DriftedReplacements
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRolloutTime != nil {
+		in, out := &in.LastRolloutTime, &out.LastRolloutTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeFleetPolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFleetPolicyStatus.
+func (in *NodeFleetPolicyStatus) DeepCopy() *NodeFleetPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFleetPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeLabelSelector) DeepCopyInto(out *NodeLabelSelector) {
 	*out = *in
 	in.LabelSelector.DeepCopyInto(&out.LabelSelector)
+	if in.MatchConditions != nil {
+		in, out := &in.MatchConditions, &out.MatchConditions
+		*out = make([]NodeConditionRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MatchTaints != nil {
+		in, out := &in.MatchTaints, &out.MatchTaints
+		*out = make([]TaintRequirement, len(*in))
+		copy(*out, *in)
+	}
+	if in.MatchFields != nil {
+		in, out := &in.MatchFields, &out.MatchFields
+		*out = make([]NodeFieldRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MinAge != nil {
+		in, out := &in.MinAge, &out.MinAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	in.ReplacementSpec.DeepCopyInto(&out.ReplacementSpec)
 	return
 }
@@ -172,11 +573,59 @@ func (in *NodeReplacementStatus) DeepCopyInto(out *NodeReplacementStatus) {
 		*out = make([]PodReason, len(*in))
 		copy(*out, *in)
 	}
+	if in.DisruptionBlockedPods != nil {
+		in, out := &in.DisruptionBlockedPods, &out.DisruptionBlockedPods
+		*out = make([]PodReason, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnschedulablePods != nil {
+		in, out := &in.UnschedulablePods, &out.UnschedulablePods
+		*out = make([]PodReason, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlockingPods != nil {
+		in, out := &in.BlockingPods, &out.BlockingPods
+		*out = make([]PodReason, len(*in))
+		copy(*out, *in)
+	}
 	if in.FailedPods != nil {
 		in, out := &in.FailedPods, &out.FailedPods
 		*out = make([]PodReason, len(*in))
 		copy(*out, *in)
 	}
+	if in.CordonTimestamp != nil {
+		in, out := &in.CordonTimestamp, &out.CordonTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainStartTimestamp != nil {
+		in, out := &in.DrainStartTimestamp, &out.DrainStartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextAttemptTime != nil {
+		in, out := &in.NextAttemptTime, &out.NextAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EvictionWaves != nil {
+		in, out := &in.EvictionWaves, &out.EvictionWaves
+		*out = make([]EvictionWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplacementNodeSelector != nil {
+		in, out := &in.ReplacementNodeSelector, &out.ReplacementNodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CompletionTimestamp != nil {
 		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
 		*out = (*in).DeepCopy()
@@ -280,6 +729,148 @@ func (in *NodeRolloutList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRolloutSchedule) DeepCopyInto(out *NodeRolloutSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRolloutSchedule.
+func (in *NodeRolloutSchedule) DeepCopy() *NodeRolloutSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRolloutSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeRolloutSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRolloutScheduleCondition) DeepCopyInto(out *NodeRolloutScheduleCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRolloutScheduleCondition.
+func (in *NodeRolloutScheduleCondition) DeepCopy() *NodeRolloutScheduleCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRolloutScheduleCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRolloutScheduleList) DeepCopyInto(out *NodeRolloutScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeRolloutSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRolloutScheduleList.
+func (in *NodeRolloutScheduleList) DeepCopy() *NodeRolloutScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRolloutScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeRolloutScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRolloutScheduleSpec) DeepCopyInto(out *NodeRolloutScheduleSpec) {
+	*out = *in
+	if in.SuccessfulRolloutsHistoryLimit != nil {
+		in, out := &in.SuccessfulRolloutsHistoryLimit, &out.SuccessfulRolloutsHistoryLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.FailedRolloutsHistoryLimit != nil {
+		in, out := &in.FailedRolloutsHistoryLimit, &out.FailedRolloutsHistoryLimit
+		*out = new(int)
+		**out = **in
+	}
+	in.RolloutTemplate.DeepCopyInto(&out.RolloutTemplate)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRolloutScheduleSpec.
+func (in *NodeRolloutScheduleSpec) DeepCopy() *NodeRolloutScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRolloutScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRolloutScheduleStatus) DeepCopyInto(out *NodeRolloutScheduleStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeRolloutScheduleCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRolloutScheduleStatus.
+func (in *NodeRolloutScheduleStatus) DeepCopy() *NodeRolloutScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRolloutScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeRolloutSpec) DeepCopyInto(out *NodeRolloutSpec) {
 	*out = *in
@@ -297,6 +888,28 @@ func (in *NodeRolloutSpec) DeepCopyInto(out *NodeRolloutSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExcludeSelectors != nil {
+		in, out := &in.ExcludeSelectors, &out.ExcludeSelectors
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailurePolicy != nil {
+		in, out := &in.FailurePolicy, &out.FailurePolicy
+		*out = new(FailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -323,6 +936,30 @@ func (in *NodeRolloutStatus) DeepCopyInto(out *NodeRolloutStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ReplacementsFailed != nil {
+		in, out := &in.ReplacementsFailed, &out.ReplacementsFailed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedNodes != nil {
+		in, out := &in.ExcludedNodes, &out.ExcludedNodes
+		*out = make([]ExcludedNode, len(*in))
+		copy(*out, *in)
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = make([]PlannedNode, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.RestoredNodes != nil {
+		in, out := &in.RestoredNodes, &out.RestoredNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.CompletionTimestamp != nil {
 		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
 		*out = (*in).DeepCopy()
@@ -347,6 +984,53 @@ func (in *NodeRolloutStatus) DeepCopy() *NodeRolloutStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRolloutTemplateSpec) DeepCopyInto(out *NodeRolloutTemplateSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRolloutTemplateSpec.
+func (in *NodeRolloutTemplateSpec) DeepCopy() *NodeRolloutTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRolloutTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedNode) DeepCopyInto(out *PlannedNode) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedNode.
+func (in *PlannedNode) DeepCopy() *PlannedNode {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodReason) DeepCopyInto(out *PodReason) {
 	*out = *in
@@ -363,6 +1047,37 @@ func (in *PodReason) DeepCopy() *PodReason {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplacementHooks) DeepCopyInto(out *ReplacementHooks) {
+	*out = *in
+	if in.PreCordon != nil {
+		in, out := &in.PreCordon, &out.PreCordon
+		*out = new(Hook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreDrain != nil {
+		in, out := &in.PreDrain, &out.PreDrain
+		*out = new(Hook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostDrain != nil {
+		in, out := &in.PostDrain, &out.PostDrain
+		*out = new(Hook)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplacementHooks.
+func (in *ReplacementHooks) DeepCopy() *ReplacementHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplacementHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplacementSpec) DeepCopyInto(out *ReplacementSpec) {
 	*out = *in
@@ -371,6 +1086,26 @@ func (in *ReplacementSpec) DeepCopyInto(out *ReplacementSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.WaitForReplacement != nil {
+		in, out := &in.WaitForReplacement, &out.WaitForReplacement
+		*out = new(WaitForReplacementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(ReplacementHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalWebhook)
+		**out = **in
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -383,3 +1118,66 @@ func (in *ReplacementSpec) DeepCopy() *ReplacementSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaintRequirement) DeepCopyInto(out *TaintRequirement) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintRequirement.
+func (in *TaintRequirement) DeepCopy() *TaintRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(TaintRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitForReplacementSpec) DeepCopyInto(out *WaitForReplacementSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitForReplacementSpec.
+func (in *WaitForReplacementSpec) DeepCopy() *WaitForReplacementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitForReplacementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
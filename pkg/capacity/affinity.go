@@ -0,0 +1,235 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// toleratesTaints returns true if the pod tolerates every NoSchedule and
+// NoExecute taint of the node
+func toleratesTaints(pod *corev1.Pod, node *corev1.Node) bool {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesNodeSelector returns true if the node matches the node selector and
+// the required node affinity of the pod
+func matchesNodeSelector(pod *corev1.Pod, node *corev1.Node) bool {
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.GetLabels())) {
+		return false
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if matchesNodeSelectorTerm(term, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeSelectorTerm returns true if the node matches all requirements
+// of the term. An empty term matches no nodes
+func matchesNodeSelectorTerm(term corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, req := range term.MatchExpressions {
+		if !matchesNodeSelectorRequirement(req, node.GetLabels()) {
+			return false
+		}
+	}
+	// metadata.name is the only field supported by the scheduler
+	fields := map[string]string{"metadata.name": node.GetName()}
+	for _, req := range term.MatchFields {
+		if req.Key != "metadata.name" || !matchesNodeSelectorRequirement(req, fields) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesNodeSelectorRequirement returns true if the values match the
+// requirement
+func matchesNodeSelectorRequirement(req corev1.NodeSelectorRequirement, values map[string]string) bool {
+	var op selection.Operator
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		op = selection.In
+	case corev1.NodeSelectorOpNotIn:
+		op = selection.NotIn
+	case corev1.NodeSelectorOpExists:
+		op = selection.Exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		op = selection.DoesNotExist
+	case corev1.NodeSelectorOpGt:
+		op = selection.GreaterThan
+	case corev1.NodeSelectorOpLt:
+		op = selection.LessThan
+	default:
+		return false
+	}
+	requirement, err := labels.NewRequirement(req.Key, op, req.Values)
+	if err != nil {
+		return false
+	}
+	return requirement.Matches(labels.Set(values))
+}
+
+// satisfiesPodAffinity returns true if, for every required pod affinity term
+// of the pod, a matching pod runs in the same topology domain as the node. As
+// in the scheduler, a term is also satisfied if no pod matches it anywhere but
+// the pod matches it itself, so that the first pod of a group can be placed
+func (s nodeStates) satisfiesPodAffinity(pod *corev1.Pod, node *corev1.Node) bool {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.PodAffinity == nil {
+		return true
+	}
+	for _, term := range affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if s.anyInDomain(node, term.TopologyKey, func(other *corev1.Pod) bool { return termMatchesPod(pod, term, other) }) {
+			continue
+		}
+		if _, ok := node.GetLabels()[term.TopologyKey]; !ok {
+			return false
+		}
+		if s.anyMatching(pod, term) || !termMatchesPod(pod, term, pod) {
+			return false
+		}
+	}
+	return true
+}
+
+// satisfiesPodAntiAffinity returns true if, for every required pod
+// anti-affinity term of the pod, no matching pod runs in the same topology
+// domain as the node
+func (s nodeStates) satisfiesPodAntiAffinity(pod *corev1.Pod, node *corev1.Node) bool {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return true
+	}
+	for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if s.anyInDomain(node, term.TopologyKey, func(other *corev1.Pod) bool { return termMatchesPod(pod, term, other) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// satisfiesExistingAntiAffinity returns true if no pod in the simulation has
+// a required pod anti-affinity term that matches the pod and shares a
+// topology domain with the node
+func (s nodeStates) satisfiesExistingAntiAffinity(pod *corev1.Pod, node *corev1.Node) bool {
+	for _, state := range s {
+		for _, other := range state.pods {
+			affinity := other.Spec.Affinity
+			if affinity == nil || affinity.PodAntiAffinity == nil {
+				continue
+			}
+			for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				if sameDomain(state.node, node, term.TopologyKey) && termMatchesPod(other, term, pod) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// anyInDomain returns true if any pod in the same topology domain as the node
+// matches
+func (s nodeStates) anyInDomain(node *corev1.Node, topologyKey string, matches func(*corev1.Pod) bool) bool {
+	for _, state := range s {
+		if !sameDomain(state.node, node, topologyKey) {
+			continue
+		}
+		for _, other := range state.pods {
+			if matches(other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyMatching returns true if any pod in the simulation matches the term of
+// the pod
+func (s nodeStates) anyMatching(pod *corev1.Pod, term corev1.PodAffinityTerm) bool {
+	for _, state := range s {
+		for _, other := range state.pods {
+			if termMatchesPod(pod, term, other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameDomain returns true if both nodes have the topology key with the same
+// value
+func sameDomain(a, b *corev1.Node, topologyKey string) bool {
+	valueA, okA := a.GetLabels()[topologyKey]
+	valueB, okB := b.GetLabels()[topologyKey]
+	return okA && okB && valueA == valueB
+}
+
+// termMatchesPod returns true if the other pod is in one of the namespaces of
+// the term and matches its label selector. A term without namespaces applies
+// to the namespace of the pod it belongs to
+func termMatchesPod(pod *corev1.Pod, term corev1.PodAffinityTerm, other *corev1.Pod) bool {
+	namespaces := term.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{pod.GetNamespace()}
+	}
+	inNamespace := false
+	for _, namespace := range namespaces {
+		if namespace == other.GetNamespace() {
+			inNamespace = true
+			break
+		}
+	}
+	if !inNamespace {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(other.GetLabels()))
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Unschedulable is a pod that would not fit on any of the nodes
+type Unschedulable struct {
+	// Pod is the pod that would not fit
+	Pod corev1.Pod
+
+	// Reason summarises why each of the nodes was rejected, in the style of
+	// the scheduler's FailedScheduling events
+	Reason string
+}
+
+// nodeState tracks the pods on a node and the resources they request during
+// the simulation
+type nodeState struct {
+	node      *corev1.Node
+	pods      []*corev1.Pod
+	requested corev1.ResourceList
+}
+
+// Simulate places each of the pods on one of the nodes, alongside the pods
+// already running on them, and returns the pods that would not fit on any
+// node, sorted by name. Pods are placed largest first, each on the feasible
+// node with the most unrequested CPU. Running pods on nodes that are not
+// given are ignored
+func Simulate(pods []corev1.Pod, nodes []corev1.Node, running []corev1.Pod) []Unschedulable {
+	states := newNodeStates(nodes, running)
+
+	ordered := make([]*corev1.Pod, 0, len(pods))
+	for i := range pods {
+		ordered = append(ordered, &pods[i])
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return largerPod(ordered[i], ordered[j])
+	})
+
+	unschedulable := []Unschedulable{}
+	for _, pod := range ordered {
+		requests := podRequests(pod)
+		var best *nodeState
+		reasons := make(map[string]int)
+		for _, state := range states {
+			if reason := states.rejectReason(state, pod, requests); reason != "" {
+				reasons[reason]++
+				continue
+			}
+			if best == nil || freeCPU(state).Cmp(*freeCPU(best)) > 0 {
+				best = state
+			}
+		}
+
+		if best == nil {
+			unschedulable = append(unschedulable, Unschedulable{
+				Pod:    *pod,
+				Reason: summarise(len(states), reasons),
+			})
+			continue
+		}
+		best.add(pod, requests)
+	}
+
+	sort.Slice(unschedulable, func(i, j int) bool {
+		return unschedulable[i].Pod.GetName() < unschedulable[j].Pod.GetName()
+	})
+	return unschedulable
+}
+
+// nodeStates is the state of every node in the simulation
+type nodeStates []*nodeState
+
+// newNodeStates builds the state of the nodes, sorted by name, from the
+// running pods that have not finished
+func newNodeStates(nodes []corev1.Node, running []corev1.Pod) nodeStates {
+	byName := make(map[string]*nodeState, len(nodes))
+	states := make(nodeStates, 0, len(nodes))
+	for i := range nodes {
+		state := &nodeState{node: &nodes[i], requested: corev1.ResourceList{}}
+		byName[nodes[i].GetName()] = state
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].node.GetName() < states[j].node.GetName()
+	})
+
+	for i := range running {
+		pod := &running[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if state, ok := byName[pod.Spec.NodeName]; ok {
+			state.add(pod, podRequests(pod))
+		}
+	}
+	return states
+}
+
+// add places the pod on the node
+func (s *nodeState) add(pod *corev1.Pod, requests corev1.ResourceList) {
+	s.pods = append(s.pods, pod)
+	addResources(s.requested, requests)
+}
+
+// rejectReason returns why the pod cannot be placed on the node, or an empty
+// string if it can
+func (s nodeStates) rejectReason(state *nodeState, pod *corev1.Pod, requests corev1.ResourceList) string {
+	node := state.node
+	if node.Spec.Unschedulable {
+		return "node(s) were unschedulable"
+	}
+	if !isReady(node) {
+		return "node(s) were not ready"
+	}
+	if !toleratesTaints(pod, node) {
+		return "node(s) had taints that the pod didn't tolerate"
+	}
+	if !matchesNodeSelector(pod, node) {
+		return "node(s) didn't match node selector"
+	}
+	if insufficient := insufficientResources(state, requests); len(insufficient) > 0 {
+		return fmt.Sprintf("Insufficient %s", strings.Join(insufficient, ", "))
+	}
+	if !s.satisfiesPodAffinity(pod, node) {
+		return "node(s) didn't match pod affinity rules"
+	}
+	if !s.satisfiesPodAntiAffinity(pod, node) {
+		return "node(s) didn't match pod anti-affinity rules"
+	}
+	if !s.satisfiesExistingAntiAffinity(pod, node) {
+		return "node(s) didn't satisfy existing pods anti-affinity rules"
+	}
+	return ""
+}
+
+// isReady returns true if the node has a Ready condition with status True
+func isReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// insufficientResources returns the names of the resources the node does not
+// have enough of left for the pod, sorted by name
+func insufficientResources(state *nodeState, requests corev1.ResourceList) []string {
+	allocatable := state.node.Status.Allocatable
+	insufficient := []string{}
+	if max, ok := allocatable[corev1.ResourcePods]; ok && int64(len(state.pods)+1) > max.Value() {
+		insufficient = append(insufficient, string(corev1.ResourcePods))
+	}
+	for name, request := range requests {
+		if request.IsZero() {
+			continue
+		}
+		free := freeResource(state, name)
+		if free.Cmp(request) < 0 {
+			insufficient = append(insufficient, string(name))
+		}
+	}
+	sort.Strings(insufficient)
+	return insufficient
+}
+
+// freeResource returns how much of the resource of the node is not yet
+// requested
+func freeResource(state *nodeState, name corev1.ResourceName) resource.Quantity {
+	allocatable := state.node.Status.Allocatable[name]
+	free := allocatable.DeepCopy()
+	free.Sub(state.requested[name])
+	return free
+}
+
+// freeCPU returns how much CPU of the node is not yet requested
+func freeCPU(state *nodeState) *resource.Quantity {
+	free := freeResource(state, corev1.ResourceCPU)
+	return &free
+}
+
+// podRequests returns the resources requested by the pod. Init containers run
+// one at a time before the containers, so the pod requests the larger of the
+// sum of its containers and its largest init container
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, request := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || request.Cmp(current) > 0 {
+				requests[name] = request.DeepCopy()
+			}
+		}
+	}
+	return requests
+}
+
+// addResources adds the resources to the total
+func addResources(total, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		current := total[name]
+		sum := current.DeepCopy()
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+// largerPod returns true if pod a requests more CPU, or the same CPU and more
+// memory, than pod b
+func largerPod(a, b *corev1.Pod) bool {
+	requestsA, requestsB := podRequests(a), podRequests(b)
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if cmp := requestsA[name]; cmp.Cmp(requestsB[name]) != 0 {
+			return cmp.Cmp(requestsB[name]) > 0
+		}
+	}
+	return false
+}
+
+// summarise formats the reasons the nodes were rejected like the scheduler
+// does, for example "0/3 nodes are available: 2 Insufficient cpu, 1 node(s)
+// were unschedulable."
+func summarise(nodes int, reasons map[string]int) string {
+	counts := []string{}
+	for reason, count := range reasons {
+		counts = append(counts, fmt.Sprintf("%d %s", count, reason))
+	}
+	sort.Strings(counts)
+	if len(counts) == 0 {
+		return fmt.Sprintf("0/%d nodes are available.", nodes)
+	}
+	return fmt.Sprintf("0/%d nodes are available: %s.", nodes, strings.Join(counts, ", "))
+}
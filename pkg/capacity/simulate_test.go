@@ -0,0 +1,243 @@
+package capacity
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Simulate", func() {
+	var newNode = func(name, zone, cpu string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"zone": zone},
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:  resource.MustParse(cpu),
+					corev1.ResourcePods: resource.MustParse("110"),
+				},
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+	}
+
+	var newPod = func(name, nodeName, cpu string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"app": name},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{
+					{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	var podNames = func(unschedulable []Unschedulable) []string {
+		names := []string{}
+		for _, u := range unschedulable {
+			names = append(names, u.Pod.GetName())
+		}
+		return names
+	}
+
+	var pods []corev1.Pod
+	var nodes []corev1.Node
+	var running []corev1.Pod
+	var unschedulable []Unschedulable
+
+	BeforeEach(func() {
+		pods = []corev1.Pod{
+			newPod("web", "", "500m"),
+			newPod("worker", "", "1"),
+		}
+		nodes = []corev1.Node{
+			newNode("node-a", "a", "2"),
+			newNode("node-b", "b", "2"),
+		}
+		running = []corev1.Pod{
+			newPod("existing-a", "node-a", "1"),
+			newPod("existing-b", "node-b", "1"),
+		}
+	})
+
+	JustBeforeEach(func() {
+		unschedulable = Simulate(pods, nodes, running)
+	})
+
+	It("fits pods that have enough room", func() {
+		Expect(unschedulable).To(BeEmpty())
+	})
+
+	Context("when the remaining nodes do not have enough CPU", func() {
+		BeforeEach(func() {
+			pods = append(pods, newPod("batch", "", "1"))
+		})
+
+		It("reports the pod that does not fit", func() {
+			Expect(podNames(unschedulable)).To(ConsistOf("web"))
+			Expect(unschedulable[0].Reason).To(Equal("0/2 nodes are available: 2 Insufficient cpu."))
+		})
+	})
+
+	Context("when a node is cordoned or not ready", func() {
+		BeforeEach(func() {
+			nodes[0].Spec.Unschedulable = true
+			nodes[1].Status.Conditions[0].Status = corev1.ConditionFalse
+		})
+
+		It("does not place pods on it", func() {
+			Expect(podNames(unschedulable)).To(ConsistOf("web", "worker"))
+			Expect(unschedulable[0].Reason).To(Equal("0/2 nodes are available: 1 node(s) were not ready, 1 node(s) were unschedulable."))
+		})
+	})
+
+	Context("when a node has a taint", func() {
+		BeforeEach(func() {
+			nodes[1].Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+			pods = []corev1.Pod{newPod("web", "", "2")}
+			running = nil
+		})
+
+		It("only places pods on it that tolerate the taint", func() {
+			Expect(unschedulable).To(BeEmpty())
+		})
+
+		Context("and the pod must run on it", func() {
+			BeforeEach(func() {
+				pods[0].Spec.NodeSelector = map[string]string{"zone": "b"}
+			})
+
+			It("reports the pod", func() {
+				Expect(podNames(unschedulable)).To(ConsistOf("web"))
+				Expect(unschedulable[0].Reason).To(Equal("0/2 nodes are available: 1 node(s) didn't match node selector, 1 node(s) had taints that the pod didn't tolerate."))
+			})
+
+			Context("and tolerates the taint", func() {
+				BeforeEach(func() {
+					pods[0].Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+				})
+
+				It("fits the pod", func() {
+					Expect(unschedulable).To(BeEmpty())
+				})
+			})
+		})
+	})
+
+	Context("when a pod requires node affinity", func() {
+		BeforeEach(func() {
+			pods[0].Spec.Affinity = &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"c"}},
+								},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("reports the pod if no node matches", func() {
+			Expect(podNames(unschedulable)).To(ConsistOf("web"))
+			Expect(unschedulable[0].Reason).To(Equal("0/2 nodes are available: 2 node(s) didn't match node selector."))
+		})
+	})
+
+	Context("when a pod has pod anti-affinity by zone", func() {
+		BeforeEach(func() {
+			pods = []corev1.Pod{newPod("web", "", "500m")}
+			pods[0].Spec.Affinity = &corev1.Affinity{
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+						{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "web"}},
+							TopologyKey:   "zone",
+						},
+					},
+				},
+			}
+			running[0].Labels["role"] = "web"
+		})
+
+		It("places the pod in another zone", func() {
+			Expect(unschedulable).To(BeEmpty())
+		})
+
+		Context("and matching pods run in every zone", func() {
+			BeforeEach(func() {
+				running[1].Labels["role"] = "web"
+			})
+
+			It("reports the pod", func() {
+				Expect(podNames(unschedulable)).To(ConsistOf("web"))
+				Expect(unschedulable[0].Reason).To(Equal("0/2 nodes are available: 2 node(s) didn't match pod anti-affinity rules."))
+			})
+		})
+	})
+
+	Context("when a running pod has pod anti-affinity against a pod", func() {
+		BeforeEach(func() {
+			pods = []corev1.Pod{newPod("web", "", "500m")}
+			for i := range running {
+				running[i].Spec.Affinity = &corev1.Affinity{
+					PodAntiAffinity: &corev1.PodAntiAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+							{
+								LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+								TopologyKey:   "zone",
+							},
+						},
+					},
+				}
+			}
+		})
+
+		It("reports the pod", func() {
+			Expect(podNames(unschedulable)).To(ConsistOf("web"))
+			Expect(unschedulable[0].Reason).To(Equal("0/2 nodes are available: 2 node(s) didn't satisfy existing pods anti-affinity rules."))
+		})
+	})
+
+	Context("when a pod requires pod affinity", func() {
+		BeforeEach(func() {
+			pods = []corev1.Pod{newPod("web", "", "500m")}
+			pods[0].Spec.Affinity = &corev1.Affinity{
+				PodAffinity: &corev1.PodAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+						{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cache"}},
+							TopologyKey:   "zone",
+						},
+					},
+				},
+			}
+			running[1].Labels["app"] = "cache"
+			running[1].Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("1800m")
+		})
+
+		It("reports the pod if the node with the matching pod is full", func() {
+			Expect(podNames(unschedulable)).To(ConsistOf("web"))
+			Expect(unschedulable[0].Reason).To(Equal("0/2 nodes are available: 1 Insufficient cpu, 1 node(s) didn't match pod affinity rules."))
+		})
+	})
+})
@@ -18,9 +18,12 @@ package controller
 
 import (
 	"github.com/pusher/navarchos/pkg/controller/nodereplacement"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
-	AddToManagerFuncs = append(AddToManagerFuncs, nodereplacement.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, func(m manager.Manager, opts *Options) error {
+		return nodereplacement.Add(m, &opts.NodeReplacementOptions)
+	})
 }
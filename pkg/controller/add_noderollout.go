@@ -18,9 +18,12 @@ package controller
 
 import (
 	"github.com/pusher/navarchos/pkg/controller/noderollout"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
-	AddToManagerFuncs = append(AddToManagerFuncs, noderollout.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, func(m manager.Manager, opts *Options) error {
+		return noderollout.Add(m, &opts.NodeRolloutOptions)
+	})
 }
@@ -17,18 +17,36 @@ limitations under the License.
 package controller
 
 import (
+	nodereplacementhandler "github.com/pusher/navarchos/pkg/controller/nodereplacement/handler"
+	noderollouthandler "github.com/pusher/navarchos/pkg/controller/noderollout/handler"
+	noderolloutschedulehandler "github.com/pusher/navarchos/pkg/controller/noderolloutschedule/handler"
+	navarchosmetrics "github.com/pusher/navarchos/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+// Options are used to configure the Controllers added to the Manager
+type Options struct {
+	// NodeReplacementOptions configure the NodeReplacement controller
+	NodeReplacementOptions nodereplacementhandler.Options
+
+	// NodeRolloutOptions configure the NodeRollout controller
+	NodeRolloutOptions noderollouthandler.Options
+
+	// NodeRolloutScheduleOptions configure the NodeRolloutSchedule controller
+	NodeRolloutScheduleOptions noderolloutschedulehandler.Options
+}
+
 // AddToManagerFuncs is a list of functions to add all Controllers to the Manager
-var AddToManagerFuncs []func(manager.Manager) error
+var AddToManagerFuncs []func(manager.Manager, *Options) error
 
-// AddToManager adds all Controllers to the Manager
-func AddToManager(m manager.Manager) error {
+// AddToManager adds all Controllers to the Manager and registers the collector
+// counting NodeRollouts and NodeReplacements on its metrics registry
+func AddToManager(m manager.Manager, opts *Options) error {
 	for _, f := range AddToManagerFuncs {
-		if err := f(m); err != nil {
+		if err := f(m, opts); err != nil {
 			return err
 		}
 	}
-	return nil
+	return metrics.Registry.Register(navarchosmetrics.NewCollector(m.GetClient()))
 }
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// nodeDrift returns the reasons the node does not match the policy. If the
+// node matches the policy no reasons are returned
+func nodeDrift(spec navarchosv1alpha1.NodeFleetPolicySpec, node *corev1.Node, now time.Time) []string {
+	var reasons []string
+	info := node.Status.NodeInfo
+
+	if spec.KubeletVersion != "" && !sameVersion(info.KubeletVersion, spec.KubeletVersion) {
+		reasons = append(reasons, fmt.Sprintf("kubelet version is %s, expected %s", info.KubeletVersion, spec.KubeletVersion))
+	}
+
+	if spec.OSImage != "" && info.OSImage != spec.OSImage {
+		reasons = append(reasons, fmt.Sprintf("OS image is %q, expected %q", info.OSImage, spec.OSImage))
+	}
+
+	// Sort the keys so that the reasons are stable between reconciles
+	for _, key := range sortedKeys(spec.RequiredLabels) {
+		value, ok := node.GetLabels()[key]
+		if !ok {
+			reasons = append(reasons, fmt.Sprintf("label %s is missing", key))
+			continue
+		}
+		if value != spec.RequiredLabels[key] {
+			reasons = append(reasons, fmt.Sprintf("label %s is %q, expected %q", key, value, spec.RequiredLabels[key]))
+		}
+	}
+
+	if tooOld(spec, node, now) {
+		reasons = append(reasons, fmt.Sprintf("node has reached the maximum age of %s", spec.MaxNodeAge.Duration))
+	}
+
+	return reasons
+}
+
+// sameVersion returns true if the versions have the same major, minor and
+// patch version, ignoring suffixes such as the "-eks-5047ed" of provider
+// builds. Versions that cannot be parsed must be equal
+func sameVersion(a, b string) bool {
+	versionA, err := version.ParseGeneric(a)
+	if err != nil {
+		return a == b
+	}
+	versionB, err := version.ParseGeneric(b)
+	if err != nil {
+		return a == b
+	}
+	return !versionA.LessThan(versionB) && !versionB.LessThan(versionA)
+}
+
+// tooOld returns true if the policy limits the node age and the node has
+// reached it
+func tooOld(spec navarchosv1alpha1.NodeFleetPolicySpec, node *corev1.Node, now time.Time) bool {
+	return spec.MaxNodeAge != nil && now.Sub(node.GetCreationTimestamp().Time) >= spec.MaxNodeAge.Duration
+}
+
+// untilTooOld returns how long until the node reaches the maximum node
+// age of the policy. It is zero if the policy does not limit the node age or
+// the node is already too old
+func untilTooOld(spec navarchosv1alpha1.NodeFleetPolicySpec, node *corev1.Node, now time.Time) time.Duration {
+	if spec.MaxNodeAge == nil {
+		return 0
+	}
+	until := node.GetCreationTimestamp().Add(spec.MaxNodeAge.Duration).Sub(now)
+	if until < 0 {
+		return 0
+	}
+	return until
+}
+
+// policyHash returns a hash of the kubelet version, OS image and required
+// labels of the policy. These are what the node group launches nodes with, so
+// a NodeRollout records the hash it was created for
+func policyHash(spec navarchosv1alpha1.NodeFleetPolicySpec) string {
+	hasher := fnv.New32a()
+	fmt.Fprintf(hasher, "%s\n%s\n", spec.KubeletVersion, spec.OSImage)
+	for _, key := range sortedKeys(spec.RequiredLabels) {
+		fmt.Fprintf(hasher, "%s=%s\n", key, spec.RequiredLabels[key])
+	}
+	return fmt.Sprintf("%x", hasher.Sum32())
+}
+
+// sortedKeys returns the keys of the map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
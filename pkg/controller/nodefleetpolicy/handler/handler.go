@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodefleetpolicy/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metalabels "k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NodeFleetPolicyHandler handles the business logic within the
+// NodeFleetPolicy controller.
+type NodeFleetPolicyHandler struct {
+	client client.Client
+}
+
+// NewNodeFleetPolicyHandler creates a new NodeFleetPolicyHandler
+func NewNodeFleetPolicyHandler(c client.Client) *NodeFleetPolicyHandler {
+	return &NodeFleetPolicyHandler{
+		client: c,
+	}
+}
+
+// Handle performs the business logic of the NodeFleetPolicy and returns
+// information in a Result. It compares the nodes selected by the policy
+// against it and creates a NodeRollout for the nodes that have drifted,
+// unless one created by the policy is still running. Drifted nodes created
+// since the previous NodeRollout started are not replaced again while the
+// policy is unchanged, so that a node group still launching nodes with the old
+// configuration does not cause NodeRollouts to be created in a loop. The
+// previous NodeRollout is recorded in the status, as finished NodeRollouts are
+// eventually deleted
+func (h *NodeFleetPolicyHandler) Handle(instance *navarchosv1alpha1.NodeFleetPolicy) (*status.Result, error) {
+	// Keep the existing status should the nodes not be listed
+	result := &status.Result{
+		NodesCount:          instance.Status.NodesCount,
+		DriftedNodes:        instance.Status.DriftedNodes,
+		DriftedReplacements: instance.Status.DriftedReplacements,
+		ActiveRollout:       instance.Status.ActiveRollout,
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&instance.Spec.NodeSelector)
+	if err != nil {
+		result.DriftDetectedReason = "InvalidNodeSelector"
+		result.DriftDetectedError = fmt.Errorf("invalid node selector: %v", err)
+		return result, nil
+	}
+
+	nodes := &corev1.NodeList{}
+	err = h.client.List(context.Background(), nodes)
+	if err != nil {
+		result.DriftDetectedReason = "ErrorListingNodes"
+		result.DriftDetectedError = fmt.Errorf("failed to list nodes: %v", err)
+		return result, result.DriftDetectedError
+	}
+
+	activeRollout, err := h.activeRollout(instance)
+	if err != nil {
+		return result, err
+	}
+	result.ActiveRollout = activeRollout
+
+	now := time.Now()
+	hash := policyHash(instance.Spec)
+	result.NodesCount = 0
+	result.DriftedNodes = nil
+	result.DriftedReplacements = nil
+	var replaceable []string
+	for _, node := range nodes.Items {
+		if !selector.Matches(metalabels.Set(node.GetLabels())) {
+			continue
+		}
+		result.NodesCount++
+
+		reasons := nodeDrift(instance.Spec, &node, now)
+		if len(reasons) == 0 {
+			// Requeue the policy for when the first node becomes too old
+			until := untilTooOld(instance.Spec, &node, now)
+			if until > 0 && (result.RequeueAfter == 0 || until < result.RequeueAfter) {
+				result.RequeueAfter = until
+			}
+			continue
+		}
+		result.DriftedNodes = append(result.DriftedNodes, navarchosv1alpha1.DriftedNode{
+			Name:    node.GetName(),
+			Reasons: reasons,
+		})
+
+		// Cordoned nodes are left alone, they are either being replaced
+		// already or were cordoned by an operator. Excluded nodes are never
+		// replaced
+		if node.Spec.Unschedulable || navarchosv1alpha1.HasExcludeAnnotation(&node) {
+			continue
+		}
+
+		// Nodes that drifted again after being launched by the previous
+		// NodeRollout are left alone, unless they are too old
+		if launchedSince(instance.Status, hash, &node) && !tooOld(instance.Spec, &node, now) {
+			result.DriftedReplacements = append(result.DriftedReplacements, node.GetName())
+			continue
+		}
+		replaceable = append(replaceable, node.GetName())
+	}
+
+	if len(result.DriftedNodes) == 0 {
+		result.DriftDetectedReason = "NoDrift"
+		return result, nil
+	}
+	result.DriftDetectedReason = "NodesDrifted"
+
+	if result.ActiveRollout != "" {
+		return result, nil
+	}
+
+	if len(replaceable) == 0 {
+		if len(result.DriftedReplacements) > 0 {
+			result.RolloutCreatedReason = "ReplacementsDrifted"
+			result.RolloutCreatedError = fmt.Errorf("node(s) launched since NodeRollout %s started still drift from the policy, check the configuration of the node group: %s", instance.Status.LastRollout, strings.Join(result.DriftedReplacements, ", "))
+		}
+		return result, nil
+	}
+
+	if instance.Spec.Suspend {
+		result.RolloutCreatedReason = "Suspended"
+		result.RolloutCreatedError = fmt.Errorf("policy is suspended, no NodeRollouts will be created")
+		return result, nil
+	}
+
+	rollout := newNodeRollout(instance, replaceable)
+	err = h.client.Create(context.Background(), rollout)
+	if err != nil {
+		result.RolloutCreatedReason = "ErrorCreatingNodeRollout"
+		result.RolloutCreatedError = fmt.Errorf("error creating NodeRollout: %v", err)
+		return result, result.RolloutCreatedError
+	}
+
+	result.ActiveRollout = rollout.GetName()
+	result.LastRollout = rollout.GetName()
+	result.LastRolloutHash = hash
+	result.LastRolloutTime = &rollout.CreationTimestamp
+	result.RolloutCreatedReason = "RolloutCreated"
+	return result, nil
+}
+
+// activeRollout returns the name of the NodeRollout controlled by the
+// NodeFleetPolicy that has not yet finished, if any
+func (h *NodeFleetPolicyHandler) activeRollout(instance *navarchosv1alpha1.NodeFleetPolicy) (string, error) {
+	rollouts := &navarchosv1alpha1.NodeRolloutList{}
+	err := h.client.List(context.Background(), rollouts)
+	if err != nil {
+		return "", fmt.Errorf("error listing NodeRollouts: %v", err)
+	}
+
+	for _, rollout := range rollouts.Items {
+		if metav1.IsControlledBy(&rollout, instance) && rollout.Status.CompletionTimestamp == nil {
+			return rollout.GetName(), nil
+		}
+	}
+	return "", nil
+}
+
+// launchedSince returns true if the node was created after the last NodeRollout
+// recorded in the status started and that NodeRollout was created for the same
+// policy hash
+func launchedSince(status navarchosv1alpha1.NodeFleetPolicyStatus, hash string, node *corev1.Node) bool {
+	if status.LastRolloutTime == nil || status.LastRolloutHash != hash {
+		return false
+	}
+	created := node.GetCreationTimestamp()
+	return !created.Before(status.LastRolloutTime)
+}
+
+// newNodeRollout creates a NodeRollout from the template of the
+// NodeFleetPolicy that replaces the given nodes
+func newNodeRollout(instance *navarchosv1alpha1.NodeFleetPolicy, nodeNames []string) *navarchosv1alpha1.NodeRollout {
+	isController := true
+	blockOwnerDeletion := true
+	gvk := navarchosv1alpha1.SchemeGroupVersion.WithKind("NodeFleetPolicy")
+
+	rollout := &navarchosv1alpha1.NodeRollout{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: navarchosv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "NodeRollout",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", instance.GetName()),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         gvk.GroupVersion().String(),
+					Kind:               gvk.Kind,
+					Name:               instance.GetName(),
+					UID:                instance.GetUID(),
+					Controller:         &isController,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Status: navarchosv1alpha1.NodeRolloutStatus{
+			Phase: navarchosv1alpha1.RolloutPhaseNew,
+		},
+	}
+
+	if instance.Spec.RolloutTemplate != nil {
+		template := instance.Spec.RolloutTemplate.DeepCopy()
+		rollout.SetLabels(template.Labels)
+		rollout.SetAnnotations(template.Annotations)
+		rollout.Spec = template.Spec
+	}
+
+	replacement := instance.Spec.Replacement.DeepCopy()
+	if replacement.Priority == nil {
+		priority := 0
+		replacement.Priority = &priority
+	}
+
+	rollout.Spec.NodeSelectors = nil
+	rollout.Spec.NodeNames = nil
+	for _, name := range nodeNames {
+		rollout.Spec.NodeNames = append(rollout.Spec.NodeNames, navarchosv1alpha1.NodeName{
+			Name:            name,
+			ReplacementSpec: *replacement.DeepCopy(),
+		})
+	}
+	return rollout
+}
@@ -0,0 +1,384 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodefleetpolicy/status"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("NodeFleetPolicy Handler suite", func() {
+	var m utils.Matcher
+	var h *NodeFleetPolicyHandler
+
+	var nodeFleetPolicy *navarchosv1alpha1.NodeFleetPolicy
+	var mgrStopped *sync.WaitGroup
+	var stopMgr chan struct{}
+
+	var workerNode1 *corev1.Node
+	var workerNode2 *corev1.Node
+	var masterNode1 *corev1.Node
+
+	const timeout = time.Second * 5
+	const consistentlyTimeout = time.Second
+
+	var setKubeletVersion = func(node *corev1.Node, version string) {
+		m.UpdateStatus(node, func(obj utils.Object) utils.Object {
+			n, _ := obj.(*corev1.Node)
+			n.Status.NodeInfo.KubeletVersion = version
+			return n
+		}, timeout).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+
+		h = NewNodeFleetPolicyHandler(m.Client)
+
+		workerNode1 = utils.ExampleNodeWorker1.DeepCopy()
+		m.Create(workerNode1).Should(Succeed())
+		setKubeletVersion(workerNode1, "v1.14.3")
+		workerNode2 = utils.ExampleNodeWorker2.DeepCopy()
+		m.Create(workerNode2).Should(Succeed())
+		setKubeletVersion(workerNode2, "v1.13.5")
+		masterNode1 = utils.ExampleNodeMaster1.DeepCopy()
+		m.Create(masterNode1).Should(Succeed())
+		setKubeletVersion(masterNode1, "v1.13.5")
+
+		nodeFleetPolicy = utils.ExampleNodeFleetPolicy.DeepCopy()
+		m.Create(nodeFleetPolicy).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeRolloutList{},
+			&navarchosv1alpha1.NodeFleetPolicyList{},
+			&corev1.NodeList{},
+		)
+	})
+
+	Context("nodeDrift", func() {
+		var node *corev1.Node
+		var spec navarchosv1alpha1.NodeFleetPolicySpec
+		var now time.Time
+		var reasons []string
+
+		BeforeEach(func() {
+			now = time.Now()
+			node = &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.NewTime(now.Add(-24 * time.Hour)),
+					Labels: map[string]string{
+						"pool": "workers",
+					},
+				},
+				Status: corev1.NodeStatus{
+					NodeInfo: corev1.NodeSystemInfo{
+						KubeletVersion: "v1.14.3",
+						OSImage:        "Container Linux by CoreOS 2079.3.0 (Rhyolite)",
+					},
+				},
+			}
+			spec = navarchosv1alpha1.NodeFleetPolicySpec{
+				KubeletVersion: "v1.14.3",
+				OSImage:        "Container Linux by CoreOS 2079.3.0 (Rhyolite)",
+				RequiredLabels: map[string]string{
+					"pool": "workers",
+				},
+				MaxNodeAge: &metav1.Duration{Duration: 48 * time.Hour},
+			}
+		})
+
+		JustBeforeEach(func() {
+			reasons = nodeDrift(spec, node, now)
+		})
+
+		It("returns no reasons for a node matching the policy", func() {
+			Expect(reasons).To(BeEmpty())
+		})
+
+		Context("when the kubelet version differs", func() {
+			BeforeEach(func() {
+				node.Status.NodeInfo.KubeletVersion = "v1.13.5"
+			})
+
+			It("returns the kubelet version", func() {
+				Expect(reasons).To(ConsistOf("kubelet version is v1.13.5, expected v1.14.3"))
+			})
+		})
+
+		Context("when the kubelet version only differs by a build suffix", func() {
+			BeforeEach(func() {
+				node.Status.NodeInfo.KubeletVersion = "v1.14.3-eks-5047ed"
+			})
+
+			It("returns no reasons", func() {
+				Expect(reasons).To(BeEmpty())
+			})
+		})
+
+		Context("when the OS image differs", func() {
+			BeforeEach(func() {
+				node.Status.NodeInfo.OSImage = "Ubuntu 18.04.2 LTS"
+			})
+
+			It("returns the OS image", func() {
+				Expect(reasons).To(ConsistOf(ContainSubstring("OS image")))
+			})
+		})
+
+		Context("when a required label is missing or differs", func() {
+			BeforeEach(func() {
+				spec.RequiredLabels["zone"] = "a"
+				node.Labels["pool"] = "spot"
+			})
+
+			It("returns each label", func() {
+				Expect(reasons).To(ConsistOf(
+					"label pool is \"spot\", expected \"workers\"",
+					"label zone is missing",
+				))
+			})
+		})
+
+		Context("when the node is older than the maximum node age", func() {
+			BeforeEach(func() {
+				spec.MaxNodeAge = &metav1.Duration{Duration: time.Hour}
+			})
+
+			It("returns the node age", func() {
+				Expect(reasons).To(ConsistOf(ContainSubstring("maximum age")))
+			})
+		})
+	})
+
+	Context("Handle", func() {
+		var result *status.Result
+		var handleErr error
+
+		JustBeforeEach(func() {
+			result, handleErr = h.Handle(nodeFleetPolicy)
+		})
+
+		Context("when a selected node has drifted", func() {
+			It("reports the drifted node", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+				Expect(result.NodesCount).To(Equal(2))
+				Expect(result.DriftedNodes).To(ConsistOf(navarchosv1alpha1.DriftedNode{
+					Name:    workerNode2.GetName(),
+					Reasons: []string{"kubelet version is v1.13.5, expected v1.14.3"},
+				}))
+				Expect(result.DriftDetectedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("NodesDrifted")))
+			})
+
+			It("creates a NodeRollout controlled by the NodeFleetPolicy for the drifted node", func() {
+				rollout := &navarchosv1alpha1.NodeRollout{ObjectMeta: metav1.ObjectMeta{Name: result.ActiveRollout}}
+				m.Get(rollout, timeout).Should(Succeed())
+				Expect(metav1.IsControlledBy(rollout, nodeFleetPolicy)).To(BeTrue())
+				Expect(rollout.Spec.NodeNames).To(ConsistOf(navarchosv1alpha1.NodeName{
+					Name:            workerNode2.GetName(),
+					ReplacementSpec: nodeFleetPolicy.Spec.Replacement,
+				}))
+				Expect(result.RolloutCreatedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("RolloutCreated")))
+			})
+
+			It("records the NodeRollout as the last one created by the NodeFleetPolicy", func() {
+				Expect(result.LastRollout).To(Equal(result.ActiveRollout))
+				Expect(result.LastRolloutHash).To(Equal(policyHash(nodeFleetPolicy.Spec)))
+				Expect(result.LastRolloutTime).ToNot(BeNil())
+			})
+
+			Context("and the NodeFleetPolicy has a rollout template", func() {
+				BeforeEach(func() {
+					nodeFleetPolicy.Spec.RolloutTemplate = &navarchosv1alpha1.NodeRolloutTemplateSpec{
+						Labels: map[string]string{"policy": "example"},
+						Spec:   *utils.ExampleNodeRollout.Spec.DeepCopy(),
+					}
+					nodeFleetPolicy.Spec.RolloutTemplate.Spec.Paused = true
+				})
+
+				It("creates the NodeRollout from the template, replacing its selectors", func() {
+					rollout := &navarchosv1alpha1.NodeRollout{ObjectMeta: metav1.ObjectMeta{Name: result.ActiveRollout}}
+					m.Get(rollout, timeout).Should(Succeed())
+					Expect(rollout.GetLabels()).To(HaveKeyWithValue("policy", "example"))
+					Expect(rollout.Spec.Paused).To(BeTrue())
+					Expect(rollout.Spec.NodeSelectors).To(BeEmpty())
+					Expect(rollout.Spec.NodeNames).To(HaveLen(1))
+				})
+			})
+
+			Context("and the drifted node is cordoned", func() {
+				BeforeEach(func() {
+					m.Update(workerNode2, func(obj utils.Object) utils.Object {
+						n, _ := obj.(*corev1.Node)
+						n.Spec.Unschedulable = true
+						return n
+					}, timeout).Should(Succeed())
+				})
+
+				It("reports the drifted node without creating a NodeRollout", func() {
+					Expect(result.DriftedNodes).To(HaveLen(1))
+					m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+				})
+			})
+
+			Context("and the drifted node is excluded", func() {
+				BeforeEach(func() {
+					m.Update(workerNode2, func(obj utils.Object) utils.Object {
+						n, _ := obj.(*corev1.Node)
+						n.SetAnnotations(map[string]string{navarchosv1alpha1.ExcludeAnnotation: "true"})
+						return n
+					}, timeout).Should(Succeed())
+				})
+
+				It("reports the drifted node without creating a NodeRollout", func() {
+					Expect(result.DriftedNodes).To(HaveLen(1))
+					m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+				})
+			})
+
+			Context("and a NodeRollout created by the NodeFleetPolicy is still running", func() {
+				var running *navarchosv1alpha1.NodeRollout
+
+				BeforeEach(func() {
+					running = newNodeRollout(nodeFleetPolicy, []string{workerNode2.GetName()})
+					m.Create(running).Should(Succeed())
+				})
+
+				It("does not create another NodeRollout", func() {
+					m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", HaveLen(1)))
+					Expect(result.ActiveRollout).To(Equal(running.GetName()))
+				})
+			})
+
+			Context("and the drifted node was launched since the previous NodeRollout started", func() {
+				var previous *navarchosv1alpha1.NodeRollout
+
+				BeforeEach(func() {
+					previous = newNodeRollout(nodeFleetPolicy, []string{workerNode2.GetName()})
+					m.Create(previous).Should(Succeed())
+					m.UpdateStatus(previous, func(obj utils.Object) utils.Object {
+						r, _ := obj.(*navarchosv1alpha1.NodeRollout)
+						now := metav1.Now()
+						r.Status.CompletionTimestamp = &now
+						return r
+					}, timeout).Should(Succeed())
+					nodeFleetPolicy.Status.LastRollout = previous.GetName()
+					nodeFleetPolicy.Status.LastRolloutHash = policyHash(nodeFleetPolicy.Spec)
+					nodeFleetPolicy.Status.LastRolloutTime = previous.CreationTimestamp.DeepCopy()
+
+					// Replace the node with one still running the old version
+					m.Delete(workerNode2).Should(Succeed())
+					m.Get(workerNode2, timeout).ShouldNot(Succeed())
+					workerNode2 = utils.ExampleNodeWorker2.DeepCopy()
+					m.Create(workerNode2).Should(Succeed())
+					setKubeletVersion(workerNode2, "v1.13.5")
+				})
+
+				It("reports the node without creating another NodeRollout", func() {
+					Expect(handleErr).ToNot(HaveOccurred())
+					m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", HaveLen(1)))
+					Expect(result.DriftedReplacements).To(ConsistOf(workerNode2.GetName()))
+					Expect(result.RolloutCreatedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("ReplacementsDrifted")))
+					Expect(result.RolloutCreatedError).To(HaveOccurred())
+				})
+
+				Context("and the previous NodeRollout has been deleted", func() {
+					BeforeEach(func() {
+						m.Delete(previous).Should(Succeed())
+						m.Get(previous, timeout).ShouldNot(Succeed())
+					})
+
+					It("still does not create another NodeRollout", func() {
+						Expect(handleErr).ToNot(HaveOccurred())
+						m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+						Expect(result.DriftedReplacements).To(ConsistOf(workerNode2.GetName()))
+						Expect(result.RolloutCreatedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("ReplacementsDrifted")))
+					})
+				})
+
+				Context("and the NodeFleetPolicy has changed since", func() {
+					BeforeEach(func() {
+						nodeFleetPolicy.Spec.KubeletVersion = "v1.14.4"
+					})
+
+					It("creates a NodeRollout for the nodes", func() {
+						m.Eventually(&navarchosv1alpha1.NodeRolloutList{}, timeout).Should(utils.WithField("Items", HaveLen(2)))
+						Expect(result.DriftedReplacements).To(BeEmpty())
+						Expect(result.RolloutCreatedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("RolloutCreated")))
+					})
+				})
+			})
+
+			Context("and the NodeFleetPolicy is suspended", func() {
+				BeforeEach(func() {
+					nodeFleetPolicy.Spec.Suspend = true
+				})
+
+				It("does not create a NodeRollout", func() {
+					m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+					Expect(result.RolloutCreatedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("Suspended")))
+				})
+			})
+		})
+
+		Context("when no selected node has drifted", func() {
+			BeforeEach(func() {
+				setKubeletVersion(workerNode2, "v1.14.3")
+			})
+
+			It("does not create a NodeRollout", func() {
+				m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+			})
+
+			It("sets the DriftDetectedReason to NoDrift", func() {
+				Expect(result.DriftedNodes).To(BeEmpty())
+				Expect(result.DriftDetectedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("NoDrift")))
+			})
+
+			Context("and the NodeFleetPolicy limits the node age", func() {
+				BeforeEach(func() {
+					nodeFleetPolicy.Spec.MaxNodeAge = &metav1.Duration{Duration: time.Hour}
+				})
+
+				It("requeues the NodeFleetPolicy for when the first node becomes too old", func() {
+					Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+				})
+			})
+		})
+
+		Context("when the node selector is invalid", func() {
+			BeforeEach(func() {
+				nodeFleetPolicy.Spec.NodeSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+					{
+						Key:      "node-role.kubernetes.io/worker",
+						Operator: "Invalid",
+					},
+				}
+			})
+
+			It("sets the DriftDetectedReason to InvalidNodeSelector", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+				Expect(result.DriftDetectedReason).To(Equal(navarchosv1alpha1.NodeFleetPolicyConditionReason("InvalidNodeSelector")))
+				Expect(result.DriftDetectedError).To(HaveOccurred())
+			})
+		})
+	})
+})
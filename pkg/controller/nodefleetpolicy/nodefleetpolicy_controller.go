@@ -0,0 +1,153 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodefleetpolicy
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodefleetpolicy/handler"
+	"github.com/pusher/navarchos/pkg/controller/nodefleetpolicy/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	watchhandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Add creates a new NodeFleetPolicy Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	h := handler.NewNodeFleetPolicyHandler(mgr.GetClient())
+	return &ReconcileNodeFleetPolicy{Client: mgr.GetClient(), handler: h, scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("nodefleetpolicy-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to NodeFleetPolicy
+	err = c.Watch(&source.Kind{Type: &navarchosv1alpha1.NodeFleetPolicy{}}, &watchhandler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for NodeRollouts created by NodeFleetPolicy
+	err = c.Watch(&source.Kind{Type: &navarchosv1alpha1.NodeRollout{}}, &watchhandler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &navarchosv1alpha1.NodeFleetPolicy{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Nodes so that drifted nodes are detected as soon as
+	// they join the cluster or change
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &watchhandler.EnqueueRequestsFromMapFunc{
+		ToRequests: watchhandler.ToRequestsFunc(func(watchhandler.MapObject) []reconcile.Request {
+			return policyRequests(mgr.GetClient())
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// policyRequests returns a reconcile.Request for every NodeFleetPolicy
+func policyRequests(c client.Client) []reconcile.Request {
+	policies := &navarchosv1alpha1.NodeFleetPolicyList{}
+	err := c.List(context.TODO(), policies)
+	if err != nil {
+		log.Printf("error listing NodeFleetPolicies: %v", err)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, policy := range policies.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: policy.GetName(),
+			},
+		})
+	}
+	return requests
+}
+
+var _ reconcile.Reconciler = &ReconcileNodeFleetPolicy{}
+
+// ReconcileNodeFleetPolicy reconciles a NodeFleetPolicy object
+type ReconcileNodeFleetPolicy struct {
+	client.Client
+	handler *handler.NodeFleetPolicyHandler
+	scheme  *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a NodeFleetPolicy object and makes changes based on the state read
+// and what is in the NodeFleetPolicy.Spec
+// +kubebuilder:rbac:groups=navarchos.pusher.com,resources=nodefleetpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=navarchos.pusher.com,resources=nodefleetpolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=navarchos.pusher.com,resources=noderollouts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+func (r *ReconcileNodeFleetPolicy) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the NodeFleetPolicy instance
+	instance := &navarchosv1alpha1.NodeFleetPolicy{}
+	err := r.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	result, err := r.handler.Handle(instance)
+	if err != nil {
+		// Ensure we attempt to update the status even when the handler fails
+		statusErr := status.UpdateStatus(r.Client, instance, result)
+		if statusErr != nil {
+			log.Printf("error updating status: %v", statusErr)
+		}
+
+		return reconcile.Result{}, fmt.Errorf("error handling fleet policy %s: %+v", instance.GetName(), err)
+	}
+	err = status.UpdateStatus(r.Client, instance, result)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("error updating status: %v", err)
+	}
+
+	return reconcile.Result{RequeueAfter: result.RequeueAfter}, nil
+}
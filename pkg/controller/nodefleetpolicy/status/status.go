@@ -0,0 +1,171 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UpdateStatus merges the status in the existing instance with the information
+// provided in the Result and then updates the instance if there is any
+// difference between the new and updated status
+func UpdateStatus(c client.Client, instance *navarchosv1alpha1.NodeFleetPolicy, result *Result) error {
+	status := instance.Status
+
+	status.NodesCount = result.NodesCount
+	setDriftedNodes(&status, result)
+	status.ActiveRollout = result.ActiveRollout
+	setLastRollout(&status, result)
+
+	err := setDriftDetectedCondition(&status, result)
+	if err != nil {
+		return err
+	}
+	err = setRolloutCreatedCondition(&status, result)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(status, instance.Status) {
+		copy := instance.DeepCopy()
+		copy.Status = status
+
+		err := c.Update(context.TODO(), copy)
+		if err != nil {
+			return fmt.Errorf("error updating status: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// setDriftedNodes sets the DriftedNodes, their count and the
+// DriftedReplacements
+func setDriftedNodes(status *navarchosv1alpha1.NodeFleetPolicyStatus, result *Result) {
+	status.DriftedNodes = result.DriftedNodes
+	status.DriftedNodesCount = len(result.DriftedNodes)
+	status.DriftedReplacements = result.DriftedReplacements
+}
+
+// setLastRollout records the NodeRollout created by the policy, if any. The
+// record is never cleared, so that it outlives the NodeRollout itself
+func setLastRollout(status *navarchosv1alpha1.NodeFleetPolicyStatus, result *Result) {
+	if result.LastRollout == "" {
+		return
+	}
+	status.LastRollout = result.LastRollout
+	status.LastRolloutHash = result.LastRolloutHash
+	status.LastRolloutTime = result.LastRolloutTime
+}
+
+// setDriftDetectedCondition sets the DriftDetected condition to True when the
+// DriftDetectedReason is NodesDrifted, listing the drifted nodes in the
+// message, and to False when it is NoDrift. Any other reason sets the
+// condition to Unknown, using the DriftDetectedError as the message
+func setDriftDetectedCondition(status *navarchosv1alpha1.NodeFleetPolicyStatus, result *Result) error {
+	if result.DriftDetectedError != nil && result.DriftDetectedReason == "" {
+		return fmt.Errorf("if DriftDetectedError is set, DriftDetectedReason must also be set")
+	}
+
+	switch result.DriftDetectedReason {
+	case "":
+		return nil
+	case "NodesDrifted":
+		names := []string{}
+		for _, node := range status.DriftedNodes {
+			names = append(names, node.Name)
+		}
+		message := fmt.Sprintf("Node(s) drifted from the policy: %s", strings.Join(names, ", "))
+		setNodeFleetPolicyCondition(status, newNodeFleetPolicyCondition(navarchosv1alpha1.DriftDetectedType, corev1.ConditionTrue, result.DriftDetectedReason, message))
+	case "NoDrift":
+		setNodeFleetPolicyCondition(status, newNodeFleetPolicyCondition(navarchosv1alpha1.DriftDetectedType, corev1.ConditionFalse, result.DriftDetectedReason, ""))
+	default:
+		condition := newNodeFleetPolicyCondition(navarchosv1alpha1.DriftDetectedType, corev1.ConditionUnknown, result.DriftDetectedReason, "")
+		if result.DriftDetectedError != nil {
+			condition.Message = result.DriftDetectedError.Error()
+		}
+		setNodeFleetPolicyCondition(status, condition)
+	}
+	return nil
+}
+
+// setRolloutCreatedCondition sets the RolloutCreated condition to True when
+// the RolloutCreatedReason is RolloutCreated and to False for any other
+// reason, using the RolloutCreatedError as the message
+func setRolloutCreatedCondition(status *navarchosv1alpha1.NodeFleetPolicyStatus, result *Result) error {
+	if result.RolloutCreatedError != nil && result.RolloutCreatedReason == "" {
+		return fmt.Errorf("if RolloutCreatedError is set, RolloutCreatedReason must also be set")
+	}
+	if result.RolloutCreatedReason == "" {
+		return nil
+	}
+
+	condition := newNodeFleetPolicyCondition(navarchosv1alpha1.RolloutCreatedType, corev1.ConditionTrue, result.RolloutCreatedReason, "")
+	if result.RolloutCreatedReason != "RolloutCreated" {
+		condition.Status = corev1.ConditionFalse
+	}
+	if result.RolloutCreatedError != nil {
+		condition.Message = result.RolloutCreatedError.Error()
+	}
+	setNodeFleetPolicyCondition(status, condition)
+	return nil
+}
+
+// newNodeFleetPolicyCondition creates a new NodeFleetPolicyCondition
+func newNodeFleetPolicyCondition(condType navarchosv1alpha1.NodeFleetPolicyConditionType, status corev1.ConditionStatus, reason navarchosv1alpha1.NodeFleetPolicyConditionReason, message string) navarchosv1alpha1.NodeFleetPolicyCondition {
+	return navarchosv1alpha1.NodeFleetPolicyCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// getNodeFleetPolicyCondition returns the condition with the provided type
+func getNodeFleetPolicyCondition(status navarchosv1alpha1.NodeFleetPolicyStatus, condType navarchosv1alpha1.NodeFleetPolicyConditionType) *navarchosv1alpha1.NodeFleetPolicyCondition {
+	for i := range status.Conditions {
+		c := status.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// setNodeFleetPolicyCondition updates the NodeFleetPolicy to include the
+// provided condition. If the condition that we are about to add already exists
+// and has the same status, reason and message then we are not going to update
+func setNodeFleetPolicyCondition(status *navarchosv1alpha1.NodeFleetPolicyStatus, condition navarchosv1alpha1.NodeFleetPolicyCondition) {
+	currentCond := getNodeFleetPolicyCondition(*status, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	newConditions := filterOutCondition(status.Conditions, condition.Type)
+	status.Conditions = append(newConditions, condition)
+}
+
+// filterOutCondition returns a new slice of NodeFleetPolicy conditions without
+// conditions with the provided types
+func filterOutCondition(conditions []navarchosv1alpha1.NodeFleetPolicyCondition, condType navarchosv1alpha1.NodeFleetPolicyConditionType) []navarchosv1alpha1.NodeFleetPolicyCondition {
+	var newConditions []navarchosv1alpha1.NodeFleetPolicyCondition
+	for _, c := range conditions {
+		if c.Type == condType {
+			continue
+		}
+		newConditions = append(newConditions, c)
+	}
+	return newConditions
+}
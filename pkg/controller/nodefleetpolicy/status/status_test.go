@@ -0,0 +1,224 @@
+package status
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("NodeFleetPolicy Status Suite", func() {
+	var c client.Client
+	var m utils.Matcher
+
+	var nodeFleetPolicy *navarchosv1alpha1.NodeFleetPolicy
+	var result *Result
+
+	const timeout = time.Second * 5
+	const consistentlyTimeout = time.Second
+
+	BeforeEach(func() {
+		var err error
+		c, err = client.New(cfg, client.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		nodeFleetPolicy = utils.ExampleNodeFleetPolicy.DeepCopy()
+		m.Create(nodeFleetPolicy).Should(Succeed())
+
+		result = &Result{}
+	})
+
+	AfterEach(func() {
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeFleetPolicyList{},
+		)
+	})
+
+	Context("UpdateStatus", func() {
+		var updateErr error
+
+		JustBeforeEach(func() {
+			updateErr = UpdateStatus(c, nodeFleetPolicy, result)
+		})
+
+		Context("when nodes have drifted", func() {
+			var driftedNodes []navarchosv1alpha1.DriftedNode
+
+			BeforeEach(func() {
+				driftedNodes = []navarchosv1alpha1.DriftedNode{
+					{
+						Name:    "example-worker-1",
+						Reasons: []string{"kubelet version is v1.13.5, expected v1.14.3"},
+					},
+					{
+						Name:    "example-worker-2",
+						Reasons: []string{"label pool is missing"},
+					},
+				}
+				result.NodesCount = 3
+				result.DriftedNodes = driftedNodes
+				result.DriftedReplacements = []string{"example-worker-2"}
+				result.DriftDetectedReason = "NodesDrifted"
+			})
+
+			It("sets the NodesCount field", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(utils.WithField("Status.NodesCount", Equal(3)))
+			})
+
+			It("sets the DriftedNodes field", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(utils.WithField("Status.DriftedNodes", Equal(driftedNodes)))
+			})
+
+			It("sets the DriftedNodesCount field", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(utils.WithField("Status.DriftedNodesCount", Equal(2)))
+			})
+
+			It("sets the DriftedReplacements field", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(utils.WithField("Status.DriftedReplacements", ConsistOf("example-worker-2")))
+			})
+
+			It("sets the DriftDetected condition to True listing the drifted nodes", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.DriftDetectedType)),
+							utils.WithField("Status", Equal(corev1.ConditionTrue)),
+							utils.WithField("Message", Equal("Node(s) drifted from the policy: example-worker-1, example-worker-2")),
+						)),
+					),
+				)
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("when no nodes have drifted", func() {
+			BeforeEach(func() {
+				result.DriftDetectedReason = "NoDrift"
+			})
+
+			It("sets the DriftDetected condition to False", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.DriftDetectedType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when the nodes could not be compared against the policy", func() {
+			BeforeEach(func() {
+				result.DriftDetectedReason = "ErrorListingNodes"
+				result.DriftDetectedError = errors.New("failed to list nodes")
+			})
+
+			It("sets the DriftDetected condition to Unknown", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.DriftDetectedType)),
+							utils.WithField("Status", Equal(corev1.ConditionUnknown)),
+							utils.WithField("Message", Equal(result.DriftDetectedError.Error())),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when a NodeRollout was created", func() {
+			BeforeEach(func() {
+				now := metav1.Now()
+				result.ActiveRollout = "example-abcde"
+				result.LastRollout = "example-abcde"
+				result.LastRolloutHash = "12345678"
+				result.LastRolloutTime = &now
+				result.RolloutCreatedReason = "RolloutCreated"
+			})
+
+			It("sets the ActiveRollout field", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(utils.WithField("Status.ActiveRollout", Equal("example-abcde")))
+			})
+
+			It("records the NodeRollout as the last one", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(SatisfyAll(
+					utils.WithField("Status.LastRollout", Equal("example-abcde")),
+					utils.WithField("Status.LastRolloutHash", Equal("12345678")),
+					utils.WithField("Status.LastRolloutTime", Not(BeNil())),
+				))
+			})
+
+			It("sets the RolloutCreated condition to True", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.RolloutCreatedType)),
+							utils.WithField("Status", Equal(corev1.ConditionTrue)),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when the last NodeRollout is recorded and no NodeRollout was created", func() {
+			BeforeEach(func() {
+				now := metav1.Now()
+				m.Update(nodeFleetPolicy, func(obj utils.Object) utils.Object {
+					p, _ := obj.(*navarchosv1alpha1.NodeFleetPolicy)
+					p.Status.LastRollout = "example-abcde"
+					p.Status.LastRolloutHash = "12345678"
+					p.Status.LastRolloutTime = &now
+					return p
+				}, timeout).Should(Succeed())
+				result.DriftDetectedReason = "NoDrift"
+			})
+
+			It("keeps the record of the last NodeRollout", func() {
+				m.Consistently(nodeFleetPolicy, consistentlyTimeout).Should(SatisfyAll(
+					utils.WithField("Status.LastRollout", Equal("example-abcde")),
+					utils.WithField("Status.LastRolloutHash", Equal("12345678")),
+				))
+			})
+		})
+
+		Context("when the RolloutCreatedError is set in the Result", func() {
+			BeforeEach(func() {
+				result.RolloutCreatedReason = "Suspended"
+				result.RolloutCreatedError = errors.New("policy is suspended")
+			})
+
+			It("sets the RolloutCreated condition to False", func() {
+				m.Eventually(nodeFleetPolicy, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.RolloutCreatedType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Message", Equal(result.RolloutCreatedError.Error())),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("if only RolloutCreatedError is set", func() {
+			BeforeEach(func() {
+				result.RolloutCreatedError = errors.New("error")
+			})
+
+			It("causes an error", func() {
+				Expect(updateErr).To(MatchError("if RolloutCreatedError is set, RolloutCreatedReason must also be set"))
+			})
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package status
+
+import (
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Result is used as the basis to updating the status of the NodeFleetPolicy.
+// It contains information gathered during a single run of the reconcile loop.
+type Result struct {
+	// NodesCount is the number of nodes selected by the policy.
+	NodesCount int
+
+	// This should list the nodes that do not match the policy.
+	// This list replaces the existing status list.
+	DriftedNodes []navarchosv1alpha1.DriftedNode
+
+	// This should list the drifted nodes created since the previous NodeRollout
+	// of the policy started, which are not replaced again.
+	// This list replaces the existing status list.
+	DriftedReplacements []string
+
+	// ActiveRollout is the name of the NodeRollout created by the policy that
+	// has not yet finished. If empty, it is cleared.
+	ActiveRollout string
+
+	// LastRollout is the name of the NodeRollout created by the policy during
+	// this run, along with the hash of the policy and the time it was created.
+	// If empty, the existing record of the last NodeRollout is kept.
+	LastRollout     string
+	LastRolloutHash string
+	LastRolloutTime *metav1.Time
+
+	// This is the short reason description for the DriftDetected condition.
+	// NodesDrifted sets the condition to True, listing the drifted nodes in the
+	// message. NoDrift sets it to False and any other reason sets it to
+	// Unknown.
+	DriftDetectedReason navarchosv1alpha1.NodeFleetPolicyConditionReason
+
+	// This should contain any errors related to comparing the nodes against
+	// the policy.
+	DriftDetectedError error
+
+	// This is the short reason description for the RolloutCreated condition.
+	// RolloutCreated sets the condition to True, any other reason sets it to
+	// False.
+	RolloutCreatedReason navarchosv1alpha1.NodeFleetPolicyConditionReason
+
+	// This should contain any errors related to creating the NodeRollout.
+	RolloutCreatedError error
+
+	// This allows the Handler to requeue the NodeFleetPolicy after a delay, so
+	// that nodes are replaced as soon as they reach the maximum node age.
+	RequeueAfter time.Duration
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	policyv1beta1client "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
+	"k8s.io/client-go/rest"
+)
+
+// abortPollPeriod is how often the NodeRollout is checked for an abort while a
+// node is being drained
+const abortPollPeriod = 5 * time.Second
+
+// handleAborted handles a NodeReplacement whose NodeRollout has been aborted
+// before the node was drained. If the node was cordoned by the controller it
+// is made schedulable again, this includes NodeReplacements that failed to
+// drain the node. Nodes that were already cordoned before the controller
+// touched them are left cordoned. The NodeReplacement is then moved to the
+// aborted phase
+func (h *NodeReplacementHandler) handleAborted(instance *navarchosv1alpha1.NodeReplacement) (*status.Result, error) {
+	abortedPhase := navarchosv1alpha1.ReplacementPhaseAborted
+
+	// Only replacements in progress, or that failed to drain, have cordoned
+	// their node
+	if instance.Status.Phase != navarchosv1alpha1.ReplacementPhaseInProgress && instance.Status.Phase != navarchosv1alpha1.ReplacementPhaseFailed {
+		return &status.Result{
+			Phase: &abortedPhase,
+		}, nil
+	}
+
+	// Replacements that were denied approval, or whose pre-cordon hook
+	// failed, never cordoned their node. Nodes cordoned by someone else are
+	// not ours to uncordon
+	if !instance.Status.CordonedByController {
+		return &status.Result{
+			Phase: &abortedPhase,
+		}, nil
+	}
+
+	node, exists, err := h.getNode(instance)
+	if err != nil {
+		return &status.Result{}, fmt.Errorf("error getting node: %v", err)
+	}
+	if !exists {
+		return &status.Result{
+			Phase: &abortedPhase,
+		}, nil
+	}
+
+	err = h.uncordonNode(node)
+	if err != nil {
+		return &status.Result{
+			NodeRestoredError:  err,
+			NodeRestoredReason: navarchosv1alpha1.ReasonErrorRestoringNode,
+		}, fmt.Errorf("error uncordoning node: %v", err)
+	}
+
+	return &status.Result{
+		Phase:              &abortedPhase,
+		NodeRestoredReason: navarchosv1alpha1.ReasonNodeRestored,
+	}, nil
+}
+
+// isRolloutAborted returns true if the NodeRollout controlling the
+// NodeReplacement has been aborted
+func isRolloutAborted(rollout *navarchosv1alpha1.NodeRollout) bool {
+	return rollout != nil && rollout.Spec.Abort
+}
+
+// abortContext returns a context that is cancelled once the NodeRollout
+// controlling the NodeReplacement is aborted. The cancel function must be
+// called once the context is no longer needed
+func (h *NodeReplacementHandler) abortContext(instance *navarchosv1alpha1.NodeReplacement) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(abortPollPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rollout, err := h.getRollout(instance)
+				if err != nil {
+					log.Printf("error checking whether NodeReplacement %s was aborted: %v", instance.GetName(), err)
+					continue
+				}
+				if isRolloutAborted(rollout) {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+// abortableClient wraps the handler's client for draining a node. The requests
+// the drain package makes for each pod, evicting or deleting it and checking
+// whether it is gone, are sent with the context so that they fail once it is
+// cancelled. This cancels evictions that are still being retried, and stops
+// waiting for evicted pods to be deleted, while sharing the connections of the
+// handler's client
+type abortableClient struct {
+	kubernetes.Interface
+	ctx context.Context
+}
+
+// CoreV1 returns a client for pods that sends requests with the context
+func (c *abortableClient) CoreV1() corev1client.CoreV1Interface {
+	return &abortableCoreV1{CoreV1Interface: c.Interface.CoreV1(), ctx: c.ctx}
+}
+
+// PolicyV1beta1 returns a client for evictions that sends requests with the
+// context
+func (c *abortableClient) PolicyV1beta1() policyv1beta1client.PolicyV1beta1Interface {
+	return &abortablePolicyV1beta1{PolicyV1beta1Interface: c.Interface.PolicyV1beta1(), ctx: c.ctx}
+}
+
+type abortableCoreV1 struct {
+	corev1client.CoreV1Interface
+	ctx context.Context
+}
+
+// Pods returns a client for the pods in the namespace
+func (c *abortableCoreV1) Pods(namespace string) corev1client.PodInterface {
+	return &abortablePods{
+		PodInterface: c.CoreV1Interface.Pods(namespace),
+		client:       c.RESTClient(),
+		namespace:    namespace,
+		ctx:          c.ctx,
+	}
+}
+
+// abortablePods gets and deletes pods with the context, any other request is
+// sent without it
+type abortablePods struct {
+	corev1client.PodInterface
+	client    rest.Interface
+	namespace string
+	ctx       context.Context
+}
+
+// Get gets the pod with the context
+func (c *abortablePods) Get(name string, options metav1.GetOptions) (*corev1.Pod, error) {
+	result := &corev1.Pod{}
+	err := c.client.Get().
+		Context(c.ctx).
+		Namespace(c.namespace).
+		Resource("pods").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return result, err
+}
+
+// Delete deletes the pod with the context
+func (c *abortablePods) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Context(c.ctx).
+		Namespace(c.namespace).
+		Resource("pods").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+type abortablePolicyV1beta1 struct {
+	policyv1beta1client.PolicyV1beta1Interface
+	ctx context.Context
+}
+
+// Evictions returns a client for evicting the pods in the namespace
+func (c *abortablePolicyV1beta1) Evictions(namespace string) policyv1beta1client.EvictionInterface {
+	return &abortableEvictions{client: c.RESTClient(), ctx: c.ctx}
+}
+
+// abortableEvictions evicts pods with the context
+type abortableEvictions struct {
+	client rest.Interface
+	ctx    context.Context
+}
+
+// Evict evicts the pod with the context
+func (c *abortableEvictions) Evict(eviction *policyv1beta1.Eviction) error {
+	return c.client.Post().
+		Context(c.ctx).
+		AbsPath("/api/v1").
+		Namespace(eviction.Namespace).
+		Resource("pods").
+		Name(eviction.Name).
+		SubResource("eviction").
+		Body(eviction).
+		Do().
+		Error()
+}
+
+// uncordonNode reverts cordonNode. It marks the node as schedulable and
+// removes the unschedulable taint
+func (h *NodeReplacementHandler) uncordonNode(node *corev1.Node) error {
+	newNode, removed := removeTaint(node, &corev1.Taint{
+		Key:    "node.kubernetes.io/unschedulable",
+		Effect: corev1.TaintEffect("NoSchedule"),
+	})
+	if !removed && !node.Spec.Unschedulable {
+		return nil
+	}
+	newNode.Spec.Unschedulable = false
+
+	err := h.client.Update(context.Background(), newNode)
+	if err != nil {
+		return fmt.Errorf("error updating the node: %v", err)
+	}
+
+	return nil
+}
+
+// removeTaint removes any taint matching the key:effect of the given taint. It
+// returns a new copy of the updated Node and true if a taint was removed, false
+// otherwise
+func removeTaint(node *corev1.Node, taint *corev1.Taint) (*corev1.Node, bool) {
+	newNode := node.DeepCopy()
+
+	var newTaints []corev1.Taint
+	removed := false
+	for i := range newNode.Spec.Taints {
+		if taint.MatchTaint(&newNode.Spec.Taints[i]) {
+			removed = true
+			continue
+		}
+		newTaints = append(newTaints, newNode.Spec.Taints[i])
+	}
+	newNode.Spec.Taints = newTaints
+
+	return newNode, removed
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("aborted replacement handler", func() {
+	var m utils.Matcher
+	var h *NodeReplacementHandler
+	var opts *Options
+
+	var nodeReplacement *navarchosv1alpha1.NodeReplacement
+	var rollout *navarchosv1alpha1.NodeRollout
+	var mgrStopped *sync.WaitGroup
+	var stopMgr chan struct{}
+
+	var workerNode1 *corev1.Node
+
+	var result *status.Result
+	var handleErr error
+
+	const timeout = time.Second * 5
+
+	var unschedulableTaint = corev1.Taint{
+		Key:    "node.kubernetes.io/unschedulable",
+		Effect: corev1.TaintEffectNoSchedule,
+	}
+
+	var otherTaint = corev1.Taint{
+		Key:    "example.com/other",
+		Effect: corev1.TaintEffectNoSchedule,
+	}
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+
+		opts = &Options{}
+
+		rollout = utils.ExampleNodeRollout.DeepCopy()
+		rollout.Spec.Abort = true
+		m.Create(rollout).Should(Succeed())
+
+		// The node has been cordoned by the controller
+		workerNode1 = utils.ExampleNodeWorker1.DeepCopy()
+		workerNode1.Spec.Unschedulable = true
+		workerNode1.Spec.Taints = []corev1.Taint{otherTaint, unschedulableTaint}
+		m.Create(workerNode1).Should(Succeed())
+
+		nodeReplacement = utils.ExampleNodeReplacement.DeepCopy()
+		nodeReplacement.SetOwnerReferences([]metav1.OwnerReference{
+			utils.GetOwnerReferenceForNodeRollout(rollout),
+			utils.GetOwnerReferenceForNode(workerNode1),
+		})
+		nodeReplacement.Spec.NodeUID = workerNode1.GetUID()
+		nodeReplacement.Spec.NodeName = workerNode1.GetName()
+		nodeReplacement.Status.Phase = navarchosv1alpha1.ReplacementPhaseInProgress
+		nodeReplacement.Status.CordonedByController = true
+		m.Create(nodeReplacement).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeRolloutList{},
+			&navarchosv1alpha1.NodeReplacementList{},
+			&corev1.NodeList{},
+		)
+	})
+
+	JustBeforeEach(func() {
+		h = NewNodeReplacementHandler(m.Client, opts)
+		result, handleErr = h.Handle(nodeReplacement)
+	})
+
+	Context("when the NodeReplacement is in progress", func() {
+		It("marks the node schedulable", func() {
+			m.Eventually(workerNode1, timeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+		})
+
+		It("only removes the unschedulable taint", func() {
+			m.Eventually(workerNode1, timeout).Should(utils.WithField("Spec.Taints", ConsistOf(otherTaint)))
+		})
+
+		It("sets the phase to aborted", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseAborted
+			Expect(result.Phase).To(Equal(&phase))
+		})
+
+		It("sets the NodeRestoredReason to NodeRestored", func() {
+			Expect(result.NodeRestoredReason).To(Equal(navarchosv1alpha1.ReasonNodeRestored))
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when the NodeReplacement has failed to drain the node", func() {
+		BeforeEach(func() {
+			m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+				nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+				cordonTime := metav1.Now()
+				nr.Status.Phase = navarchosv1alpha1.ReplacementPhaseFailed
+				nr.Status.CordonTimestamp = &cordonTime
+				return nr
+			}, timeout).Should(Succeed())
+		})
+
+		It("marks the node schedulable", func() {
+			m.Eventually(workerNode1, timeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+		})
+
+		It("sets the phase to aborted", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseAborted
+			Expect(result.Phase).To(Equal(&phase))
+		})
+	})
+
+	Context("when the node was cordoned before the controller touched it", func() {
+		BeforeEach(func() {
+			m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+				nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+				nr.Status.CordonedByController = false
+				return nr
+			}, timeout).Should(Succeed())
+		})
+
+		It("does not modify the node", func() {
+			m.Consistently(workerNode1, time.Second).Should(utils.WithField("Spec.Unschedulable", BeTrue()))
+		})
+
+		It("sets the phase to aborted", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseAborted
+			Expect(result.Phase).To(Equal(&phase))
+		})
+
+		It("does not set the NodeRestoredReason", func() {
+			Expect(result.NodeRestoredReason).To(BeEmpty())
+		})
+	})
+
+	Context("when the NodeReplacement failed before cordoning the node", func() {
+		BeforeEach(func() {
+			m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+				nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+				nr.Status.Phase = navarchosv1alpha1.ReplacementPhaseFailed
+				nr.Status.CordonedByController = false
+				return nr
+			}, timeout).Should(Succeed())
+		})
+
+		It("does not modify the node", func() {
+			m.Consistently(workerNode1, time.Second).Should(utils.WithField("Spec.Unschedulable", BeTrue()))
+		})
+
+		It("sets the phase to aborted", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseAborted
+			Expect(result.Phase).To(Equal(&phase))
+		})
+	})
+
+	Context("when the NodeReplacement has not started", func() {
+		BeforeEach(func() {
+			m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+				nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+				nr.Status.Phase = navarchosv1alpha1.ReplacementPhaseNew
+				return nr
+			}, timeout).Should(Succeed())
+		})
+
+		It("does not modify the node", func() {
+			m.Consistently(workerNode1, time.Second).Should(utils.WithField("Spec.Unschedulable", BeTrue()))
+		})
+
+		It("sets the phase to aborted", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseAborted
+			Expect(result.Phase).To(Equal(&phase))
+		})
+
+		It("does not set the NodeRestoredReason", func() {
+			Expect(result.NodeRestoredReason).To(BeEmpty())
+		})
+	})
+
+	Context("when the NodeReplacement has already drained the node", func() {
+		BeforeEach(func() {
+			m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+				nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+				nr.Status.Phase = navarchosv1alpha1.ReplacementPhaseTerminating
+				return nr
+			}, timeout).Should(Succeed())
+		})
+
+		It("does not modify the node", func() {
+			m.Consistently(workerNode1, time.Second).Should(utils.WithField("Spec.Unschedulable", BeTrue()))
+		})
+
+		It("continues the NodeReplacement", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseCompleted
+			Expect(result.Phase).To(Equal(&phase))
+			Expect(result.NodeRestoredReason).To(BeEmpty())
+		})
+	})
+
+	Context("abortContext", func() {
+		It("is cancelled once the NodeRollout is aborted", func() {
+			ctx, cancel := h.abortContext(nodeReplacement)
+			defer cancel()
+			Eventually(ctx.Done(), 2*abortPollPeriod).Should(BeClosed())
+		})
+	})
+
+	Context("abortableClient", func() {
+		var ctx context.Context
+		var cancel context.CancelFunc
+		var drainClient *abortableClient
+
+		BeforeEach(func() {
+			opts.Config = cfg
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		JustBeforeEach(func() {
+			drainClient = &abortableClient{Interface: h.k8sClient, ctx: ctx}
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("sends requests while the context is open", func() {
+			_, err := drainClient.CoreV1().Pods("default").Get("pod-1", metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		Context("when the context is cancelled", func() {
+			JustBeforeEach(func() {
+				cancel()
+			})
+
+			It("fails evictions", func() {
+				err := drainClient.PolicyV1beta1().Evictions("default").Evict(&policyv1beta1.Eviction{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"},
+				})
+				Expect(err).To(MatchError(ContainSubstring("context canceled")))
+			})
+
+			It("fails checking whether pods are gone", func() {
+				_, err := drainClient.CoreV1().Pods("default").Get("pod-1", metav1.GetOptions{})
+				Expect(err).To(MatchError(ContainSubstring("context canceled")))
+			})
+		})
+	})
+})
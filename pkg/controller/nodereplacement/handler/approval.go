@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	approvalPending = "pending"
+	approvalApprove = "approve"
+	approvalDeny    = "deny"
+
+	// approvalRequeuePeriod determines how often a NodeReplacement waiting for
+	// approval asks the approval webhook again
+	approvalRequeuePeriod = 30 * time.Second
+)
+
+// approvalRequest is the payload POSTed to the approval webhook
+type approvalRequest struct {
+	NodeReplacement *navarchosv1alpha1.NodeReplacement `json:"nodeReplacement"`
+	Node            *corev1.Node                       `json:"node"`
+}
+
+// approvalResponse is the payload expected from the approval webhook
+type approvalResponse struct {
+	// Decision is one of pending, approve or deny
+	Decision string `json:"decision"`
+
+	// Reason optionally describes the decision
+	Reason string `json:"reason,omitempty"`
+}
+
+// approvalURL returns the URL of the approval webhook for the NodeReplacement.
+// The URL set on the NodeReplacement takes precedence over the controller's
+func (h *NodeReplacementHandler) approvalURL(instance *navarchosv1alpha1.NodeReplacement) string {
+	if instance.Spec.ReplacementSpec.Approval != nil {
+		return instance.Spec.ReplacementSpec.Approval.URL
+	}
+	return h.approvalWebhookURL
+}
+
+// requestApproval asks the approval webhook whether the node may be disrupted
+// and returns the reason for the Approved condition. While the decision is
+// pending, or if it was denied, the returned error describes the decision. If
+// there is no approval webhook, or the NodeReplacement has already been
+// approved, the reason and error are empty
+func (h *NodeReplacementHandler) requestApproval(instance *navarchosv1alpha1.NodeReplacement, node *corev1.Node) (navarchosv1alpha1.NodeReplacementConditionReason, error) {
+	url := h.approvalURL(instance)
+	if url == "" || isConditionTrue(instance, navarchosv1alpha1.ApprovedType) {
+		return "", nil
+	}
+
+	response, err := h.postApprovalRequest(url, &approvalRequest{
+		NodeReplacement: instance,
+		Node:            node,
+	})
+	if err != nil {
+		return navarchosv1alpha1.ReasonErrorRequestingApproval, fmt.Errorf("error requesting approval from %s: %v", url, err)
+	}
+
+	switch response.Decision {
+	case approvalApprove:
+		return navarchosv1alpha1.ReasonApproved, nil
+	case approvalPending:
+		return navarchosv1alpha1.ReasonApprovalPending, fmt.Errorf("waiting for approval from %s%s", url, describeReason(response.Reason))
+	case approvalDeny:
+		return navarchosv1alpha1.ReasonApprovalDenied, fmt.Errorf("disruption of node %s denied by %s%s", node.GetName(), url, describeReason(response.Reason))
+	default:
+		return navarchosv1alpha1.ReasonErrorRequestingApproval, fmt.Errorf("invalid decision %q from %s, must be one of %s, %s or %s", response.Decision, url, approvalPending, approvalApprove, approvalDeny)
+	}
+}
+
+// postApprovalRequest POSTs the request to the approval webhook and decodes
+// its response
+func (h *NodeReplacementHandler) postApprovalRequest(url string, request *approvalRequest) (*approvalResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %v", err)
+	}
+
+	resp, err := h.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	response := &approvalResponse{}
+	err = json.NewDecoder(resp.Body).Decode(response)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %v", err)
+	}
+	return response, nil
+}
+
+// stopForApproval updates the result for a NodeReplacement that has not been
+// approved and returns true if it cannot continue. While the decision is
+// pending the NodeReplacement is requeued, if it was denied the
+// NodeReplacement is failed. An error is returned if the approval webhook
+// could not be reached
+func stopForApproval(result *status.Result, reason navarchosv1alpha1.NodeReplacementConditionReason, approvalErr error) (bool, error) {
+	switch reason {
+	case navarchosv1alpha1.ReasonApprovalPending:
+		result.Requeue = true
+		result.RequeueAfter = approvalRequeuePeriod
+		result.RequeueReason = approvalErr.Error()
+		result.RequeueCause = string(reason)
+		return true, nil
+	case navarchosv1alpha1.ReasonApprovalDenied:
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		result.Phase = &failedPhase
+		return true, nil
+	case navarchosv1alpha1.ReasonErrorRequestingApproval:
+		return true, approvalErr
+	default:
+		return false, nil
+	}
+}
+
+// describeReason formats the optional reason given by the approval webhook
+func describeReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return ": " + reason
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("replacement approval", func() {
+	var m utils.Matcher
+	var h *NodeReplacementHandler
+	var opts *Options
+
+	var nodeReplacement *navarchosv1alpha1.NodeReplacement
+	var mgrStopped *sync.WaitGroup
+	var stopMgr chan struct{}
+
+	var workerNode1 *corev1.Node
+
+	// server stands in for the approval webhook, responding with statusCode
+	// and response while recording the last request it received
+	var server *httptest.Server
+	var statusCode int
+	var response string
+	var received *approvalRequest
+
+	const timeout = time.Second * 5
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+
+		statusCode = http.StatusOK
+		response = `{"decision": "approve"}`
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			received = &approvalRequest{}
+			Expect(json.NewDecoder(r.Body).Decode(received)).To(Succeed())
+			w.WriteHeader(statusCode)
+			w.Write([]byte(response))
+		}))
+
+		opts = &Options{}
+
+		workerNode1 = utils.ExampleNodeWorker1.DeepCopy()
+		m.Create(workerNode1).Should(Succeed())
+
+		nodeReplacement = utils.ExampleNodeReplacement.DeepCopy()
+		nodeReplacement.SetOwnerReferences([]metav1.OwnerReference{utils.GetOwnerReferenceForNode(workerNode1)})
+		nodeReplacement.Spec.ReplacementSpec.Priority = intPtr(0)
+		nodeReplacement.Spec.ReplacementSpec.Approval = &navarchosv1alpha1.ApprovalWebhook{URL: server.URL}
+		nodeReplacement.Spec.NodeUID = workerNode1.GetUID()
+		nodeReplacement.Spec.NodeName = workerNode1.GetName()
+		m.Create(nodeReplacement).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		close(stopMgr)
+		mgrStopped.Wait()
+
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeReplacementList{},
+			&corev1.NodeList{},
+		)
+	})
+
+	Context("requestApproval", func() {
+		var reason navarchosv1alpha1.NodeReplacementConditionReason
+		var approvalErr error
+
+		JustBeforeEach(func() {
+			h = NewNodeReplacementHandler(m.Client, opts)
+			reason, approvalErr = h.requestApproval(nodeReplacement, workerNode1)
+		})
+
+		It("sends the NodeReplacement and node to the webhook", func() {
+			Expect(received).ToNot(BeNil())
+			Expect(received.NodeReplacement.GetName()).To(Equal(nodeReplacement.GetName()))
+			Expect(received.Node.GetName()).To(Equal(workerNode1.GetName()))
+		})
+
+		Context("when the webhook approves", func() {
+			It("sets the reason to Approved", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonApproved))
+				Expect(approvalErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the decision is pending", func() {
+			BeforeEach(func() {
+				response = `{"decision": "pending", "reason": "change not yet reviewed"}`
+			})
+
+			It("sets the reason to ApprovalPending", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonApprovalPending))
+				Expect(approvalErr).To(MatchError(ContainSubstring("change not yet reviewed")))
+			})
+		})
+
+		Context("when the webhook denies", func() {
+			BeforeEach(func() {
+				response = `{"decision": "deny", "reason": "change freeze"}`
+			})
+
+			It("sets the reason to ApprovalDenied", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonApprovalDenied))
+				Expect(approvalErr).To(MatchError(ContainSubstring("change freeze")))
+			})
+		})
+
+		Context("when the decision is invalid", func() {
+			BeforeEach(func() {
+				response = `{"decision": "maybe"}`
+			})
+
+			It("sets the reason to ErrorRequestingApproval", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonErrorRequestingApproval))
+				Expect(approvalErr).To(HaveOccurred())
+			})
+		})
+
+		Context("when the webhook returns an error status", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusInternalServerError
+			})
+
+			It("sets the reason to ErrorRequestingApproval", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonErrorRequestingApproval))
+				Expect(approvalErr).To(HaveOccurred())
+			})
+		})
+
+		Context("when the NodeReplacement has already been approved", func() {
+			BeforeEach(func() {
+				nodeReplacement.Status.Conditions = []navarchosv1alpha1.NodeReplacementCondition{
+					{
+						Type:   navarchosv1alpha1.ApprovedType,
+						Status: corev1.ConditionTrue,
+						Reason: navarchosv1alpha1.ReasonApproved,
+					},
+				}
+			})
+
+			It("does not call the webhook", func() {
+				Expect(received).To(BeNil())
+				Expect(reason).To(BeEmpty())
+			})
+		})
+
+		Context("when the NodeReplacement does not set a webhook", func() {
+			BeforeEach(func() {
+				nodeReplacement.Spec.ReplacementSpec.Approval = nil
+			})
+
+			It("does not require approval without a controller webhook", func() {
+				Expect(received).To(BeNil())
+				Expect(reason).To(BeEmpty())
+			})
+
+			Context("and the controller has a webhook", func() {
+				BeforeEach(func() {
+					opts.ApprovalWebhookURL = &server.URL
+				})
+
+				It("calls the controller's webhook", func() {
+					Expect(received).ToNot(BeNil())
+					Expect(reason).To(Equal(navarchosv1alpha1.ReasonApproved))
+				})
+			})
+		})
+	})
+
+	Context("handleNew", func() {
+		var result *status.Result
+		var handleErr error
+
+		JustBeforeEach(func() {
+			h = NewNodeReplacementHandler(m.Client, opts)
+			rollout, err := h.getRollout(nodeReplacement)
+			Expect(err).ToNot(HaveOccurred())
+			result, handleErr = h.handleNew(nodeReplacement, rollout)
+		})
+
+		Context("when the decision is pending", func() {
+			BeforeEach(func() {
+				response = `{"decision": "pending"}`
+			})
+
+			It("does not cordon the node", func() {
+				m.Consistently(workerNode1, time.Second).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+			})
+
+			It("requeues the NodeReplacement", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+				Expect(result.Requeue).To(BeTrue())
+				Expect(result.RequeueAfter).To(Equal(approvalRequeuePeriod))
+			})
+		})
+
+		Context("when the webhook denies", func() {
+			BeforeEach(func() {
+				response = `{"decision": "deny"}`
+			})
+
+			It("does not cordon the node", func() {
+				m.Consistently(workerNode1, time.Second).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+			})
+
+			It("sets the phase to Failed", func() {
+				failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+				Expect(result.Phase).To(Equal(&failedPhase))
+			})
+		})
+
+		Context("when the webhook approves", func() {
+			It("cordons the node", func() {
+				m.Eventually(workerNode1, timeout).Should(utils.WithField("Spec.Unschedulable", BeTrue()))
+			})
+
+			It("sets the ApprovedReason to Approved", func() {
+				Expect(result.ApprovedReason).To(Equal(navarchosv1alpha1.ReasonApproved))
+			})
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// blockingPodsRequeuePeriod determines how often a NodeReplacement waiting for
+// blocking pods is requeued, pods are not watched by the controller
+const blockingPodsRequeuePeriod = 30 * time.Second
+
+// waitForBlockingPods updates the result with the pods that must finish
+// before the node can be drained and returns true while the NodeReplacement
+// should keep waiting for them. Once the blocking pods timeout has passed the
+// node is drained regardless
+func (h *NodeReplacementHandler) waitForBlockingPods(instance *navarchosv1alpha1.NodeReplacement, settings *navarchosv1alpha1.DrainSpec, result *status.Result) (bool, error) {
+	blocking, err := h.blockingPods(instance.Spec.NodeName, *settings.WaitForJobs)
+	if err != nil {
+		return true, fmt.Errorf("error listing blocking pods: %v", err)
+	}
+	result.BlockingPods = blocking
+	if len(blocking) == 0 {
+		return false, nil
+	}
+
+	timeout := settings.BlockingPodsTimeout.Duration
+	cordonTime := instance.Status.CordonTimestamp
+	if timeout > 0 && cordonTime != nil && time.Since(cordonTime.Time) >= timeout {
+		return false, nil
+	}
+
+	result.Requeue = true
+	result.RequeueAfter = blockingPodsRequeuePeriod
+	result.RequeueReason = fmt.Sprintf("waiting for %d pod(s) to finish before draining node", len(blocking))
+	result.RequeueCause = string(navarchosv1alpha1.ReasonBlockingPods)
+	return true, nil
+}
+
+// blockingPods lists the pods on the node that have not finished and either
+// have the DoNotDisruptAnnotation or, if waitForJobs is set, are owned by a
+// Job. The pods are sorted by name
+func (h *NodeReplacementHandler) blockingPods(nodeName string, waitForJobs bool) ([]navarchosv1alpha1.PodReason, error) {
+	podList := &corev1.PodList{}
+	err := h.client.List(context.Background(), podList, client.MatchingField("spec.nodeName", nodeName))
+	if err != nil {
+		return nil, err
+	}
+
+	blocking := []navarchosv1alpha1.PodReason{}
+	for _, pod := range podList.Items {
+		if reason, ok := blockingReason(&pod, waitForJobs); ok {
+			blocking = append(blocking, navarchosv1alpha1.PodReason{Name: pod.GetName(), Namespace: pod.GetNamespace(), Reason: reason})
+		}
+	}
+	sort.Slice(blocking, func(i, j int) bool {
+		return blocking[i].Name < blocking[j].Name
+	})
+	return blocking, nil
+}
+
+// blockingReason returns why the pod blocks the drain of its node, or false
+// if it does not
+func blockingReason(pod *corev1.Pod, waitForJobs bool) (string, bool) {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return "", false
+	}
+	if pod.GetAnnotations()[navarchosv1alpha1.DoNotDisruptAnnotation] == "true" {
+		return fmt.Sprintf("pod has the %s annotation", navarchosv1alpha1.DoNotDisruptAnnotation), true
+	}
+	if !waitForJobs {
+		return "", false
+	}
+	for _, ref := range pod.GetOwnerReferences() {
+		if ref.Kind == "Job" {
+			return fmt.Sprintf("pod is owned by Job %s", ref.Name), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("blocking pods", func() {
+	var pod *corev1.Pod
+	var waitForJobs bool
+	var reason string
+	var blocking bool
+
+	BeforeEach(func() {
+		pod = utils.ExamplePod.DeepCopy()
+		pod.Status.Phase = corev1.PodRunning
+		pod.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Job", Name: "example-job"}})
+		waitForJobs = false
+	})
+
+	JustBeforeEach(func() {
+		reason, blocking = blockingReason(pod, waitForJobs)
+	})
+
+	It("does not block on a Job owned pod by default", func() {
+		Expect(blocking).To(BeFalse())
+	})
+
+	Context("when waiting for Jobs", func() {
+		BeforeEach(func() {
+			waitForJobs = true
+		})
+
+		It("blocks on the Job owned pod", func() {
+			Expect(blocking).To(BeTrue())
+			Expect(reason).To(Equal("pod is owned by Job example-job"))
+		})
+
+		Context("and the pod has completed", func() {
+			BeforeEach(func() {
+				pod.Status.Phase = corev1.PodSucceeded
+			})
+
+			It("does not block", func() {
+				Expect(blocking).To(BeFalse())
+			})
+		})
+	})
+
+	Context("when the pod has the do-not-disrupt annotation", func() {
+		BeforeEach(func() {
+			pod.SetAnnotations(map[string]string{navarchosv1alpha1.DoNotDisruptAnnotation: "true"})
+		})
+
+		It("blocks on the pod", func() {
+			Expect(blocking).To(BeTrue())
+			Expect(reason).To(Equal("pod has the navarchos.pusher.com/do-not-disrupt annotation"))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/capacity"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// capacityRequeuePeriod determines how often a NodeReplacement waiting for
+// the pods on its node to fit on the remaining nodes is requeued, pods are not
+// watched by the controller
+const capacityRequeuePeriod = 30 * time.Second
+
+// checkCapacity updates the result with the pods on the node that would not
+// fit on the remaining nodes once it is drained and returns true if the
+// NodeReplacement cannot continue. What happens to a NodeReplacement with
+// unschedulable pods is determined by the capacity policy of its drain
+// settings
+func (h *NodeReplacementHandler) checkCapacity(instance *navarchosv1alpha1.NodeReplacement, node *corev1.Node, result *status.Result) (bool, error) {
+	unschedulable, err := h.unschedulablePods(node)
+	if err != nil {
+		result.PodsSchedulableReason = navarchosv1alpha1.ReasonErrorSimulatingScheduling
+		result.PodsSchedulableError = fmt.Errorf("error simulating scheduling: %v", err)
+		return true, result.PodsSchedulableError
+	}
+	result.UnschedulablePods = unschedulable
+	if len(unschedulable) == 0 {
+		result.PodsSchedulableReason = navarchosv1alpha1.ReasonPodsSchedulable
+		return false, nil
+	}
+
+	names := []string{}
+	for _, pod := range unschedulable {
+		names = append(names, pod.Name)
+	}
+	result.PodsSchedulableReason = navarchosv1alpha1.ReasonPodsUnschedulable
+	result.PodsSchedulableError = fmt.Errorf("%d pod(s) would not fit on the remaining nodes: %s", len(unschedulable), strings.Join(names, ", "))
+
+	switch policy := h.drainSettings(instance).CapacityPolicy; policy {
+	case navarchosv1alpha1.CapacityPolicyIgnore:
+		return false, nil
+	case navarchosv1alpha1.CapacityPolicyWait:
+		result.Requeue = true
+		result.RequeueAfter = capacityRequeuePeriod
+		result.RequeueReason = result.PodsSchedulableError.Error()
+		result.RequeueCause = string(result.PodsSchedulableReason)
+		return true, nil
+	case navarchosv1alpha1.CapacityPolicyFail:
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		result.Phase = &failedPhase
+		return true, nil
+	default:
+		result.PodsSchedulableReason = navarchosv1alpha1.ReasonErrorSimulatingScheduling
+		result.PodsSchedulableError = fmt.Errorf("invalid capacity policy %q", policy)
+		return true, result.PodsSchedulableError
+	}
+}
+
+// unschedulablePods simulates rescheduling the pods that would be evicted
+// from the node onto the other nodes of the cluster and returns the pods that
+// would not fit, with the reasons why
+func (h *NodeReplacementHandler) unschedulablePods(node *corev1.Node) ([]navarchosv1alpha1.PodReason, error) {
+	podList := &corev1.PodList{}
+	err := h.client.List(context.Background(), podList)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+	nodeList := &corev1.NodeList{}
+	err = h.client.List(context.Background(), nodeList)
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %v", err)
+	}
+
+	evictable := []corev1.Pod{}
+	running := []corev1.Pod{}
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != node.GetName() {
+			running = append(running, pod)
+			continue
+		}
+		if IsEvictable(&pod) {
+			evictable = append(evictable, pod)
+		}
+	}
+	nodes := []corev1.Node{}
+	for _, other := range nodeList.Items {
+		if other.GetName() != node.GetName() {
+			nodes = append(nodes, other)
+		}
+	}
+
+	unschedulable := []navarchosv1alpha1.PodReason{}
+	for _, pod := range capacity.Simulate(evictable, nodes, running) {
+		unschedulable = append(unschedulable, navarchosv1alpha1.PodReason{Name: pod.Pod.GetName(), Namespace: pod.Pod.GetNamespace(), Reason: pod.Reason})
+	}
+	return unschedulable, nil
+}
@@ -2,13 +2,17 @@ package handler
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/pkg/provider"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -35,6 +39,80 @@ type Options struct {
 	// or StatefulSet. Defaults false
 	ForcePodDeletion *bool
 
+	// EvictionOrder determines whether the controller evicts the pods on a
+	// node in waves. Defaults None
+	EvictionOrder *navarchosv1alpha1.EvictionOrder
+
+	// WaitForJobs instructs the controller to wait for pods owned by a Job to
+	// complete before draining a node. Defaults false
+	WaitForJobs *bool
+
+	// BlockingPodsTimeout determines how long after cordoning a node the
+	// controller waits for blocking pods to finish before draining it anyway.
+	// Zero means infinite
+	BlockingPodsTimeout *time.Duration
+
+	// PDBPolicy determines what happens to a NodeReplacement when
+	// PodDisruptionBudgets would block the drain of its node, checked before
+	// the node is cordoned. Defaults Ignore
+	PDBPolicy *navarchosv1alpha1.PDBPolicy
+
+	// CapacityPolicy determines what happens to a NodeReplacement when the
+	// pods on its node would not fit on the remaining nodes, checked before
+	// the node is cordoned. Defaults Ignore
+	CapacityPolicy *navarchosv1alpha1.CapacityPolicy
+
+	// PendingPodsGracePeriod determines how long a pod must have been
+	// unschedulable before it blocks NodeReplacements from starting. Defaults
+	// zero
+	PendingPodsGracePeriod *time.Duration
+
+	// PendingPodsIgnoreNamespaces lists the namespaces whose unschedulable
+	// pods never block NodeReplacements from starting
+	PendingPodsIgnoreNamespaces []string
+
+	// PendingPodsIgnoreSelector matches the unschedulable pods that never
+	// block NodeReplacements from starting. Defaults to matching no pods
+	PendingPodsIgnoreSelector labels.Selector
+
+	// MaxDrainAttempts determines how many times the controller should attempt
+	// to drain a node before marking the NodeReplacement as failed. Zero means
+	// infinite. Defaults 0
+	MaxDrainAttempts *int
+
+	// MaxDrainDuration determines how long after cordoning a node the
+	// controller should keep attempting to drain it before marking the
+	// NodeReplacement as failed. Zero means infinite
+	MaxDrainDuration *time.Duration
+
+	// DrainBackoff determines how long the controller should wait before
+	// retrying a failed drain. It is doubled after every failed attempt.
+	// Defaults 30 seconds
+	DrainBackoff *time.Duration
+
+	// MaxDrainBackoff caps how long the controller should wait before retrying
+	// a failed drain. Defaults 10 minutes
+	MaxDrainBackoff *time.Duration
+
+	// ReplacementTimeout determines how long the controller should wait for a
+	// replacement node to become Ready when the NodeReplacement does not set
+	// its own timeout. Defaults 30 minutes
+	ReplacementTimeout *time.Duration
+
+	// ApprovalWebhookURL is the URL of the webhook that must approve the
+	// disruption of a node before it is cordoned, when the NodeReplacement
+	// does not set its own. Empty means no approval is required
+	ApprovalWebhookURL *string
+
+	// ApprovalTimeout determines how long the controller waits for a response
+	// from the approval webhook. Defaults 10 seconds
+	ApprovalTimeout *time.Duration
+
+	// Provider is used to terminate the instance backing a node once it has
+	// been drained. If nil, replacements are completed as soon as the node is
+	// drained
+	Provider provider.Provider
+
 	// Config is used to construct a kubernetes client
 	Config *rest.Config
 
@@ -62,6 +140,60 @@ func (o *Options) Complete() {
 	if o.ForcePodDeletion == nil {
 		o.ForcePodDeletion = boolPtr(false)
 	}
+	if o.EvictionOrder == nil {
+		order := navarchosv1alpha1.EvictionOrderNone
+		o.EvictionOrder = &order
+	}
+	if o.WaitForJobs == nil {
+		o.WaitForJobs = boolPtr(false)
+	}
+	if o.BlockingPodsTimeout == nil {
+		var timeout time.Duration
+		o.BlockingPodsTimeout = &timeout
+	}
+	if o.PDBPolicy == nil {
+		policy := navarchosv1alpha1.PDBPolicyIgnore
+		o.PDBPolicy = &policy
+	}
+	if o.CapacityPolicy == nil {
+		policy := navarchosv1alpha1.CapacityPolicyIgnore
+		o.CapacityPolicy = &policy
+	}
+	if o.PendingPodsGracePeriod == nil {
+		var grace time.Duration
+		o.PendingPodsGracePeriod = &grace
+	}
+	if o.PendingPodsIgnoreSelector == nil {
+		o.PendingPodsIgnoreSelector = labels.Nothing()
+	}
+	if o.MaxDrainAttempts == nil {
+		var attempts int
+		o.MaxDrainAttempts = &attempts
+	}
+	if o.MaxDrainDuration == nil {
+		var duration time.Duration
+		o.MaxDrainDuration = &duration
+	}
+	if o.DrainBackoff == nil {
+		backoff := 30 * time.Second
+		o.DrainBackoff = &backoff
+	}
+	if o.MaxDrainBackoff == nil {
+		backoff := 10 * time.Minute
+		o.MaxDrainBackoff = &backoff
+	}
+	if o.ReplacementTimeout == nil {
+		timeout := 30 * time.Minute
+		o.ReplacementTimeout = &timeout
+	}
+	if o.ApprovalWebhookURL == nil {
+		var url string
+		o.ApprovalWebhookURL = &url
+	}
+	if o.ApprovalTimeout == nil {
+		timeout := 10 * time.Second
+		o.ApprovalTimeout = &timeout
+	}
 	if o.Config != nil {
 		o.k8sClient = kubernetes.NewForConfigOrDie(o.Config)
 	}
@@ -70,26 +202,58 @@ func (o *Options) Complete() {
 // NodeReplacementHandler handles the business logic within the NodeReplacement
 // controller.
 type NodeReplacementHandler struct {
-	client              client.Client
-	k8sClient           kubernetes.Interface
-	evictionGracePeriod time.Duration
-	drainTimeout        time.Duration
-	ignoreAllDaemonSets bool
-	deleteLocalData     bool
-	forcePodDeletion    bool
+	client                      client.Client
+	k8sClient                   kubernetes.Interface
+	evictionGracePeriod         time.Duration
+	drainTimeout                time.Duration
+	ignoreAllDaemonSets         bool
+	deleteLocalData             bool
+	forcePodDeletion            bool
+	evictionOrder               navarchosv1alpha1.EvictionOrder
+	waitForJobs                 bool
+	blockingPodsTimeout         time.Duration
+	pdbPolicy                   navarchosv1alpha1.PDBPolicy
+	capacityPolicy              navarchosv1alpha1.CapacityPolicy
+	pendingPodsGracePeriod      time.Duration
+	pendingPodsIgnoreNamespaces []string
+	pendingPodsIgnoreSelector   labels.Selector
+	maxDrainAttempts            int
+	maxDrainDuration            time.Duration
+	drainBackoff                time.Duration
+	maxDrainBackoff             time.Duration
+	replacementTimeout          time.Duration
+	approvalWebhookURL          string
+	httpClient                  *http.Client
+	provider                    provider.Provider
 }
 
 // NewNodeReplacementHandler creates a new NodeReplacementHandler
 func NewNodeReplacementHandler(c client.Client, opts *Options) *NodeReplacementHandler {
 	opts.Complete()
 	return &NodeReplacementHandler{
-		client:              c,
-		k8sClient:           opts.k8sClient,
-		evictionGracePeriod: *opts.EvictionGracePeriod,
-		drainTimeout:        *opts.DrainTimeout,
-		ignoreAllDaemonSets: *opts.IgnoreAllDaemonSets,
-		deleteLocalData:     *opts.DeleteLocalData,
-		forcePodDeletion:    *opts.ForcePodDeletion,
+		client:                      c,
+		k8sClient:                   opts.k8sClient,
+		evictionGracePeriod:         *opts.EvictionGracePeriod,
+		drainTimeout:                *opts.DrainTimeout,
+		ignoreAllDaemonSets:         *opts.IgnoreAllDaemonSets,
+		deleteLocalData:             *opts.DeleteLocalData,
+		forcePodDeletion:            *opts.ForcePodDeletion,
+		evictionOrder:               *opts.EvictionOrder,
+		waitForJobs:                 *opts.WaitForJobs,
+		blockingPodsTimeout:         *opts.BlockingPodsTimeout,
+		pdbPolicy:                   *opts.PDBPolicy,
+		capacityPolicy:              *opts.CapacityPolicy,
+		pendingPodsGracePeriod:      *opts.PendingPodsGracePeriod,
+		pendingPodsIgnoreNamespaces: opts.PendingPodsIgnoreNamespaces,
+		pendingPodsIgnoreSelector:   opts.PendingPodsIgnoreSelector,
+		maxDrainAttempts:            *opts.MaxDrainAttempts,
+		maxDrainDuration:            *opts.MaxDrainDuration,
+		drainBackoff:                *opts.DrainBackoff,
+		maxDrainBackoff:             *opts.MaxDrainBackoff,
+		replacementTimeout:          *opts.ReplacementTimeout,
+		approvalWebhookURL:          *opts.ApprovalWebhookURL,
+		httpClient:                  &http.Client{Timeout: *opts.ApprovalTimeout},
+		provider:                    opts.Provider,
 	}
 }
 
@@ -98,8 +262,26 @@ func NewNodeReplacementHandler(c client.Client, opts *Options) *NodeReplacementH
 // instance of a NodeReplacement can be handled in full without interruption
 func (h *NodeReplacementHandler) Handle(instance *navarchosv1alpha1.NodeReplacement) (*status.Result, error) {
 	var result = &status.Result{}
+	var rollout *navarchosv1alpha1.NodeRollout
 	var err error
 
+	// Replacements that have not yet drained their node, including those that
+	// failed to, are stopped when their NodeRollout is aborted
+	switch instance.Status.Phase {
+	case navarchosv1alpha1.ReplacementPhaseTerminating,
+		navarchosv1alpha1.ReplacementPhaseWaitingForReplacement,
+		navarchosv1alpha1.ReplacementPhaseCompleted,
+		navarchosv1alpha1.ReplacementPhaseAborted:
+	default:
+		rollout, err = h.getRollout(instance)
+		if err != nil {
+			return result, fmt.Errorf("error getting NodeRollout: %v", err)
+		}
+		if isRolloutAborted(rollout) {
+			return h.handleAborted(instance)
+		}
+	}
+
 	switch instance.Status.Phase {
 	default:
 		newPhase := navarchosv1alpha1.ReplacementPhaseNew
@@ -117,11 +299,11 @@ func (h *NodeReplacementHandler) Handle(instance *navarchosv1alpha1.NodeReplacem
 
 		fallthrough // This is important, we want one instance to be handled to completion without a requeue if possible
 	case navarchosv1alpha1.ReplacementPhaseNew:
-		result, err = h.handleNew(instance)
+		result, err = h.handleNew(instance, rollout)
 		if err != nil {
 			return result, err
 		}
-		if result.Requeue {
+		if result.Requeue || (result.Phase != nil && (*result.Phase == navarchosv1alpha1.ReplacementPhaseFailed || *result.Phase == navarchosv1alpha1.ReplacementPhaseAborted)) {
 			return result, nil
 		}
 
@@ -137,13 +319,63 @@ func (h *NodeReplacementHandler) Handle(instance *navarchosv1alpha1.NodeReplacem
 		if err != nil {
 			return result, err
 		}
-		// Nothing left to do
-		return result, nil
-	case navarchosv1alpha1.ReplacementPhaseCompleted:
+		if result.Phase == nil || *result.Phase == navarchosv1alpha1.ReplacementPhaseCompleted || *result.Phase == navarchosv1alpha1.ReplacementPhaseFailed || *result.Phase == navarchosv1alpha1.ReplacementPhaseAborted {
+			// Nothing left to do
+			return result, nil
+		}
+
+		// Update status before starting next phase
+		err = status.UpdateStatus(h.client, instance, result)
+		if err != nil {
+			return result, fmt.Errorf("error updating status: %v", err)
+		}
+
+		fallthrough // This is important, we want one instance to be handled to completion without a requeue if possible
+	case navarchosv1alpha1.ReplacementPhaseTerminating:
+		// The in progress phase may skip termination if there is no provider
+		if instance.Status.Phase == navarchosv1alpha1.ReplacementPhaseTerminating {
+			result, err = h.handleTerminating(instance)
+			if err != nil {
+				return result, err
+			}
+			if result.Phase == nil || *result.Phase == navarchosv1alpha1.ReplacementPhaseCompleted || *result.Phase == navarchosv1alpha1.ReplacementPhaseFailed {
+				return result, nil
+			}
+
+			// Update status before starting next phase
+			err = status.UpdateStatus(h.client, instance, result)
+			if err != nil {
+				return result, fmt.Errorf("error updating status: %v", err)
+			}
+		}
+
+		fallthrough // This is important, we want one instance to be handled to completion without a requeue if possible
+	case navarchosv1alpha1.ReplacementPhaseWaitingForReplacement:
+		return h.handleWaitingForReplacement(instance)
+	case navarchosv1alpha1.ReplacementPhaseCompleted, navarchosv1alpha1.ReplacementPhaseAborted, navarchosv1alpha1.ReplacementPhaseFailed:
 		return &status.Result{}, nil
 	}
 }
 
+// drainedResult returns a Result moving a NodeReplacement whose node has been
+// drained, and terminated if required, to the WaitingForReplacement phase if
+// it waits for a replacement node, or to the Completed phase otherwise
+func drainedResult(instance *navarchosv1alpha1.NodeReplacement) *status.Result {
+	if instance.Spec.ReplacementSpec.WaitForReplacement != nil {
+		waitingPhase := navarchosv1alpha1.ReplacementPhaseWaitingForReplacement
+		return &status.Result{
+			Phase: &waitingPhase,
+		}
+	}
+
+	completedPhase := navarchosv1alpha1.ReplacementPhaseCompleted
+	completedTime := metav1.Now()
+	return &status.Result{
+		Phase:               &completedPhase,
+		CompletionTimestamp: &completedTime,
+	}
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
@@ -25,11 +25,14 @@ import (
 	. "github.com/onsi/gomega"
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/pkg/provider"
+	"github.com/pusher/navarchos/pkg/provider/fake"
 	"github.com/pusher/navarchos/test/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -79,9 +82,17 @@ var _ = Describe("Handler suite", func() {
 		return pod
 	}
 
-	var setPodPending = func(obj utils.Object) utils.Object {
+	var setPodUnschedulable = func(obj utils.Object) utils.Object {
 		pod, _ := obj.(*corev1.Pod)
 		pod.Status.Phase = corev1.PodPending
+		pod.Status.Conditions = []corev1.PodCondition{
+			{
+				Type:               corev1.PodScheduled,
+				Status:             corev1.ConditionFalse,
+				Reason:             corev1.PodReasonUnschedulable,
+				LastTransitionTime: metav1.Now(),
+			},
+		}
 		return pod
 	}
 
@@ -229,6 +240,7 @@ var _ = Describe("Handler suite", func() {
 			It("requeues the NodeReplacement", func() {
 				Expect(result.Requeue).To(BeTrue())
 				Expect(result.RequeueReason).To(Equal("NodeReplacement \"high-priority\" has a higher priority"))
+				Expect(result.RequeueCause).To(Equal(string(navarchosv1alpha1.ReasonReplacementsInProgress)))
 			})
 
 			It("does not set the Result NodePods field", func() {
@@ -306,14 +318,19 @@ var _ = Describe("Handler suite", func() {
 			})
 		})
 
-		Context("if a pod is pending", func() {
+		Context("if a pod is unschedulable", func() {
 			BeforeEach(func() {
-				m.UpdateStatus(pod1, setPodPending, timeout).Should(Succeed())
+				m.UpdateStatus(pod1, setPodUnschedulable, timeout).Should(Succeed())
 			})
 
 			It("requeues the NodeReplacement", func() {
 				Expect(result.Requeue).To(BeTrue())
-				Expect(result.RequeueReason).To(Equal("requeuing as there are pending pod(s): [pod-1]"))
+				Expect(result.RequeueReason).To(Equal("1 pod(s) have been unschedulable for longer than 0s: default/pod-1"))
+				Expect(result.RequeueCause).To(Equal("PendingPods"))
+			})
+
+			It("sets the PendingPodsClearedReason to PendingPods", func() {
+				Expect(result.PendingPodsClearedReason).To(Equal(navarchosv1alpha1.ReasonPendingPods))
 			})
 
 			It("does not set the Result NodePods field", func() {
@@ -359,7 +376,7 @@ var _ = Describe("Handler suite", func() {
 			It("should ignore the DaemonSet managed Pod", func() {
 				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.IgnoredPods",
 					ConsistOf(
-						navarchosv1alpha1.PodReason{Name: "pod-1", Reason: "pod owned by a DaemonSet"},
+						navarchosv1alpha1.PodReason{Name: "pod-1", Namespace: "default", Reason: "pod owned by a DaemonSet"},
 					),
 				))
 			})
@@ -420,6 +437,10 @@ var _ = Describe("Handler suite", func() {
 			Expect(result.EvictedPods).To(ConsistOf("pod-1", "pod-2", "pod-3"))
 		})
 
+		It("sets the DrainStartTimestamp in the Result", func() {
+			Expect(result.DrainStartTimestamp).ToNot(BeNil())
+		})
+
 		It("does not add any pods to the Result FailedPods field", func() {
 			Expect(result.FailedPods).To(BeEmpty())
 		})
@@ -433,6 +454,42 @@ var _ = Describe("Handler suite", func() {
 			Expect(result.Phase).To(Equal(&phase))
 		})
 
+		Context("when a provider is configured", func() {
+			var fakeProvider *fake.Provider
+			const providerID = "fake:///example-worker-1"
+
+			BeforeEach(func() {
+				fakeProvider = fake.NewProvider()
+				fakeProvider.AddInstance(providerID)
+				opts.Provider = fakeProvider
+
+				m.Update(workerNode1, func(obj utils.Object) utils.Object {
+					node, _ := obj.(*corev1.Node)
+					node.Spec.ProviderID = providerID
+					return node
+				}, timeout).Should(Succeed())
+			})
+
+			It("sets the Status Phase field to Terminating", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.Phase",
+					Equal(navarchosv1alpha1.ReplacementPhaseTerminating),
+				))
+			})
+
+			It("requests termination of the instance", func() {
+				Expect(fakeProvider.InstanceState(providerID)).To(Equal(provider.InstanceStateTerminating))
+			})
+
+			It("requeues the NodeReplacement until the instance has terminated", func() {
+				Expect(result.Requeue).To(BeTrue())
+				Expect(result.RequeueReason).To(Equal("waiting for instance fake:///example-worker-1 to terminate"))
+			})
+
+			It("should not return an error", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+			})
+		})
+
 		PIt("deletes the node", func() {
 			m.Get(workerNode1, timeout).ShouldNot(Succeed())
 		})
@@ -491,8 +548,9 @@ var _ = Describe("Handler suite", func() {
 				It("fails the eviction of the Pod", func() {
 					Expect(result.FailedPods).To(ConsistOf(
 						navarchosv1alpha1.PodReason{
-							Name:   "pod-1",
-							Reason: "error when evicting pod \"pod-1\" (will retry after 5s): Cannot evict pod as it would violate the pod's disruption budget.",
+							Name:      "pod-1",
+							Namespace: "default",
+							Reason:    "error when evicting pod \"pod-1\" (will retry after 5s): Cannot evict pod as it would violate the pod's disruption budget.",
 						},
 					))
 				})
@@ -563,3 +621,7 @@ var _ = Describe("Handler suite", func() {
 func intPtr(i int) *int {
 	return &i
 }
+
+func intstrPtr(i intstr.IntOrString) *intstr.IntOrString {
+	return &i
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	preCordonHook = "pre-cordon"
+	preDrainHook  = "pre-drain"
+	postDrainHook = "post-drain"
+
+	// hookRequeuePeriod determines how often a NodeReplacement waiting for a
+	// hook Job is requeued, in case an update to the Job is missed
+	hookRequeuePeriod = 30 * time.Second
+
+	// maxJobNameLength is the maximum length of a Job name, the name is used
+	// as a label value on the Job's pods
+	maxJobNameLength = 63
+)
+
+// replacementHooks returns the hooks of the NodeReplacement. If none are set
+// it returns an empty ReplacementHooks
+func replacementHooks(instance *navarchosv1alpha1.NodeReplacement) *navarchosv1alpha1.ReplacementHooks {
+	if instance.Spec.ReplacementSpec.Hooks == nil {
+		return &navarchosv1alpha1.ReplacementHooks{}
+	}
+	return instance.Spec.ReplacementSpec.Hooks
+}
+
+// runHook creates the Job for the hook if it does not exist yet and returns
+// the reason for the hook's condition. While the Job is running, or if it has
+// failed, the returned error describes its state. If the hook is not set, or
+// has already succeeded according to the condition of the given type, the
+// reason and error are empty
+func (h *NodeReplacementHandler) runHook(instance *navarchosv1alpha1.NodeReplacement, name string, hook *navarchosv1alpha1.Hook, condType navarchosv1alpha1.NodeReplacementConditionType) (navarchosv1alpha1.NodeReplacementConditionReason, error) {
+	if hook == nil || isConditionTrue(instance, condType) {
+		return "", nil
+	}
+
+	job, err := h.getOrCreateHookJob(instance, name, hook)
+	if err != nil {
+		return navarchosv1alpha1.ReasonErrorRunningHook, fmt.Errorf("error running %s hook: %v", name, err)
+	}
+
+	if job.Status.Succeeded > 0 {
+		return navarchosv1alpha1.ReasonHookSucceeded, nil
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return navarchosv1alpha1.ReasonHookFailed, fmt.Errorf("%s hook Job %s/%s failed: %s", name, job.GetNamespace(), job.GetName(), cond.Message)
+		}
+	}
+	return navarchosv1alpha1.ReasonHookRunning, fmt.Errorf("waiting for %s hook Job %s/%s to complete", name, job.GetNamespace(), job.GetName())
+}
+
+// stopForHook updates the result for a hook that has not succeeded and returns
+// true if the NodeReplacement cannot continue. While the hook Job is running
+// the NodeReplacement is requeued, if the Job failed the NodeReplacement is
+// failed. An error is returned if the controller could not run the hook
+func stopForHook(result *status.Result, reason navarchosv1alpha1.NodeReplacementConditionReason, hookErr error) (bool, error) {
+	switch reason {
+	case navarchosv1alpha1.ReasonHookRunning:
+		result.Requeue = true
+		result.RequeueAfter = hookRequeuePeriod
+		result.RequeueReason = hookErr.Error()
+		result.RequeueCause = string(reason)
+		return true, nil
+	case navarchosv1alpha1.ReasonHookFailed:
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		result.Phase = &failedPhase
+		return true, nil
+	case navarchosv1alpha1.ReasonErrorRunningHook:
+		return true, hookErr
+	default:
+		return false, nil
+	}
+}
+
+// getOrCreateHookJob gets the Job for the hook, creating it from the hook's
+// template if it does not exist
+func (h *NodeReplacementHandler) getOrCreateHookJob(instance *navarchosv1alpha1.NodeReplacement, name string, hook *navarchosv1alpha1.Hook) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	err := h.client.Get(context.Background(), client.ObjectKey{
+		Namespace: hook.Namespace,
+		Name:      hookJobName(instance, name),
+	}, job)
+	if err == nil {
+		if !metav1.IsControlledBy(job, instance) {
+			return nil, fmt.Errorf("job %s/%s already exists and is not controlled by the NodeReplacement", job.GetNamespace(), job.GetName())
+		}
+		return job, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("error getting Job: %v", err)
+	}
+
+	job = newHookJob(instance, name, hook)
+	err = h.client.Create(context.Background(), job)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("error creating Job: %v", err)
+	}
+	return job, nil
+}
+
+// newHookJob builds the Job for the hook from its template. The Job is
+// controlled by the NodeReplacement and the name of the node is added to the
+// environment of every container
+func newHookJob(instance *navarchosv1alpha1.NodeReplacement, name string, hook *navarchosv1alpha1.Hook) *batchv1.Job {
+	template := hook.Template.DeepCopy()
+	job := &batchv1.Job{
+		ObjectMeta: template.ObjectMeta,
+		Spec:       template.Spec,
+	}
+	job.SetName(hookJobName(instance, name))
+	job.SetNamespace(hook.Namespace)
+	job.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(instance, navarchosv1alpha1.SchemeGroupVersion.WithKind("NodeReplacement")),
+	})
+
+	nodeNameEnv := corev1.EnvVar{Name: "NODE_NAME", Value: instance.Spec.NodeName}
+	podSpec := &job.Spec.Template.Spec
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Env = append(podSpec.InitContainers[i].Env, nodeNameEnv)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, nodeNameEnv)
+	}
+
+	return job
+}
+
+// hookJobName returns the name of the Job for the hook. The name of the
+// NodeReplacement is truncated so that the name is a valid label value
+func hookJobName(instance *navarchosv1alpha1.NodeReplacement, name string) string {
+	suffix := "-" + name
+	prefix := instance.GetName()
+	if len(prefix)+len(suffix) > maxJobNameLength {
+		prefix = strings.TrimRight(prefix[:maxJobNameLength-len(suffix)], "-.")
+	}
+	return prefix + suffix
+}
+
+// isConditionTrue returns true if the NodeReplacement has a condition of the
+// given type with status True
+func isConditionTrue(instance *navarchosv1alpha1.NodeReplacement, condType navarchosv1alpha1.NodeReplacementConditionType) bool {
+	for _, cond := range instance.Status.Conditions {
+		if cond.Type == condType {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isHookRunning returns true if the NodeReplacement is waiting for the hook
+// Job of the given condition type to complete
+func isHookRunning(instance *navarchosv1alpha1.NodeReplacement, condType navarchosv1alpha1.NodeReplacementConditionType) bool {
+	for _, cond := range instance.Status.Conditions {
+		if cond.Type == condType {
+			return cond.Reason == navarchosv1alpha1.ReasonHookRunning
+		}
+	}
+	return false
+}
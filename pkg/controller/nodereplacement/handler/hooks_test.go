@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/test/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("replacement hooks", func() {
+	var m utils.Matcher
+	var h *NodeReplacementHandler
+
+	var nodeReplacement *navarchosv1alpha1.NodeReplacement
+	var hook *navarchosv1alpha1.Hook
+	var mgrStopped *sync.WaitGroup
+	var stopMgr chan struct{}
+
+	var workerNode1 *corev1.Node
+
+	const timeout = time.Second * 5
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+
+		h = NewNodeReplacementHandler(m.Client, &Options{})
+
+		workerNode1 = utils.ExampleNodeWorker1.DeepCopy()
+		m.Create(workerNode1).Should(Succeed())
+
+		hook = &navarchosv1alpha1.Hook{
+			Namespace: "default",
+			Template: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "deregister",
+									Image: "busybox",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		nodeReplacement = utils.ExampleNodeReplacement.DeepCopy()
+		nodeReplacement.SetOwnerReferences([]metav1.OwnerReference{utils.GetOwnerReferenceForNode(workerNode1)})
+		nodeReplacement.Spec.NodeUID = workerNode1.GetUID()
+		nodeReplacement.Spec.NodeName = workerNode1.GetName()
+		m.Create(nodeReplacement).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeReplacementList{},
+			&batchv1.JobList{},
+			&corev1.NodeList{},
+		)
+	})
+
+	Context("runHook", func() {
+		var reason navarchosv1alpha1.NodeReplacementConditionReason
+		var hookErr error
+		var job *batchv1.Job
+
+		JustBeforeEach(func() {
+			reason, hookErr = h.runHook(nodeReplacement, preDrainHook, hook, navarchosv1alpha1.PreDrainHookType)
+			job = &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      hookJobName(nodeReplacement, preDrainHook),
+				},
+			}
+		})
+
+		Context("when the hook is not set", func() {
+			BeforeEach(func() {
+				hook = nil
+			})
+
+			It("does not set a reason", func() {
+				Expect(reason).To(BeEmpty())
+				Expect(hookErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the Job does not exist", func() {
+			It("creates the Job controlled by the NodeReplacement", func() {
+				m.Get(job, timeout).Should(Succeed())
+				Expect(metav1.IsControlledBy(job, nodeReplacement)).To(BeTrue())
+			})
+
+			It("sets the node name in the environment of the Job's containers", func() {
+				m.Get(job, timeout).Should(Succeed())
+				Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+					Name:  "NODE_NAME",
+					Value: workerNode1.GetName(),
+				}))
+			})
+
+			It("sets the reason to HookRunning", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonHookRunning))
+				Expect(hookErr).To(HaveOccurred())
+			})
+		})
+
+		Context("when the Job has succeeded", func() {
+			BeforeEach(func() {
+				_, err := h.runHook(nodeReplacement, preDrainHook, hook, navarchosv1alpha1.PreDrainHookType)
+				Expect(err).To(HaveOccurred())
+				created := &batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      hookJobName(nodeReplacement, preDrainHook),
+					},
+				}
+				m.UpdateStatus(created, func(obj utils.Object) utils.Object {
+					j, _ := obj.(*batchv1.Job)
+					j.Status.Succeeded = 1
+					return j
+				}, timeout).Should(Succeed())
+			})
+
+			It("sets the reason to HookSucceeded", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonHookSucceeded))
+				Expect(hookErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the Job has failed", func() {
+			BeforeEach(func() {
+				_, err := h.runHook(nodeReplacement, preDrainHook, hook, navarchosv1alpha1.PreDrainHookType)
+				Expect(err).To(HaveOccurred())
+				created := &batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      hookJobName(nodeReplacement, preDrainHook),
+					},
+				}
+				m.UpdateStatus(created, func(obj utils.Object) utils.Object {
+					j, _ := obj.(*batchv1.Job)
+					j.Status.Conditions = []batchv1.JobCondition{
+						{
+							Type:    batchv1.JobFailed,
+							Status:  corev1.ConditionTrue,
+							Message: "Job has reached the specified backoff limit",
+						},
+					}
+					return j
+				}, timeout).Should(Succeed())
+			})
+
+			It("sets the reason to HookFailed", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonHookFailed))
+				Expect(hookErr).To(MatchError(ContainSubstring("backoff limit")))
+			})
+		})
+
+		Context("when the hook has already succeeded", func() {
+			BeforeEach(func() {
+				nodeReplacement.Status.Conditions = []navarchosv1alpha1.NodeReplacementCondition{
+					{
+						Type:   navarchosv1alpha1.PreDrainHookType,
+						Status: corev1.ConditionTrue,
+						Reason: navarchosv1alpha1.ReasonHookSucceeded,
+					},
+				}
+			})
+
+			It("does not create the Job", func() {
+				m.Consistently(&batchv1.JobList{}, time.Second).Should(utils.WithField("Items", BeEmpty()))
+			})
+
+			It("does not set a reason", func() {
+				Expect(reason).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when handleNew is called again while the pre-cordon hook is running", func() {
+		var result *status.Result
+		var handleErr error
+
+		BeforeEach(func() {
+			m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+				nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+				nr.Spec.ReplacementSpec.Hooks = &navarchosv1alpha1.ReplacementHooks{PreCordon: hook}
+				nr.Status.Conditions = []navarchosv1alpha1.NodeReplacementCondition{
+					{
+						Type:   navarchosv1alpha1.PreCordonHookType,
+						Status: corev1.ConditionFalse,
+						Reason: navarchosv1alpha1.ReasonHookRunning,
+					},
+				}
+				return nr
+			}, timeout).Should(Succeed())
+
+			_, err := h.runHook(nodeReplacement, preCordonHook, hook, navarchosv1alpha1.PreCordonHookType)
+			Expect(err).To(HaveOccurred())
+			created := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      hookJobName(nodeReplacement, preCordonHook),
+				},
+			}
+			m.UpdateStatus(created, func(obj utils.Object) utils.Object {
+				j, _ := obj.(*batchv1.Job)
+				j.Status.Succeeded = 1
+				return j
+			}, timeout).Should(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			rollout, err := h.getRollout(nodeReplacement)
+			Expect(err).ToNot(HaveOccurred())
+			result, handleErr = h.handleNew(nodeReplacement, rollout)
+		})
+
+		It("does not count itself as an in-progress NodeReplacement", func() {
+			Expect(result.RequeueCause).ToNot(Equal(string(navarchosv1alpha1.ReasonReplacementsInProgress)))
+		})
+
+		It("cordons the node once the hook has succeeded", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+			Expect(result.PreCordonHookReason).To(Equal(navarchosv1alpha1.ReasonHookSucceeded))
+			Expect(result.NodeCordonReason).To(Equal(navarchosv1alpha1.ReasonNodeCordoned))
+		})
+	})
+
+	Context("stopForHook", func() {
+		var result *status.Result
+		var stop bool
+		var stopErr error
+		var reason navarchosv1alpha1.NodeReplacementConditionReason
+
+		JustBeforeEach(func() {
+			result = &status.Result{}
+			stop, stopErr = stopForHook(result, reason, fmt.Errorf("hook error"))
+		})
+
+		Context("when the hook is running", func() {
+			BeforeEach(func() {
+				reason = navarchosv1alpha1.ReasonHookRunning
+			})
+
+			It("requeues the NodeReplacement", func() {
+				Expect(stop).To(BeTrue())
+				Expect(result.Requeue).To(BeTrue())
+				Expect(result.RequeueAfter).To(Equal(hookRequeuePeriod))
+			})
+		})
+
+		Context("when the hook has failed", func() {
+			BeforeEach(func() {
+				reason = navarchosv1alpha1.ReasonHookFailed
+			})
+
+			It("sets the phase to Failed", func() {
+				failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+				Expect(stop).To(BeTrue())
+				Expect(result.Phase).To(Equal(&failedPhase))
+				Expect(stopErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the hook could not be run", func() {
+			BeforeEach(func() {
+				reason = navarchosv1alpha1.ReasonErrorRunningHook
+			})
+
+			It("returns the error", func() {
+				Expect(stop).To(BeTrue())
+				Expect(stopErr).To(HaveOccurred())
+			})
+		})
+
+		Context("when the hook has succeeded", func() {
+			BeforeEach(func() {
+				reason = navarchosv1alpha1.ReasonHookSucceeded
+			})
+
+			It("does not stop the NodeReplacement", func() {
+				Expect(stop).To(BeFalse())
+			})
+		})
+	})
+
+	Context("hookJobName", func() {
+		It("appends the hook name to the NodeReplacement name", func() {
+			Expect(hookJobName(nodeReplacement, postDrainHook)).To(Equal(nodeReplacement.GetName() + "-post-drain"))
+		})
+
+		It("truncates long names", func() {
+			nodeReplacement.SetName(strings.Repeat("a", 100))
+			Expect(len(hookJobName(nodeReplacement, postDrainHook))).To(BeNumerically("<=", maxJobNameLength))
+		})
+	})
+})
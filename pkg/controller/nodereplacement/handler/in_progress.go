@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -11,32 +12,56 @@ import (
 
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/kubectl/pkg/drain"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// threadsafeEvictedPods provides a threadsafe []string. This is used to record
-// the succesfully evicted pods through the OnPodDeletedOrEvicted callback
+// errDrainAborted is returned by drainNode when the NodeRollout was aborted
+// while the node was being drained
+var errDrainAborted = errors.New("drain aborted")
+
+// threadsafeEvictedPods provides a threadsafe []types.NamespacedName. This is
+// used to record the succesfully evicted pods through the
+// OnPodDeletedOrEvicted callback
 type threadsafeEvictedPods struct {
 	sync.RWMutex
-	pods []string
+	pods []types.NamespacedName
 }
 
-func (e *threadsafeEvictedPods) writePod(podName string) {
+func (e *threadsafeEvictedPods) writePod(pod *corev1.Pod) {
 	e.Lock()
 	defer e.Unlock()
-	e.pods = append(e.pods, podName)
+	e.pods = append(e.pods, types.NamespacedName{Namespace: pod.GetNamespace(), Name: pod.GetName()})
 }
 
+// readPods returns the names of the evicted pods
 func (e *threadsafeEvictedPods) readPods() []string {
 	e.RLock()
 	defer e.RUnlock()
-	return e.pods
+	names := []string{}
+	for _, pod := range e.pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+// contains returns true if the pod has been evicted
+func (e *threadsafeEvictedPods) contains(pod *corev1.Pod) bool {
+	e.RLock()
+	defer e.RUnlock()
+	for _, evicted := range e.pods {
+		if evicted.Namespace == pod.GetNamespace() && evicted.Name == pod.GetName() {
+			return true
+		}
+	}
+	return false
 }
 
 // threadsafeErrWriter provides a threadsafe map[string]string. This is used to
@@ -64,18 +89,12 @@ func (w *threadsafeErrWriter) Write(data []byte) (int, error) {
 	return fmt.Fprintf(os.Stderr, string(data))
 }
 
-// ReadErrorMap reads the underlying map[string]string removing any keys that
-// match the provided evictedPods
-func (w *threadsafeErrWriter) ReadErrorMap(evictedPods []string) map[string]string {
+// ReadErrorMap returns a copy of the underlying map[string]string. It includes
+// errors for pods that were evicted after being retried
+func (w *threadsafeErrWriter) ReadErrorMap() map[string]string {
 	w.Lock()
-	errorMap := copyMap(w.errMap)
-	w.Unlock()
-	for _, podName := range evictedPods {
-		if _, exists := errorMap[podName]; exists {
-			delete(errorMap, podName)
-		}
-	}
-	return errorMap
+	defer w.Unlock()
+	return copyMap(w.errMap)
 }
 
 // failedPodError implements the Error interface and provides a method read the
@@ -113,11 +132,81 @@ func (f *failedPodError) ReadErrorMap() map[string]string {
 }
 
 // handleInProgress handles a NodeReplacement in the in progress phase. It
-// drains the node specified in the replacement and then marks it completed
+// waits for pods blocking the drain, runs the pre-drain hook, drains the node
+// specified in the replacement and runs the post-drain hook. It then moves the
+// replacement to the terminating phase if a provider is configured. Otherwise
+// it moves on to waiting for a replacement node, or marks it completed. After a
+// failed drain it waits until the next attempt is due before draining again
 func (h *NodeReplacementHandler) handleInProgress(instance *navarchosv1alpha1.NodeReplacement) (*status.Result, error) {
-	// evictedPods captures all pod names that are succesfully evicted
-	evictedPods := threadsafeEvictedPods{
-		pods: []string{},
+	if next := instance.Status.NextAttemptTime; next != nil && time.Until(next.Time) > 0 {
+		return &status.Result{
+			Requeue:       true,
+			RequeueAfter:  time.Until(next.Time),
+			RequeueReason: fmt.Sprintf("waiting until %s to retry draining node", next.Format(time.RFC3339)),
+			RequeueCause:  string(navarchosv1alpha1.ReasonDrainBackoff),
+		}, nil
+	}
+
+	hooks := replacementHooks(instance)
+	result := &status.Result{}
+
+	// Wait for pods that must not be disrupted before running the pre-drain
+	// hook, unless the node has already been drained
+	if !isConditionTrue(instance, navarchosv1alpha1.NodeDrainedType) {
+		settings := h.drainSettings(instance)
+		result.Drain = settings
+		if wait, err := h.waitForBlockingPods(instance, settings, result); wait {
+			return result, err
+		}
+	}
+
+	hookReason, hookErr := h.runHook(instance, preDrainHook, hooks.PreDrain, navarchosv1alpha1.PreDrainHookType)
+	result.PreDrainHookReason, result.PreDrainHookError = hookReason, hookErr
+	if stop, err := stopForHook(result, hookReason, hookErr); stop {
+		return result, err
+	}
+
+	// The node has already been drained if the replacement was waiting for
+	// its post-drain hook
+	if !isConditionTrue(instance, navarchosv1alpha1.NodeDrainedType) {
+		drained, err := h.drainNode(instance, result)
+		if err == errDrainAborted {
+			aborted, err := h.handleAborted(instance)
+			aborted.EvictedPods = result.EvictedPods
+			return aborted, err
+		}
+		if !drained {
+			return result, err
+		}
+	}
+
+	hookReason, hookErr = h.runHook(instance, postDrainHook, hooks.PostDrain, navarchosv1alpha1.PostDrainHookType)
+	result.PostDrainHookReason, result.PostDrainHookError = hookReason, hookErr
+	if stop, err := stopForHook(result, hookReason, hookErr); stop {
+		return result, err
+	}
+
+	if h.provider != nil {
+		terminatingPhase := navarchosv1alpha1.ReplacementPhaseTerminating
+		result.Phase = &terminatingPhase
+	} else {
+		drained := drainedResult(instance)
+		result.Phase = drained.Phase
+		result.CompletionTimestamp = drained.CompletionTimestamp
+	}
+
+	return result, nil
+}
+
+// drainNode drains the node specified in the replacement, recording the
+// evicted and failed pods in the result. It returns true once the node has
+// been drained. A failed drain is recorded in the result by drainErrorResult.
+// If the NodeRollout is aborted while the pods are being evicted the drain is
+// cancelled and errDrainAborted is returned
+func (h *NodeReplacementHandler) drainNode(instance *navarchosv1alpha1.NodeReplacement, result *status.Result) (bool, error) {
+	// evictedPods captures all pods that are succesfully evicted
+	evictedPods := &threadsafeEvictedPods{
+		pods: []types.NamespacedName{},
 	}
 
 	// errOut captures any errors thrown when a PDB blocks eviction, that
@@ -126,35 +215,70 @@ func (h *NodeReplacementHandler) handleInProgress(instance *navarchosv1alpha1.No
 		errMap: make(map[string]string),
 	}
 
+	settings := h.drainSettings(instance)
+	result.Drain = settings
+
+	ctx, cancel := h.abortContext(instance)
+	defer cancel()
+
 	helper := &drain.Helper{
-		Client:              h.k8sClient,
-		IgnoreAllDaemonSets: h.ignoreAllDaemonSets,
-		Timeout:             h.drainTimeout,
-		GracePeriodSeconds:  int(h.evictionGracePeriod / time.Second),
-		DeleteLocalData:     h.deleteLocalData,
-		Force:               h.forcePodDeletion,
+		Client:              &abortableClient{Interface: h.k8sClient, ctx: ctx},
+		IgnoreAllDaemonSets: *settings.IgnoreAllDaemonSets,
+		Timeout:             settings.Timeout.Duration,
+		GracePeriodSeconds:  int(settings.EvictionGracePeriod.Duration / time.Second),
+		DeleteLocalData:     *settings.DeleteLocalData,
+		Force:               *settings.ForcePodDeletion,
 		Out:                 os.Stdout,
 		ErrOut:              errOut,
 
 		OnPodDeletedOrEvicted: func(pod *corev1.Pod, _ bool) {
-			evictedPods.writePod(pod.GetName())
+			evictedPods.writePod(pod)
+			metrics.EvictedPods.WithLabelValues(pod.GetNamespace()).Inc()
 		},
 	}
 
-	err := runNodeDrain(helper, instance.Spec.NodeName)
+	pods, err := podsForDeletion(helper, instance.Spec.NodeName)
+	if err != nil {
+		_, err = h.drainErrorResult(instance, result, err)
+		return false, err
+	}
+	waves, err := evictionWaves(pods, settings.EvictionOrder)
+	if err != nil {
+		_, err = h.drainErrorResult(instance, result, err)
+		return false, err
+	}
+	if settings.EvictionOrder != navarchosv1alpha1.EvictionOrderNone {
+		result.EvictionWaves = evictionWavesStatus(instance, waves)
+	}
+
+	// Only the first wave is evicted, the NodeReplacement is requeued to evict
+	// the remaining waves so that the progress is recorded in its status
+	wavePods := []corev1.Pod{}
+	if len(waves) > 0 {
+		wavePods = waves[0].pods
+	}
+
+	if instance.Status.DrainStartTimestamp == nil {
+		now := metav1.Now()
+		result.DrainStartTimestamp = &now
+	}
+	err = runNodeDrain(helper, wavePods)
+	result.EvictedPods = evictedPods.readPods()
+	if ctx.Err() != nil {
+		return false, errDrainAborted
+	}
 	if err != nil {
 		e, ok := err.(failedPodError)
 		if !ok {
 			// the type assertion has failed for some reason...  it shouldn't
 			// have, bail..
-			return &status.Result{
-				EvictedPods: evictedPods.readPods(),
-			}, fmt.Errorf("error draining node: %v", err)
+			_, err = h.drainErrorResult(instance, result, err)
+			return false, err
 		}
 
 		// If there is an error for any pod in both the aggregate error and
 		// collected from ErrOut, ErrOut takes precedence
-		outMap := errOut.ReadErrorMap(evictedPods.readPods())
+		outMap := errOut.ReadErrorMap()
 		aggregateMap := e.ReadErrorMap()
 
 		for k, v := range outMap {
@@ -163,16 +287,23 @@ func (h *NodeReplacementHandler) handleInProgress(instance *navarchosv1alpha1.No
 
 		// outMap now contains the union of the two maps, with k,v from outMap
 		// overwriting those of aggregate
-		podReasons := buildPodReasonsFromMap(aggregateMap)
+		result.FailedPods = failedPodReasons(wavePods, evictedPods, aggregateMap)
+		countFailedPods(result.FailedPods)
 
-		return &status.Result{
-			EvictedPods: evictedPods.readPods(),
-			FailedPods:  podReasons,
-		}, fmt.Errorf("error draining node: %v", err.Error())
+		_, err = h.drainErrorResult(instance, result, err)
+		return false, err
 	}
 
-	outMap := errOut.ReadErrorMap(evictedPods.readPods())
-	podReasons := buildPodReasonsFromMap(outMap)
+	result.FailedPods = failedPodReasons(wavePods, evictedPods, errOut.ReadErrorMap())
+	countFailedPods(result.FailedPods)
+
+	completeNextWave(result.EvictionWaves)
+	if len(waves) > 1 {
+		result.Requeue = true
+		result.RequeueReason = fmt.Sprintf("evicted pods with eviction order %d, %d wave(s) remaining", waves[0].order, len(waves)-1)
+		result.RequeueCause = string(navarchosv1alpha1.ReasonEvictionWaves)
+		return false, nil
+	}
 
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		return h.addCompletedLabel(instance.Spec.NodeName)
@@ -180,42 +311,159 @@ func (h *NodeReplacementHandler) handleInProgress(instance *navarchosv1alpha1.No
 	if retryErr != nil {
 		log.Printf("error labeling node as completed: %v", retryErr)
 		if !apierrors.IsNotFound(retryErr) {
-			return &status.Result{
-				EvictedPods: evictedPods.readPods(),
-				FailedPods:  podReasons,
-			}, retryErr
+			return false, retryErr
 		}
 	}
 
-	completedPhase := navarchosv1alpha1.ReplacementPhaseCompleted
-	completedTime := metav1.Now()
+	observeDrainDuration(instance, result, "success")
+	result.NodeDrainedReason = navarchosv1alpha1.ReasonNodeDrained
+	return true, nil
+}
+
+// drainSettings returns the settings used to drain the node of the
+// NodeReplacement. Any setting not set in the ReplacementSpec defaults to the
+// controller's
+func (h *NodeReplacementHandler) drainSettings(instance *navarchosv1alpha1.NodeReplacement) *navarchosv1alpha1.DrainSpec {
+	settings := &navarchosv1alpha1.DrainSpec{}
+	if instance.Spec.ReplacementSpec.Drain != nil {
+		settings = instance.Spec.ReplacementSpec.Drain.DeepCopy()
+	}
 
-	return &status.Result{
-		EvictedPods:         evictedPods.readPods(),
-		FailedPods:          podReasons,
-		Phase:               &completedPhase,
-		CompletionTimestamp: &completedTime,
-	}, nil
+	if settings.EvictionGracePeriod == nil {
+		settings.EvictionGracePeriod = &metav1.Duration{Duration: h.evictionGracePeriod}
+	}
+	if settings.Timeout == nil {
+		settings.Timeout = &metav1.Duration{Duration: h.drainTimeout}
+	}
+	if settings.IgnoreAllDaemonSets == nil {
+		settings.IgnoreAllDaemonSets = boolPtr(h.ignoreAllDaemonSets)
+	}
+	if settings.DeleteLocalData == nil {
+		settings.DeleteLocalData = boolPtr(h.deleteLocalData)
+	}
+	if settings.ForcePodDeletion == nil {
+		settings.ForcePodDeletion = boolPtr(h.forcePodDeletion)
+	}
+	if settings.EvictionOrder == "" {
+		settings.EvictionOrder = h.evictionOrder
+	}
+	if settings.WaitForJobs == nil {
+		settings.WaitForJobs = boolPtr(h.waitForJobs)
+	}
+	if settings.BlockingPodsTimeout == nil {
+		settings.BlockingPodsTimeout = &metav1.Duration{Duration: h.blockingPodsTimeout}
+	}
+	if settings.PDBPolicy == "" {
+		settings.PDBPolicy = h.pdbPolicy
+	}
+	if settings.CapacityPolicy == "" {
+		settings.CapacityPolicy = h.capacityPolicy
+	}
+	return settings
 }
 
-// runNodeDrain uses the kubectl drain package to drain a node. If any pods
-// fail, it unpacks the individual error from the aggregate and returns them
-// individually
-func runNodeDrain(drainer *drain.Helper, nodeName string) error {
-	list, errs := drainer.GetPodsForDeletion(nodeName)
-	if errs != nil {
-		return utilerrors.NewAggregate(errs)
+// drainErrorResult records a failed attempt to drain the node in the result.
+// If the NodeReplacement has used up its attempts, or has been draining the
+// node for longer than allowed, the result moves it to the failed phase.
+// Otherwise the result requeues the NodeReplacement once the backoff for the
+// attempt has passed. No error is returned so that the drain is not retried
+// any sooner
+func (h *NodeReplacementHandler) drainErrorResult(instance *navarchosv1alpha1.NodeReplacement, result *status.Result, err error) (*status.Result, error) {
+	now := metav1.Now()
+	result.Attempts = instance.Status.Attempts + 1
+	result.LastAttemptTime = &now
+
+	if h.maxDrainAttempts > 0 && result.Attempts >= h.maxDrainAttempts {
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		result.Phase = &failedPhase
+		result.NodeDrainedError = fmt.Errorf("giving up after %d attempt(s): %v", result.Attempts, err)
+		result.NodeDrainedReason = navarchosv1alpha1.ReasonDrainFailed
+		observeDrainDuration(instance, result, "error")
+		return result, nil
 	}
-	if warnings := list.Warnings(); warnings != "" {
-		log.Printf("Warning: %s\n", warnings)
+
+	cordonTime := instance.Status.CordonTimestamp
+	if h.maxDrainDuration > 0 && cordonTime != nil && time.Since(cordonTime.Time) >= h.maxDrainDuration {
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		result.Phase = &failedPhase
+		result.NodeDrainedError = fmt.Errorf("giving up after draining for longer than %v: %v", h.maxDrainDuration, err)
+		result.NodeDrainedReason = navarchosv1alpha1.ReasonDrainFailed
+		observeDrainDuration(instance, result, "error")
+		return result, nil
 	}
 
-	if err := drainer.DeleteOrEvictPods(list.Pods()); err != nil {
+	backoff := h.drainBackoffFor(result.Attempts)
+	nextAttempt := metav1.NewTime(now.Add(backoff))
+	result.NextAttemptTime = &nextAttempt
+	result.Requeue = true
+	result.RequeueAfter = backoff
+	result.RequeueReason = fmt.Sprintf("error draining node, retrying in %v: %v", backoff, err)
+	result.RequeueCause = string(navarchosv1alpha1.ReasonErrorDrainingNode)
+	result.NodeDrainedError = err
+	result.NodeDrainedReason = navarchosv1alpha1.ReasonErrorDrainingNode
+	return result, nil
+}
+
+// drainBackoffFor returns how long to wait before retrying a drain after the
+// given number of failed attempts. The backoff doubles with every attempt, up
+// to the maximum backoff
+func (h *NodeReplacementHandler) drainBackoffFor(attempts int) time.Duration {
+	backoff := h.drainBackoff
+	for i := 1; i < attempts && backoff < h.maxDrainBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > h.maxDrainBackoff {
+		return h.maxDrainBackoff
+	}
+	return backoff
+}
+
+// observeDrainDuration records the time since the first eviction wave of the
+// node started in the drain duration metric. It is called once per
+// NodeReplacement, when the final wave has been evicted or the controller has
+// given up draining the node
+func observeDrainDuration(instance *navarchosv1alpha1.NodeReplacement, result *status.Result, outcome string) {
+	start := instance.Status.DrainStartTimestamp
+	if start == nil {
+		start = result.DrainStartTimestamp
+	}
+	// The controller may give up before evicting any pods
+	if start == nil {
+		return
+	}
+	metrics.DrainDuration.WithLabelValues(outcome).Observe(time.Since(start.Time).Seconds())
+}
+
+// countFailedPods increments the failed pods metric for each failed pod
+func countFailedPods(failed []navarchosv1alpha1.PodReason) {
+	for _, pod := range failed {
+		metrics.FailedPods.WithLabelValues(pod.Namespace).Inc()
+	}
+}
+
+// runNodeDrain uses the kubectl drain package to delete or evict the pods of a
+// node. If any pods fail, it unpacks the individual error from the aggregate
+// and returns them individually
+func runNodeDrain(drainer *drain.Helper, pods []corev1.Pod) error {
+	if err := drainer.DeleteOrEvictPods(pods); err != nil {
 		return failedPodError{err: err}
 	}
 	return nil
 }
 
+// podsForDeletion returns the pods that must be deleted or evicted to drain
+// the node
+func podsForDeletion(drainer *drain.Helper, nodeName string) ([]corev1.Pod, error) {
+	list, errs := drainer.GetPodsForDeletion(nodeName)
+	if errs != nil {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	if warnings := list.Warnings(); warnings != "" {
+		log.Printf("Warning: %s\n", warnings)
+	}
+	return list.Pods(), nil
+}
+
 // addCompletedLabel adds a label to the node with the passed name. The label
 // has format: navarchos.pusher.com/drain-completed:YYYY-MM-DDThhmmss
 func (h *NodeReplacementHandler) addCompletedLabel(nodeName string) error {
@@ -245,14 +493,22 @@ func parsePodName(err string) (string, error) {
 	return split[1], nil
 }
 
-// buildPodReasonsFromMap returns the supplied map as type PodReasons. It trims
-// leading and trailing whitespace on the error
-func buildPodReasonsFromMap(inMap map[string]string) []navarchosv1alpha1.PodReason {
+// failedPodReasons returns the pods that were not evicted and have an error in
+// the supplied map as type PodReasons. The errors only name the pods, so the
+// namespaces are taken from the drained pods. It trims leading and trailing
+// whitespace on the error
+func failedPodReasons(pods []corev1.Pod, evicted *threadsafeEvictedPods, errMap map[string]string) []navarchosv1alpha1.PodReason {
 	reasons := []navarchosv1alpha1.PodReason{}
-	for name, err := range inMap {
+	for i := range pods {
+		pod := &pods[i]
+		err, failed := errMap[pod.GetName()]
+		if !failed || evicted.contains(pod) {
+			continue
+		}
 		reasons = append(reasons, navarchosv1alpha1.PodReason{
-			Name:   name,
-			Reason: strings.TrimSpace(err),
+			Name:      pod.GetName(),
+			Namespace: pod.GetNamespace(),
+			Reason:    strings.TrimSpace(err),
 		})
 	}
 
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -8,6 +9,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
 	"github.com/pusher/navarchos/test/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -172,30 +174,78 @@ var _ = Describe("in progress replacement handler", func() {
 
 	})
 
-	Context("buildPodReasonsFromMap", func() {
+	Context("failedPodReasons", func() {
 		var podReasons []navarchosv1alpha1.PodReason
-		var input map[string]string
+		var evicted *threadsafeEvictedPods
+		var pods []corev1.Pod
+
+		var newPod = func(namespace, name string) corev1.Pod {
+			return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		}
+
+		BeforeEach(func() {
+			evicted = &threadsafeEvictedPods{}
+			pods = []corev1.Pod{
+				newPod("default", "pod-1"),
+				newPod("default", "pod-2"),
+				newPod("default", "pod-3"),
+			}
+		})
 
 		JustBeforeEach(func() {
-			input = map[string]string{
+			input := map[string]string{
 				"pod-1": "pdb disruption",
-				"pod-2": "global timeout",
+				"pod-2": " global timeout\n",
 			}
-			podReasons = buildPodReasonsFromMap(input)
+			podReasons = failedPodReasons(pods, evicted, input)
 		})
 
-		It("returns the input map formatted as PodReasons", func() {
+		It("returns the pods with an error as PodReasons", func() {
 			Expect(podReasons).To(ConsistOf([]navarchosv1alpha1.PodReason{
 				{
-					Name:   "pod-1",
-					Reason: "pdb disruption",
+					Name:      "pod-1",
+					Namespace: "default",
+					Reason:    "pdb disruption",
 				},
 				{
-					Name:   "pod-2",
-					Reason: "global timeout",
+					Name:      "pod-2",
+					Namespace: "default",
+					Reason:    "global timeout",
 				},
 			}))
 		})
+
+		Context("when a pod with an error was evicted after being retried", func() {
+			BeforeEach(func() {
+				pod := newPod("default", "pod-1")
+				evicted.writePod(&pod)
+			})
+
+			It("does not return the pod", func() {
+				Expect(podReasons).To(ConsistOf(navarchosv1alpha1.PodReason{
+					Name:      "pod-2",
+					Namespace: "default",
+					Reason:    "global timeout",
+				}))
+			})
+		})
+
+		Context("when a pod of the same name in another namespace was evicted", func() {
+			BeforeEach(func() {
+				pods = append(pods, newPod("other", "pod-1"))
+				pod := newPod("other", "pod-1")
+				evicted.writePod(&pod)
+			})
+
+			It("returns the pod that was not evicted with its namespace", func() {
+				Expect(podReasons).To(ContainElement(navarchosv1alpha1.PodReason{
+					Name:      "pod-1",
+					Namespace: "default",
+					Reason:    "pdb disruption",
+				}))
+				Expect(podReasons).To(HaveLen(2))
+			})
+		})
 	})
 
 	Context("threadsafeEvictedPods", func() {
@@ -209,7 +259,7 @@ var _ = Describe("in progress replacement handler", func() {
 				for i := 0; i < 10; i++ {
 					wg.Add(1)
 					go func(i int, wait *sync.WaitGroup) {
-						evictedPods.writePod(fmt.Sprintf("pod_%d", i))
+						evictedPods.writePod(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod_%d", i)}})
 						wait.Done()
 					}(i, &wg)
 				}
@@ -232,7 +282,7 @@ var _ = Describe("in progress replacement handler", func() {
 		var errWriter threadsafeErrWriter
 		var errMap map[string]string
 
-		Context("when handling concurrent writes with no evictedPods supplied", func() {
+		Context("when handling concurrent writes", func() {
 			JustBeforeEach(func() {
 				var wg sync.WaitGroup
 
@@ -246,7 +296,7 @@ var _ = Describe("in progress replacement handler", func() {
 				}
 				wg.Wait()
 
-				errMap = errWriter.ReadErrorMap([]string{})
+				errMap = errWriter.ReadErrorMap()
 			})
 
 			It("does not drop a write", func() {
@@ -257,30 +307,260 @@ var _ = Describe("in progress replacement handler", func() {
 				Expect(errMap).To(Equal(testMap))
 			})
 		})
+	})
 
-		Context("when handling concurrent writes with evictedPods supplied", func() {
-			JustBeforeEach(func() {
-				var wg sync.WaitGroup
+	Context("drainErrorResult", func() {
+		var result *status.Result
+		var resultErr error
+		var drainErr = errors.New("pod \"pod-1\" could not be evicted")
 
-				for i := 0; i < 10; i++ {
-					wg.Add(1)
-					go func(i int, wait *sync.WaitGroup) {
-						str := fmt.Sprintf("unfortunately \"pod_%d\" could not be evicted, goodbye.\n", i)
-						errWriter.Write([]byte(str))
-						wait.Done()
-					}(i, &wg)
-				}
-				wg.Wait()
+		BeforeEach(func() {
+			backoff := 30 * time.Second
+			opts.MaxDrainAttempts = intPtr(3)
+			opts.DrainBackoff = &backoff
+			nodeReplacement.Status.Attempts = 1
+		})
 
-				errMap = errWriter.ReadErrorMap([]string{"pod_0"})
+		JustBeforeEach(func() {
+			result, resultErr = h.drainErrorResult(nodeReplacement, &status.Result{}, drainErr)
+		})
+
+		Context("when the NodeReplacement has attempts left", func() {
+			It("increments the attempts", func() {
+				Expect(result.Attempts).To(Equal(2))
 			})
 
-			It("does not drop a write", func() {
-				testMap := map[string]string{}
-				for i := 1; i < 10; i++ {
-					testMap[fmt.Sprintf("pod_%d", i)] = fmt.Sprintf("unfortunately \"pod_%d\" could not be evicted, goodbye.\n", i)
+			It("does not set the phase", func() {
+				Expect(result.Phase).To(BeNil())
+			})
+
+			It("sets the NodeDrainedReason to ErrorDrainingNode", func() {
+				Expect(result.NodeDrainedReason).To(Equal(navarchosv1alpha1.ReasonErrorDrainingNode))
+			})
+
+			It("records the time of the attempt", func() {
+				Expect(result.LastAttemptTime).ToNot(BeNil())
+			})
+
+			It("requeues the NodeReplacement after the backoff", func() {
+				Expect(result.Requeue).To(BeTrue())
+				Expect(result.RequeueAfter).To(Equal(time.Minute))
+			})
+
+			It("sets the NextAttemptTime to after the backoff", func() {
+				Expect(result.NextAttemptTime.Sub(result.LastAttemptTime.Time)).To(Equal(time.Minute))
+			})
+
+			It("does not return an error", func() {
+				Expect(resultErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the NodeReplacement has used up its attempts", func() {
+			BeforeEach(func() {
+				nodeReplacement.Status.Attempts = 2
+			})
+
+			It("sets the phase to Failed", func() {
+				failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+				Expect(result.Phase).To(Equal(&failedPhase))
+			})
+
+			It("sets the NodeDrainedReason to DrainFailed", func() {
+				Expect(result.NodeDrainedReason).To(Equal(navarchosv1alpha1.ReasonDrainFailed))
+			})
+
+			It("does not return an error", func() {
+				Expect(resultErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the node has been draining for longer than the maximum duration", func() {
+			BeforeEach(func() {
+				duration := 30 * time.Minute
+				opts.MaxDrainDuration = &duration
+				cordonTime := metav1.NewTime(time.Now().Add(-time.Hour))
+				nodeReplacement.Status.CordonTimestamp = &cordonTime
+			})
+
+			It("sets the phase to Failed", func() {
+				failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+				Expect(result.Phase).To(Equal(&failedPhase))
+			})
+
+			It("does not return an error", func() {
+				Expect(resultErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when attempts are unlimited", func() {
+			BeforeEach(func() {
+				opts.MaxDrainAttempts = intPtr(0)
+				nodeReplacement.Status.Attempts = 100
+			})
+
+			It("does not set the phase", func() {
+				Expect(result.Phase).To(BeNil())
+			})
+
+			It("caps the backoff at the maximum backoff", func() {
+				Expect(result.RequeueAfter).To(Equal(10 * time.Minute))
+			})
+		})
+	})
+
+	Context("drainSettings", func() {
+		var settings *navarchosv1alpha1.DrainSpec
+
+		JustBeforeEach(func() {
+			settings = h.drainSettings(nodeReplacement)
+		})
+
+		Context("when the NodeReplacement does not override the drain settings", func() {
+			It("uses the controller's drain settings", func() {
+				Expect(settings).To(Equal(&navarchosv1alpha1.DrainSpec{
+					EvictionGracePeriod: &metav1.Duration{Duration: 5 * time.Second},
+					Timeout:             &metav1.Duration{Duration: 15 * time.Minute},
+					IgnoreAllDaemonSets: boolPtr(true),
+					DeleteLocalData:     boolPtr(true),
+					ForcePodDeletion:    boolPtr(false),
+					EvictionOrder:       navarchosv1alpha1.EvictionOrderNone,
+					WaitForJobs:         boolPtr(false),
+					BlockingPodsTimeout: &metav1.Duration{},
+					PDBPolicy:           navarchosv1alpha1.PDBPolicyIgnore,
+					CapacityPolicy:      navarchosv1alpha1.CapacityPolicyIgnore,
+				}))
+			})
+		})
+
+		Context("when the NodeReplacement overrides some of the drain settings", func() {
+			BeforeEach(func() {
+				nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{
+					Timeout:          &metav1.Duration{Duration: 2 * time.Hour},
+					ForcePodDeletion: boolPtr(true),
 				}
-				Expect(errMap).To(Equal(testMap))
+			})
+
+			It("uses the overridden settings with the controller's defaults", func() {
+				Expect(settings).To(Equal(&navarchosv1alpha1.DrainSpec{
+					EvictionGracePeriod: &metav1.Duration{Duration: 5 * time.Second},
+					Timeout:             &metav1.Duration{Duration: 2 * time.Hour},
+					IgnoreAllDaemonSets: boolPtr(true),
+					DeleteLocalData:     boolPtr(true),
+					ForcePodDeletion:    boolPtr(true),
+					EvictionOrder:       navarchosv1alpha1.EvictionOrderNone,
+					WaitForJobs:         boolPtr(false),
+					BlockingPodsTimeout: &metav1.Duration{},
+					PDBPolicy:           navarchosv1alpha1.PDBPolicyIgnore,
+					CapacityPolicy:      navarchosv1alpha1.CapacityPolicyIgnore,
+				}))
+			})
+
+			It("does not modify the NodeReplacement", func() {
+				Expect(nodeReplacement.Spec.ReplacementSpec.Drain.EvictionGracePeriod).To(BeNil())
+			})
+		})
+	})
+
+	Context("drainBackoffFor", func() {
+		BeforeEach(func() {
+			backoff := 30 * time.Second
+			maxBackoff := 5 * time.Minute
+			opts.DrainBackoff = &backoff
+			opts.MaxDrainBackoff = &maxBackoff
+		})
+
+		It("uses the initial backoff after the first attempt", func() {
+			Expect(h.drainBackoffFor(1)).To(Equal(30 * time.Second))
+		})
+
+		It("doubles the backoff after every attempt", func() {
+			Expect(h.drainBackoffFor(3)).To(Equal(2 * time.Minute))
+		})
+
+		It("caps the backoff at the maximum backoff", func() {
+			Expect(h.drainBackoffFor(10)).To(Equal(5 * time.Minute))
+		})
+	})
+
+	Context("handleInProgress", func() {
+		Context("when the next attempt to drain the node is not due", func() {
+			var result *status.Result
+			var handleErr error
+
+			BeforeEach(func() {
+				nextAttempt := metav1.NewTime(time.Now().Add(time.Hour))
+				nodeReplacement.Status.NextAttemptTime = &nextAttempt
+			})
+
+			JustBeforeEach(func() {
+				result, handleErr = h.handleInProgress(nodeReplacement)
+			})
+
+			It("does not evict any pods", func() {
+				m.Consistently(pod1, consistentlyTimeout).Should(utils.WithField("ObjectMeta.DeletionTimestamp", BeNil()))
+			})
+
+			It("requeues the NodeReplacement until the next attempt", func() {
+				Expect(result.Requeue).To(BeTrue())
+				Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+			})
+
+			It("should not return an error", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when a pod on the node must not be disrupted", func() {
+			var result *status.Result
+			var handleErr error
+
+			BeforeEach(func() {
+				m.Update(pod1, func(obj utils.Object) utils.Object {
+					pod, _ := obj.(*corev1.Pod)
+					pod.SetAnnotations(map[string]string{navarchosv1alpha1.DoNotDisruptAnnotation: "true"})
+					return pod
+				}, timeout).Should(Succeed())
+			})
+
+			JustBeforeEach(func() {
+				result, handleErr = h.handleInProgress(nodeReplacement)
+			})
+
+			It("does not evict any pods", func() {
+				m.Consistently(pod2, consistentlyTimeout).Should(utils.WithField("ObjectMeta.DeletionTimestamp", BeNil()))
+			})
+
+			It("lists the pod as blocking the drain", func() {
+				Expect(result.BlockingPods).To(ConsistOf(navarchosv1alpha1.PodReason{
+					Name:      pod1.GetName(),
+					Namespace: pod1.GetNamespace(),
+					Reason:    "pod has the navarchos.pusher.com/do-not-disrupt annotation",
+				}))
+			})
+
+			It("requeues the NodeReplacement", func() {
+				Expect(result.Requeue).To(BeTrue())
+				Expect(result.RequeueAfter).To(Equal(blockingPodsRequeuePeriod))
+				Expect(handleErr).ToNot(HaveOccurred())
+			})
+
+			Context("and the blocking pods timeout has passed", func() {
+				BeforeEach(func() {
+					cordonTime := metav1.NewTime(time.Now().Add(-time.Hour))
+					nodeReplacement.Status.CordonTimestamp = &cordonTime
+					nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{
+						BlockingPodsTimeout: &metav1.Duration{Duration: time.Minute},
+					}
+				})
+
+				It("stops waiting for the blocking pods", func() {
+					waitResult := &status.Result{}
+					wait, err := h.waitForBlockingPods(nodeReplacement, h.drainSettings(nodeReplacement), waitResult)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(wait).To(BeFalse())
+					Expect(waitResult.BlockingPods).To(HaveLen(1))
+				})
 			})
 		})
 	})
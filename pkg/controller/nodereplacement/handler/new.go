@@ -7,19 +7,69 @@ import (
 
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/pkg/metrics"
+	"github.com/pusher/navarchos/pkg/schedule"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// handleNew handles a NodeReplacement in the New phase
-func (h *NodeReplacementHandler) handleNew(instance *navarchosv1alpha1.NodeReplacement) (*status.Result, error) {
-	requeue, reason := h.shouldRequeueReplacement(instance)
+// handleNew handles a NodeReplacement in the New phase. The rollout is the
+// NodeRollout controlling the NodeReplacement, if any
+func (h *NodeReplacementHandler) handleNew(instance *navarchosv1alpha1.NodeReplacement, rollout *navarchosv1alpha1.NodeRollout) (*status.Result, error) {
+	if rollout != nil && rollout.Spec.Paused {
+		return &status.Result{
+			Requeue:       true,
+			RequeueReason: fmt.Sprintf("NodeRollout \"%s\" is paused", rollout.GetName()),
+			RequeueCause:  string(navarchosv1alpha1.ReasonRolloutPaused),
+			PausedReason:  navarchosv1alpha1.ReasonRolloutPaused,
+		}, nil
+	}
+	if rollout != nil && rollout.Status.Phase == navarchosv1alpha1.RolloutPhaseFailed {
+		return &status.Result{
+			Requeue:       true,
+			RequeueReason: fmt.Sprintf("NodeRollout \"%s\" has failed", rollout.GetName()),
+			RequeueCause:  string(navarchosv1alpha1.ReasonRolloutFailed),
+		}, nil
+	}
+	if rollout != nil && len(rollout.Spec.MaintenanceWindows) > 0 {
+		now := time.Now()
+		open, next, err := schedule.Windows(rollout.Spec.MaintenanceWindows, now)
+		if err != nil {
+			return &status.Result{}, fmt.Errorf("error evaluating maintenance windows of NodeRollout %s: %v", rollout.GetName(), err)
+		}
+		if !open {
+			return outsideMaintenanceWindowResult(rollout, now, next), nil
+		}
+	}
+
+	// Record that the replacement is no longer paused
+	var pausedReason navarchosv1alpha1.NodeReplacementConditionReason
+	if isPaused(instance) {
+		pausedReason = navarchosv1alpha1.ReasonRolloutResumed
+	}
+
+	requeue, reason := h.shouldRequeueReplacement(instance, rollout)
 	if requeue {
 		return &status.Result{
 			Requeue:       true,
 			RequeueReason: reason,
+			RequeueCause:  string(navarchosv1alpha1.ReasonReplacementsInProgress),
+			PausedReason:  pausedReason,
+		}, nil
+	}
+
+	pendingReason, pendingErr := h.checkPendingPods()
+	if pendingErr != nil {
+		return &status.Result{
+			Requeue:                  true,
+			RequeueReason:            pendingErr.Error(),
+			RequeueCause:             string(pendingReason),
+			PausedReason:             pausedReason,
+			PendingPodsClearedReason: pendingReason,
+			PendingPodsClearedError:  pendingErr,
 		}, nil
 	}
 
@@ -37,22 +87,64 @@ func (h *NodeReplacementHandler) handleNew(instance *navarchosv1alpha1.NodeRepla
 		}, nil
 	}
 
-	err = h.cordonNode(node)
-	if err != nil {
-		// TODO: once migrated to kind, test this case.
+	// Nodes excluded after the NodeReplacement was created are never touched
+	if navarchosv1alpha1.HasExcludeAnnotation(node) {
+		abortedPhase := navarchosv1alpha1.ReplacementPhaseAborted
 		return &status.Result{
-			NodeCordonError:  err,
-			NodeCordonReason: navarchosv1alpha1.ReasonErrorCordoningNode,
-		}, fmt.Errorf("error cordoning node: %v", err)
+			Phase:            &abortedPhase,
+			PausedReason:     pausedReason,
+			NodeCordonReason: navarchosv1alpha1.ReasonNodeExcluded,
+			NodeCordonError:  fmt.Errorf("node has the %s annotation", navarchosv1alpha1.ExcludeAnnotation),
+		}, nil
 	}
 
 	result := &status.Result{
-		NodeCordonReason: navarchosv1alpha1.ReasonNodeCordoned,
+		PausedReason:             pausedReason,
+		PendingPodsClearedReason: pendingReason,
+	}
+
+	if stop, err := h.checkDrainFeasible(instance, node, result); stop {
+		return result, err
+	}
+
+	if stop, err := h.checkCapacity(instance, node, result); stop {
+		return result, err
+	}
+
+	approvalReason, approvalErr := h.requestApproval(instance, node)
+	result.ApprovedReason, result.ApprovedError = approvalReason, approvalErr
+	if stop, err := stopForApproval(result, approvalReason, approvalErr); stop {
+		return result, err
+	}
+
+	hookReason, hookErr := h.runHook(instance, preCordonHook, replacementHooks(instance).PreCordon, navarchosv1alpha1.PreCordonHookType)
+	result.PreCordonHookReason, result.PreCordonHookError = hookReason, hookErr
+	if stop, err := stopForHook(result, hookReason, hookErr); stop {
+		return result, err
+	}
+
+	cordoned, err := h.cordonNode(node)
+	if err != nil {
+		// TODO: once migrated to kind, test this case.
+		result.NodeCordonError = err
+		result.NodeCordonReason = navarchosv1alpha1.ReasonErrorCordoningNode
+		return result, fmt.Errorf("error cordoning node: %v", err)
 	}
-	result.NodePods, result.IgnoredPods, err = h.getPodsOnNode(node)
+
+	cordonTime := metav1.Now()
+	result.NodeCordonReason = navarchosv1alpha1.ReasonNodeCordoned
+	result.CordonTimestamp = &cordonTime
+	result.CordonedByController = cordoned
+	result.Drain = h.drainSettings(instance)
+	if instance.Spec.ReplacementSpec.WaitForReplacement != nil {
+		result.ReplacementNodeSelector = replacementNodeSelector(instance, node)
+	}
+	pods, ignoredPods, err := h.getPodsOnNode(node)
+	result.NodePods, result.IgnoredPods = podNames(pods), ignoredPods
 	if err != nil {
 		return result, fmt.Errorf("error listing pods on node %s: %v", node.GetName(), err)
 	}
+	countIgnoredPods(ignoredPods)
 
 	inProgress := navarchosv1alpha1.ReplacementPhaseInProgress
 	result.Phase = &inProgress
@@ -60,48 +152,179 @@ func (h *NodeReplacementHandler) handleNew(instance *navarchosv1alpha1.NodeRepla
 	return result, nil
 }
 
+// outsideMaintenanceWindowResult returns a Result requeuing a NodeReplacement
+// until the next maintenance window of its NodeRollout starts. If no window
+// starts again the NodeReplacement is requeued with the default backoff
+func outsideMaintenanceWindowResult(rollout *navarchosv1alpha1.NodeRollout, now, next time.Time) *status.Result {
+	if next.IsZero() {
+		return &status.Result{
+			Requeue:       true,
+			RequeueReason: fmt.Sprintf("no maintenance window of NodeRollout \"%s\" starts within the next %d years", rollout.GetName(), schedule.MaxSearchYears),
+			RequeueCause:  string(navarchosv1alpha1.ReasonOutsideMaintenanceWindow),
+		}
+	}
+	return &status.Result{
+		Requeue:       true,
+		RequeueAfter:  next.Sub(now),
+		RequeueReason: fmt.Sprintf("outside the maintenance windows of NodeRollout \"%s\", the next window starts at %s", rollout.GetName(), next.UTC().Format(time.RFC3339)),
+		RequeueCause:  string(navarchosv1alpha1.ReasonOutsideMaintenanceWindow),
+	}
+}
+
 // shouldRequeueReplacement determines if a replacement should be requeued, it
 // returns true with a reason as to why the replacement should be requeued.
-// Otherwise it returns false along with an empty reason string
-func (h *NodeReplacementHandler) shouldRequeueReplacement(instance *navarchosv1alpha1.NodeReplacement) (bool, string) {
+// Otherwise it returns false along with an empty reason string. The rollout is
+// the NodeRollout controlling the replacement, or nil if there is none
+func (h *NodeReplacementHandler) shouldRequeueReplacement(instance *navarchosv1alpha1.NodeReplacement, rollout *navarchosv1alpha1.NodeRollout) (bool, string) {
 	replacements := &navarchosv1alpha1.NodeReplacementList{}
 	err := h.client.List(context.Background(), replacements)
 	if err != nil {
 		return true, fmt.Sprintf("failed to list NodeReplacements: %v", err)
 	}
 
+	maxUnavailable, err := getMaxUnavailable(instance, rollout, replacements)
+	if err != nil {
+		return true, fmt.Sprintf("failed to determine maxUnavailable: %v", err)
+	}
+
+	unavailable := 0
 	for _, replacement := range replacements.Items {
-		if replacement.Status.Phase == navarchosv1alpha1.ReplacementPhaseCompleted {
+		// The instance is unavailable itself while its pre-cordon hook runs
+		if replacement.GetUID() == instance.GetUID() {
+			continue
+		}
+		if isFinished(&replacement) {
 			continue
 		}
 		if *replacement.Spec.ReplacementSpec.Priority > *instance.Spec.ReplacementSpec.Priority {
 			reason := fmt.Sprintf("NodeReplacement \"%s\" has a higher priority", replacement.GetName())
 			return true, reason
 		}
-		if replacement.Status.Phase == navarchosv1alpha1.ReplacementPhaseInProgress {
+		if !isUnavailable(&replacement) {
+			continue
+		}
+		if !inSameTier(instance, &replacement) {
 			reason := fmt.Sprintf("NodeReplacement \"%s\" is already in-progress", replacement.GetName())
 			return true, reason
 		}
+		unavailable++
+	}
+	if unavailable >= maxUnavailable {
+		return true, fmt.Sprintf("%d NodeReplacement(s) of the same priority are already in-progress, maxUnavailable is %d", unavailable, maxUnavailable)
+	}
+
+	return false, ""
+}
+
+// getMaxUnavailable returns the number of NodeReplacements in the same tier as
+// the instance that may be in progress at the same time. This is determined by
+// the strategy of the NodeRollout controlling the instance. If the instance is
+// not controlled by a NodeRollout, or the NodeRollout does not set a strategy,
+// it returns 1
+func getMaxUnavailable(instance *navarchosv1alpha1.NodeReplacement, rollout *navarchosv1alpha1.NodeRollout, replacements *navarchosv1alpha1.NodeReplacementList) (int, error) {
+	if rollout == nil || rollout.Spec.Strategy == nil || rollout.Spec.Strategy.MaxUnavailable == nil {
+		return 1, nil
+	}
+
+	total := 0
+	for _, replacement := range replacements.Items {
+		if inSameTier(instance, &replacement) {
+			total++
+		}
+	}
+
+	maxUnavailable, err := intstr.GetValueFromIntOrPercent(rollout.Spec.Strategy.MaxUnavailable, total, false)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maxUnavailable for NodeRollout %s: %v", rollout.GetName(), err)
+	}
+	if maxUnavailable < 1 {
+		// Always allow progress
+		maxUnavailable = 1
+	}
+	return maxUnavailable, nil
+}
+
+// getRollout gets the NodeRollout controlling the NodeReplacement. If the
+// NodeReplacement is not controlled by a NodeRollout, or the NodeRollout no
+// longer exists, it returns nil
+func (h *NodeReplacementHandler) getRollout(instance *navarchosv1alpha1.NodeReplacement) (*navarchosv1alpha1.NodeRollout, error) {
+	owner := metav1.GetControllerOf(instance)
+	if owner == nil || owner.Kind != "NodeRollout" {
+		return nil, nil
 	}
 
-	pods := corev1.PodList{}
-	err = h.client.List(context.Background(), &pods, client.MatchingFields{"status.phase": "Pending"})
+	rollout := &navarchosv1alpha1.NodeRollout{}
+	err := h.client.Get(context.Background(), client.ObjectKey{Name: owner.Name}, rollout)
 	if err != nil {
-		return true, fmt.Sprintf("failed to list pending pods: %v", err)
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting NodeRollout %s: %v", owner.Name, err)
+	}
+
+	// The UID differs if the NodeRollout has been recreated with the same name
+	if rollout.GetUID() != owner.UID {
+		return nil, nil
 	}
-	if len(pods.Items) != 0 {
-		podNames := []string{}
-		for _, pod := range pods.Items {
-			podNames = append(podNames, pod.GetName())
+	return rollout, nil
+}
+
+// isPaused returns true if the NodeReplacement has a Paused condition with
+// status True
+func isPaused(instance *navarchosv1alpha1.NodeReplacement) bool {
+	for _, cond := range instance.Status.Conditions {
+		if cond.Type == navarchosv1alpha1.ReplacementPausedType {
+			return cond.Status == corev1.ConditionTrue
 		}
-		return true, fmt.Sprintf("requeuing as there are pending pod(s): %v", podNames)
 	}
+	return false
+}
 
-	return false, ""
+// inSameTier returns true if both NodeReplacements are controlled by the same
+// NodeRollout and have the same priority
+func inSameTier(a, b *navarchosv1alpha1.NodeReplacement) bool {
+	ownerA := metav1.GetControllerOf(a)
+	ownerB := metav1.GetControllerOf(b)
+	if ownerA == nil || ownerB == nil || ownerA.UID != ownerB.UID {
+		return false
+	}
+	return *a.Spec.ReplacementSpec.Priority == *b.Spec.ReplacementSpec.Priority
+}
+
+// isFinished returns true if the NodeReplacement will not be processed any
+// further. Failed NodeReplacements leave their node cordoned but do not block
+// other NodeReplacements
+func isFinished(replacement *navarchosv1alpha1.NodeReplacement) bool {
+	switch replacement.Status.Phase {
+	case navarchosv1alpha1.ReplacementPhaseCompleted,
+		navarchosv1alpha1.ReplacementPhaseAborted,
+		navarchosv1alpha1.ReplacementPhaseFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnavailable returns true if the node of the NodeReplacement has been
+// cordoned and the NodeReplacement has not yet completed, or the
+// NodeReplacement is running its pre-cordon hook
+func isUnavailable(replacement *navarchosv1alpha1.NodeReplacement) bool {
+	switch replacement.Status.Phase {
+	case navarchosv1alpha1.ReplacementPhaseNew:
+		return isHookRunning(replacement, navarchosv1alpha1.PreCordonHookType)
+	case navarchosv1alpha1.ReplacementPhaseInProgress,
+		navarchosv1alpha1.ReplacementPhaseTerminating,
+		navarchosv1alpha1.ReplacementPhaseWaitingForReplacement:
+		return true
+	default:
+		return false
+	}
 }
 
-// cordonNode cordons a node
-func (h *NodeReplacementHandler) cordonNode(node *corev1.Node) error {
+// cordonNode cordons a node. It returns true if the node was schedulable
+// before, so that only nodes cordoned by the controller are uncordoned again
+func (h *NodeReplacementHandler) cordonNode(node *corev1.Node) (bool, error) {
+	schedulable := !node.Spec.Unschedulable
 	now := metav1.Now()
 	node.Spec.Unschedulable = true
 	node, updated := addTaint(node, &corev1.Taint{
@@ -109,16 +332,16 @@ func (h *NodeReplacementHandler) cordonNode(node *corev1.Node) error {
 		Effect:    corev1.TaintEffect("NoSchedule"),
 		TimeAdded: &now,
 	})
-	if !updated {
-		return nil
+	if !updated && !schedulable {
+		return false, nil
 	}
 
 	err := h.client.Update(context.Background(), node)
 	if err != nil {
-		return fmt.Errorf("error updating the node: %v", err)
+		return false, fmt.Errorf("error updating the node: %v", err)
 	}
 
-	return nil
+	return schedulable, nil
 }
 
 // addTaint tries to add a taint to the annotations list. It returns a new copy
@@ -146,10 +369,9 @@ func addTaint(node *corev1.Node, taint *corev1.Taint) (*corev1.Node, bool) {
 	return newNode, true
 }
 
-// getPodsOnNode lists the pods present on a node. It returns a []string
-// consisting of all pods on the node and a []PodReason consisitng of all pods
-// that are to be ignored
-func (h *NodeReplacementHandler) getPodsOnNode(node *corev1.Node) ([]string, []navarchosv1alpha1.PodReason, error) {
+// getPodsOnNode lists the pods present on a node. It returns all pods on the
+// node and a []PodReason consisitng of all pods that are to be ignored
+func (h *NodeReplacementHandler) getPodsOnNode(node *corev1.Node) ([]corev1.Pod, []navarchosv1alpha1.PodReason, error) {
 	podList := &corev1.PodList{}
 	err := func() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
@@ -157,23 +379,36 @@ func (h *NodeReplacementHandler) getPodsOnNode(node *corev1.Node) ([]string, []n
 		return h.client.List(ctx, podList, client.MatchingField("spec.nodeName", node.GetName()))
 	}()
 	if err != nil {
-		return []string{}, []navarchosv1alpha1.PodReason{}, err
+		return []corev1.Pod{}, []navarchosv1alpha1.PodReason{}, err
 	}
 
-	nodePods := []string{}
 	ignoredPods := []navarchosv1alpha1.PodReason{}
 	for _, pod := range podList.Items {
-		nodePods = append(nodePods, pod.GetName())
-
 		ownerRefs := pod.GetOwnerReferences()
 		for _, ref := range ownerRefs {
 			if ref.Kind == "DaemonSet" {
-				ignoredPods = append(ignoredPods, navarchosv1alpha1.PodReason{Name: pod.GetName(), Reason: "pod owned by a DaemonSet"})
+				ignoredPods = append(ignoredPods, navarchosv1alpha1.PodReason{Name: pod.GetName(), Namespace: pod.GetNamespace(), Reason: "pod owned by a DaemonSet"})
 			}
 		}
 	}
 
-	return nodePods, ignoredPods, nil
+	return podList.Items, ignoredPods, nil
+}
+
+// podNames returns the names of the pods
+func podNames(pods []corev1.Pod) []string {
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.GetName())
+	}
+	return names
+}
+
+// countIgnoredPods increments the ignored pods metric for each ignored pod
+func countIgnoredPods(ignored []navarchosv1alpha1.PodReason) {
+	for _, pod := range ignored {
+		metrics.IgnoredPods.WithLabelValues(pod.Namespace).Inc()
+	}
 }
 
 // getNode gets the node specified in a NodeReplacement. If it does not exist it
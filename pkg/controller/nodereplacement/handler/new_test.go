@@ -13,6 +13,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -55,6 +57,22 @@ var _ = Describe("new node replacement handler", func() {
 		return pod
 	}
 
+	var setPodUnschedulable = func(since time.Time) func(utils.Object) utils.Object {
+		return func(obj utils.Object) utils.Object {
+			pod, _ := obj.(*corev1.Pod)
+			pod.Status.Phase = corev1.PodPending
+			pod.Status.Conditions = []corev1.PodCondition{
+				{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             corev1.PodReasonUnschedulable,
+					LastTransitionTime: metav1.NewTime(since),
+				},
+			}
+			return pod
+		}
+	}
+
 	var setPodSucceeded = func(obj utils.Object) utils.Object {
 		pod, _ := obj.(*corev1.Pod)
 		pod.Status.Phase = corev1.PodSucceeded
@@ -133,7 +151,9 @@ var _ = Describe("new node replacement handler", func() {
 		var reason string
 
 		JustBeforeEach(func() {
-			requeue, reason = h.shouldRequeueReplacement(nodeReplacement)
+			rollout, err := h.getRollout(nodeReplacement)
+			Expect(err).ToNot(HaveOccurred())
+			requeue, reason = h.shouldRequeueReplacement(nodeReplacement, rollout)
 		})
 		Context("if a another NodeReplacement is higher priority", func() {
 			var highPriorityNR *navarchosv1alpha1.NodeReplacement
@@ -179,6 +199,21 @@ var _ = Describe("new node replacement handler", func() {
 				})
 			})
 
+			Context("and the higher priority replacement has failed", func() {
+				BeforeEach(func() {
+					m.Update(highPriorityNR, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+						nr.Status.Phase = navarchosv1alpha1.ReplacementPhaseFailed
+						return nr
+					}, timeout).Should(Succeed())
+					m.Eventually(highPriorityNR, timeout).Should(utils.WithField("Status.Phase", Equal(navarchosv1alpha1.ReplacementPhaseFailed)))
+				})
+
+				It("sets requeue to false", func() {
+					Expect(requeue).To(BeFalse())
+				})
+			})
+
 		})
 
 		Context("if a another NodeReplacement is in Phase InProgress", func() {
@@ -199,17 +234,113 @@ var _ = Describe("new node replacement handler", func() {
 			})
 		})
 
-		Context("if a pod is pending", func() {
+		Context("if the NodeReplacements are controlled by a NodeRollout", func() {
+			var rollout *navarchosv1alpha1.NodeRollout
+
+			var newSiblingNR = func(name string, priority int, phase navarchosv1alpha1.NodeReplacementPhase) *navarchosv1alpha1.NodeReplacement {
+				nr := utils.ExampleNodeReplacement.DeepCopy()
+				nr.SetName(name)
+				nr.Spec.ReplacementSpec.Priority = intPtr(priority)
+				nr.Status.Phase = phase
+				nr.SetOwnerReferences([]metav1.OwnerReference{utils.GetOwnerReferenceForNodeRollout(rollout)})
+				return nr
+			}
+
 			BeforeEach(func() {
-				m.UpdateStatus(pod1, setPodPending, timeout).Should(Succeed())
+				rollout = utils.ExampleNodeRollout.DeepCopy()
+				rollout.Spec.Strategy = &navarchosv1alpha1.RolloutStrategy{
+					MaxUnavailable: intstrPtr(intstr.FromInt(2)),
+				}
+				m.Create(rollout).Should(Succeed())
+
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.SetOwnerReferences([]metav1.OwnerReference{
+						utils.GetOwnerReferenceForNodeRollout(rollout),
+						utils.GetOwnerReferenceForNode(workerNode1),
+					})
+					return nr
+				}, timeout).Should(Succeed())
+
+				m.Create(newSiblingNR("sibling-1", 0, navarchosv1alpha1.ReplacementPhaseInProgress)).Should(Succeed())
 			})
 
-			It("sets requeue to true", func() {
-				Expect(requeue).To(BeTrue())
+			AfterEach(func() {
+				utils.DeleteAll(cfg, timeout,
+					&navarchosv1alpha1.NodeRolloutList{},
+				)
 			})
 
-			It("requeues the NodeReplacement", func() {
-				Expect(reason).To(Equal("requeuing as there are pending pod(s): [pod-1]"))
+			Context("and fewer than maxUnavailable replacements of the same priority are in progress", func() {
+				It("sets requeue to false", func() {
+					Expect(requeue).To(BeFalse())
+				})
+
+				It("does not set the reason string", func() {
+					Expect(reason).To(Equal(""))
+				})
+			})
+
+			Context("and maxUnavailable replacements of the same priority are in progress", func() {
+				BeforeEach(func() {
+					m.Create(newSiblingNR("sibling-2", 0, navarchosv1alpha1.ReplacementPhaseWaitingForReplacement)).Should(Succeed())
+				})
+
+				It("sets requeue to true", func() {
+					Expect(requeue).To(BeTrue())
+				})
+
+				It("requeues the NodeReplacement", func() {
+					Expect(reason).To(Equal("2 NodeReplacement(s) of the same priority are already in-progress, maxUnavailable is 2"))
+				})
+			})
+
+			Context("and maxUnavailable is a percentage", func() {
+				BeforeEach(func() {
+					m.Update(rollout, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+						nr.Spec.Strategy.MaxUnavailable = intstrPtr(intstr.FromString("50%"))
+						return nr
+					}, timeout).Should(Succeed())
+					m.Create(newSiblingNR("sibling-2", 0, navarchosv1alpha1.ReplacementPhaseNew)).Should(Succeed())
+					m.Create(newSiblingNR("sibling-3", 0, navarchosv1alpha1.ReplacementPhaseNew)).Should(Succeed())
+				})
+
+				It("allows the percentage of the tier to be in progress", func() {
+					Expect(requeue).To(BeFalse())
+				})
+
+				Context("and the percentage of the tier is already in progress", func() {
+					BeforeEach(func() {
+						m.Update(rollout, func(obj utils.Object) utils.Object {
+							nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+							nr.Spec.Strategy.MaxUnavailable = intstrPtr(intstr.FromString("25%"))
+							return nr
+						}, timeout).Should(Succeed())
+					})
+
+					It("sets requeue to true", func() {
+						Expect(requeue).To(BeTrue())
+					})
+
+					It("requeues the NodeReplacement", func() {
+						Expect(reason).To(Equal("1 NodeReplacement(s) of the same priority are already in-progress, maxUnavailable is 1"))
+					})
+				})
+			})
+
+			Context("and a replacement of a different priority is in progress", func() {
+				BeforeEach(func() {
+					m.Create(newSiblingNR("lower-priority", -10, navarchosv1alpha1.ReplacementPhaseInProgress)).Should(Succeed())
+				})
+
+				It("sets requeue to true", func() {
+					Expect(requeue).To(BeTrue())
+				})
+
+				It("requeues the NodeReplacement", func() {
+					Expect(reason).To(Equal("NodeReplacement \"lower-priority\" is already in-progress"))
+				})
 			})
 		})
 
@@ -224,6 +355,79 @@ var _ = Describe("new node replacement handler", func() {
 		})
 	})
 
+	Context("checkPendingPods", func() {
+		var reason navarchosv1alpha1.NodeReplacementConditionReason
+		var pendingErr error
+
+		JustBeforeEach(func() {
+			reason, pendingErr = h.checkPendingPods()
+		})
+
+		Context("if no pod is pending", func() {
+			It("sets the reason to NoPendingPods", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonNoPendingPods))
+				Expect(pendingErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("if a pod is pending but has not been marked unschedulable", func() {
+			BeforeEach(func() {
+				m.UpdateStatus(pod1, setPodPending, timeout).Should(Succeed())
+			})
+
+			It("does not block the NodeReplacement", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonNoPendingPods))
+			})
+		})
+
+		Context("if a pod is unschedulable", func() {
+			BeforeEach(func() {
+				m.UpdateStatus(pod1, setPodUnschedulable(time.Now().Add(-time.Hour)), timeout).Should(Succeed())
+			})
+
+			It("lists the pod", func() {
+				Expect(reason).To(Equal(navarchosv1alpha1.ReasonPendingPods))
+				Expect(pendingErr).To(MatchError("1 pod(s) have been unschedulable for longer than 0s: default/pod-1"))
+			})
+
+			Context("and the pod has been unschedulable for less than the grace period", func() {
+				BeforeEach(func() {
+					grace := 2 * time.Hour
+					opts.PendingPodsGracePeriod = &grace
+				})
+
+				It("does not block the NodeReplacement", func() {
+					Expect(reason).To(Equal(navarchosv1alpha1.ReasonNoPendingPods))
+				})
+			})
+
+			Context("and the pod is in an ignored namespace", func() {
+				BeforeEach(func() {
+					opts.PendingPodsIgnoreNamespaces = []string{"kube-system", "default"}
+				})
+
+				It("does not block the NodeReplacement", func() {
+					Expect(reason).To(Equal(navarchosv1alpha1.ReasonNoPendingPods))
+				})
+			})
+
+			Context("and the pod matches the ignore selector", func() {
+				BeforeEach(func() {
+					m.Update(pod1, func(obj utils.Object) utils.Object {
+						pod, _ := obj.(*corev1.Pod)
+						pod.SetLabels(map[string]string{"batch": "true"})
+						return pod
+					}, timeout).Should(Succeed())
+					opts.PendingPodsIgnoreSelector = labels.SelectorFromSet(labels.Set{"batch": "true"})
+				})
+
+				It("does not block the NodeReplacement", func() {
+					Expect(reason).To(Equal(navarchosv1alpha1.ReasonNoPendingPods))
+				})
+			})
+		})
+	})
+
 	Context("getNode", func() {
 		var node *corev1.Node
 		var exists bool
@@ -296,9 +500,10 @@ var _ = Describe("new node replacement handler", func() {
 	})
 
 	Context("cordonNode", func() {
+		var cordoned bool
 		var err error
 		JustBeforeEach(func() {
-			err = h.cordonNode(workerNode1)
+			cordoned, err = h.cordonNode(workerNode1)
 		})
 
 		Context("when called on an uncordoned node", func() {
@@ -314,6 +519,10 @@ var _ = Describe("new node replacement handler", func() {
 					)))
 			})
 
+			It("reports that the controller cordoned the node", func() {
+				Expect(cordoned).To(BeTrue())
+			})
+
 			It("should not return an error", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
@@ -346,6 +555,10 @@ var _ = Describe("new node replacement handler", func() {
 				))
 			})
 
+			It("reports that the controller did not cordon the node", func() {
+				Expect(cordoned).To(BeFalse())
+			})
+
 			It("should not return an error", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
@@ -358,7 +571,9 @@ var _ = Describe("new node replacement handler", func() {
 		var err error
 
 		JustBeforeEach(func() {
-			nodePods, ignoredPods, err = h.getPodsOnNode(workerNode1)
+			var pods []corev1.Pod
+			pods, ignoredPods, err = h.getPodsOnNode(workerNode1)
+			nodePods = podNames(pods)
 		})
 
 		Context("when a pod is owned by a daemonset", func() {
@@ -383,7 +598,7 @@ var _ = Describe("new node replacement handler", func() {
 
 			It("sets IgnoredPods", func() {
 				Expect(ignoredPods).To(ConsistOf(
-					navarchosv1alpha1.PodReason{Name: "pod-2", Reason: "pod owned by a DaemonSet"}))
+					navarchosv1alpha1.PodReason{Name: "pod-2", Namespace: "default", Reason: "pod owned by a DaemonSet"}))
 			})
 
 			It("should not return an error", func() {
@@ -415,7 +630,9 @@ var _ = Describe("new node replacement handler", func() {
 		var handleErr error
 
 		JustBeforeEach(func() {
-			result, handleErr = h.handleNew(nodeReplacement)
+			rollout, err := h.getRollout(nodeReplacement)
+			Expect(err).ToNot(HaveOccurred())
+			result, handleErr = h.handleNew(nodeReplacement, rollout)
 		})
 
 		It("should not set any Pods in the EvictedPods field", func() {
@@ -435,5 +652,328 @@ var _ = Describe("new node replacement handler", func() {
 		It("should not return an error", func() {
 			Expect(handleErr).ToNot(HaveOccurred())
 		})
+
+		Context("and the node has the exclude annotation", func() {
+			BeforeEach(func() {
+				m.Update(workerNode1, func(obj utils.Object) utils.Object {
+					node, _ := obj.(*corev1.Node)
+					node.SetAnnotations(map[string]string{navarchosv1alpha1.ExcludeAnnotation: "true"})
+					return node
+				}, timeout).Should(Succeed())
+			})
+
+			It("sets the phase to Aborted", func() {
+				Expect(result.Phase).ToNot(BeNil())
+				Expect(*result.Phase).To(Equal(navarchosv1alpha1.ReplacementPhaseAborted))
+			})
+
+			It("sets the NodeCordonReason to NodeExcluded", func() {
+				Expect(result.NodeCordonReason).To(Equal(navarchosv1alpha1.ReasonNodeExcluded))
+				Expect(result.NodeCordonError).To(MatchError("node has the navarchos.pusher.com/exclude annotation"))
+			})
+
+			It("does not cordon the node", func() {
+				m.Consistently(workerNode1, consistentlyTimeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+			})
+
+			It("should not return an error", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+			})
+		})
+
+		It("should not check the PodDisruptionBudgets by default", func() {
+			Expect(result.DrainFeasibleReason).To(BeEmpty())
+			Expect(result.DisruptionBlockedPods).To(BeEmpty())
+		})
+
+		Context("and the PDB policy is not Ignore", func() {
+			BeforeEach(func() {
+				nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{PDBPolicy: navarchosv1alpha1.PDBPolicyWait}
+			})
+
+			It("should set the DrainFeasibleReason to DrainFeasible", func() {
+				Expect(result.DrainFeasibleReason).To(Equal(navarchosv1alpha1.ReasonDrainFeasible))
+				Expect(result.DisruptionBlockedPods).To(BeEmpty())
+			})
+		})
+
+		Context("and a PodDisruptionBudget allows no disruptions of a pod on the node", func() {
+			BeforeEach(func() {
+				pdb := utils.ExamplePodDisruptionBudget.DeepCopy()
+				m.Create(pdb).Should(Succeed())
+				m.UpdateStatus(pdb, func(obj utils.Object) utils.Object {
+					p, _ := obj.(*policyv1beta1.PodDisruptionBudget)
+					p.Status.PodDisruptionsAllowed = int32(0)
+					p.Status.CurrentHealthy = int32(1)
+					p.Status.DesiredHealthy = int32(1)
+					return p
+				}, timeout).Should(Succeed())
+				m.Update(pod1, func(obj utils.Object) utils.Object {
+					pod, _ := obj.(*corev1.Pod)
+					pod.SetLabels(pdb.Spec.Selector.MatchLabels)
+					return pod
+				}, timeout).Should(Succeed())
+			})
+
+			It("cordons the node by default without checking the PodDisruptionBudget", func() {
+				Expect(result.DisruptionBlockedPods).To(BeEmpty())
+				Expect(result.DrainFeasibleReason).To(BeEmpty())
+				Expect(result.NodeCordonReason).To(Equal(navarchosv1alpha1.ReasonNodeCordoned))
+			})
+
+			Context("and the PDB policy is Wait", func() {
+				BeforeEach(func() {
+					nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{PDBPolicy: navarchosv1alpha1.PDBPolicyWait}
+				})
+
+				It("reports the blocked pod", func() {
+					Expect(result.DisruptionBlockedPods).To(ConsistOf(navarchosv1alpha1.PodReason{
+						Name:      pod1.GetName(),
+						Namespace: pod1.GetNamespace(),
+						Reason:    "PodDisruptionBudget example-pdb allows no disruptions, 1 of 1 desired pods are healthy",
+					}))
+					Expect(result.DrainFeasibleReason).To(Equal(navarchosv1alpha1.ReasonDrainBlocked))
+					Expect(result.DrainFeasibleError).To(MatchError("PodDisruptionBudgets would block the eviction of 1 pod(s): pod-1"))
+				})
+
+				It("requeues the NodeReplacement without cordoning the node", func() {
+					Expect(result.Requeue).To(BeTrue())
+					Expect(result.RequeueAfter).To(Equal(pdbRequeuePeriod))
+					Expect(result.NodeCordonReason).To(BeEmpty())
+					m.Consistently(workerNode1, consistentlyTimeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+				})
+			})
+
+			Context("and the PDB policy is Skip", func() {
+				BeforeEach(func() {
+					nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{PDBPolicy: navarchosv1alpha1.PDBPolicySkip}
+				})
+
+				It("sets the phase to Aborted", func() {
+					Expect(result.Phase).ToNot(BeNil())
+					Expect(*result.Phase).To(Equal(navarchosv1alpha1.ReplacementPhaseAborted))
+				})
+			})
+
+			Context("and the PDB policy is Fail", func() {
+				BeforeEach(func() {
+					nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{PDBPolicy: navarchosv1alpha1.PDBPolicyFail}
+				})
+
+				It("sets the phase to Failed without cordoning the node", func() {
+					Expect(result.Phase).ToNot(BeNil())
+					Expect(*result.Phase).To(Equal(navarchosv1alpha1.ReplacementPhaseFailed))
+					m.Consistently(workerNode1, consistentlyTimeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+				})
+			})
+		})
+
+		Context("and the pods on the node would not fit on the remaining nodes", func() {
+			// The nodes created for the tests are never Ready
+
+			It("reports the unschedulable pods", func() {
+				Expect(result.UnschedulablePods).To(ConsistOf(
+					navarchosv1alpha1.PodReason{Name: pod1.GetName(), Namespace: pod1.GetNamespace(), Reason: "0/1 nodes are available: 1 node(s) were not ready."},
+					navarchosv1alpha1.PodReason{Name: pod2.GetName(), Namespace: pod2.GetNamespace(), Reason: "0/1 nodes are available: 1 node(s) were not ready."},
+					navarchosv1alpha1.PodReason{Name: pod3.GetName(), Namespace: pod3.GetNamespace(), Reason: "0/1 nodes are available: 1 node(s) were not ready."},
+				))
+				Expect(result.PodsSchedulableReason).To(Equal(navarchosv1alpha1.ReasonPodsUnschedulable))
+			})
+
+			It("cordons the node by default", func() {
+				Expect(result.NodeCordonReason).To(Equal(navarchosv1alpha1.ReasonNodeCordoned))
+			})
+
+			Context("and the capacity policy is Wait", func() {
+				BeforeEach(func() {
+					nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{CapacityPolicy: navarchosv1alpha1.CapacityPolicyWait}
+				})
+
+				It("requeues the NodeReplacement without cordoning the node", func() {
+					Expect(result.Requeue).To(BeTrue())
+					Expect(result.RequeueAfter).To(Equal(capacityRequeuePeriod))
+					m.Consistently(workerNode1, consistentlyTimeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+				})
+			})
+
+			Context("and the capacity policy is Fail", func() {
+				BeforeEach(func() {
+					nodeReplacement.Spec.ReplacementSpec.Drain = &navarchosv1alpha1.DrainSpec{CapacityPolicy: navarchosv1alpha1.CapacityPolicyFail}
+				})
+
+				It("sets the phase to Failed", func() {
+					Expect(result.Phase).ToNot(BeNil())
+					Expect(*result.Phase).To(Equal(navarchosv1alpha1.ReplacementPhaseFailed))
+				})
+			})
+
+			Context("and another node is Ready with room for the pods", func() {
+				BeforeEach(func() {
+					m.UpdateStatus(workerNode2, func(obj utils.Object) utils.Object {
+						node, _ := obj.(*corev1.Node)
+						node.Status.Conditions = []corev1.NodeCondition{
+							{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+						}
+						return node
+					}, timeout).Should(Succeed())
+				})
+
+				It("sets the PodsSchedulableReason to PodsSchedulable", func() {
+					Expect(result.UnschedulablePods).To(BeEmpty())
+					Expect(result.PodsSchedulableReason).To(Equal(navarchosv1alpha1.ReasonPodsSchedulable))
+				})
+			})
+		})
+
+		Context("and the NodeRollout controlling it is paused", func() {
+			var rollout *navarchosv1alpha1.NodeRollout
+
+			BeforeEach(func() {
+				rollout = utils.ExampleNodeRollout.DeepCopy()
+				rollout.Spec.Paused = true
+				m.Create(rollout).Should(Succeed())
+
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.SetOwnerReferences([]metav1.OwnerReference{
+						utils.GetOwnerReferenceForNodeRollout(rollout),
+						utils.GetOwnerReferenceForNode(workerNode1),
+					})
+					return nr
+				}, timeout).Should(Succeed())
+			})
+
+			AfterEach(func() {
+				utils.DeleteAll(cfg, timeout,
+					&navarchosv1alpha1.NodeRolloutList{},
+				)
+			})
+
+			It("requeues the NodeReplacement", func() {
+				Expect(result.Requeue).To(BeTrue())
+				Expect(result.RequeueReason).To(Equal("NodeRollout \"example\" is paused"))
+				Expect(result.RequeueCause).To(Equal("RolloutPaused"))
+			})
+
+			It("sets the PausedReason to RolloutPaused", func() {
+				Expect(result.PausedReason).To(Equal(navarchosv1alpha1.ReasonRolloutPaused))
+			})
+
+			It("does not cordon the node", func() {
+				m.Consistently(workerNode1, consistentlyTimeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+			})
+
+			It("should not return an error", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+			})
+
+			Context("and the NodeRollout is resumed", func() {
+				BeforeEach(func() {
+					m.Update(rollout, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+						nr.Spec.Paused = false
+						return nr
+					}, timeout).Should(Succeed())
+
+					nodeReplacement.Status.Conditions = []navarchosv1alpha1.NodeReplacementCondition{
+						{
+							Type:   navarchosv1alpha1.ReplacementPausedType,
+							Status: corev1.ConditionTrue,
+							Reason: navarchosv1alpha1.ReasonRolloutPaused,
+						},
+					}
+				})
+
+				It("cordons the node", func() {
+					Expect(result.NodeCordonReason).To(Equal(navarchosv1alpha1.ReasonNodeCordoned))
+				})
+
+				It("sets the PausedReason to RolloutResumed", func() {
+					Expect(result.PausedReason).To(Equal(navarchosv1alpha1.ReasonRolloutResumed))
+				})
+			})
+		})
+
+		Context("and the NodeRollout controlling it has maintenance windows", func() {
+			var rollout *navarchosv1alpha1.NodeRollout
+			var windows []navarchosv1alpha1.MaintenanceWindow
+
+			BeforeEach(func() {
+				rollout = utils.ExampleNodeRollout.DeepCopy()
+				rollout.Spec.MaintenanceWindows = windows
+				m.Create(rollout).Should(Succeed())
+
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.SetOwnerReferences([]metav1.OwnerReference{
+						utils.GetOwnerReferenceForNodeRollout(rollout),
+						utils.GetOwnerReferenceForNode(workerNode1),
+					})
+					return nr
+				}, timeout).Should(Succeed())
+			})
+
+			AfterEach(func() {
+				utils.DeleteAll(cfg, timeout,
+					&navarchosv1alpha1.NodeRolloutList{},
+				)
+			})
+
+			Context("and a window is open", func() {
+				BeforeEach(func() {
+					windows = []navarchosv1alpha1.MaintenanceWindow{
+						{
+							Schedule: "* * * * *",
+							Duration: metav1.Duration{Duration: time.Hour},
+						},
+					}
+				})
+
+				It("cordons the node", func() {
+					Expect(result.NodeCordonReason).To(Equal(navarchosv1alpha1.ReasonNodeCordoned))
+				})
+			})
+
+			Context("and no window is open", func() {
+				BeforeEach(func() {
+					// Opens for the first minute of every year only
+					windows = []navarchosv1alpha1.MaintenanceWindow{
+						{
+							Schedule: "0 0 1 1 *",
+							Duration: metav1.Duration{Duration: time.Minute},
+						},
+					}
+				})
+
+				It("requeues the NodeReplacement until the next window starts", func() {
+					Expect(result.Requeue).To(BeTrue())
+					Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+					Expect(result.RequeueReason).To(ContainSubstring("the next window starts at"))
+				})
+
+				It("does not cordon the node", func() {
+					m.Consistently(workerNode1, consistentlyTimeout).Should(utils.WithField("Spec.Unschedulable", BeFalse()))
+				})
+
+				It("should not return an error", func() {
+					Expect(handleErr).ToNot(HaveOccurred())
+				})
+			})
+
+			Context("and a window is invalid", func() {
+				BeforeEach(func() {
+					windows = []navarchosv1alpha1.MaintenanceWindow{
+						{
+							Schedule: "not a schedule",
+							Duration: metav1.Duration{Duration: time.Hour},
+						},
+					}
+				})
+
+				It("returns an error", func() {
+					Expect(handleErr).To(HaveOccurred())
+				})
+			})
+		})
 	})
 })
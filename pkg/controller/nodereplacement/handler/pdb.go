@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pdbRequeuePeriod determines how often a NodeReplacement waiting for
+// PodDisruptionBudgets to allow its node to be drained is requeued,
+// PodDisruptionBudgets are not watched by the controller
+const pdbRequeuePeriod = 30 * time.Second
+
+// checkDrainFeasible updates the result with the pods on the node whose
+// eviction would be blocked by a PodDisruptionBudget and returns true if the
+// NodeReplacement cannot continue. What happens to a NodeReplacement with
+// blocked pods is determined by the PDB policy of its drain settings. The
+// PodDisruptionBudgets are not checked at all when the policy is Ignore
+func (h *NodeReplacementHandler) checkDrainFeasible(instance *navarchosv1alpha1.NodeReplacement, node *corev1.Node, result *status.Result) (bool, error) {
+	policy := h.drainSettings(instance).PDBPolicy
+	if policy == navarchosv1alpha1.PDBPolicyIgnore {
+		return false, nil
+	}
+
+	blocked, err := h.disruptionBlockedPods(node)
+	if err != nil {
+		result.DrainFeasibleReason = navarchosv1alpha1.ReasonErrorCheckingDrain
+		result.DrainFeasibleError = fmt.Errorf("error checking PodDisruptionBudgets: %v", err)
+		return true, result.DrainFeasibleError
+	}
+	result.DisruptionBlockedPods = blocked
+	if len(blocked) == 0 {
+		result.DrainFeasibleReason = navarchosv1alpha1.ReasonDrainFeasible
+		return false, nil
+	}
+
+	names := []string{}
+	for _, pod := range blocked {
+		names = append(names, pod.Name)
+	}
+	result.DrainFeasibleReason = navarchosv1alpha1.ReasonDrainBlocked
+	result.DrainFeasibleError = fmt.Errorf("PodDisruptionBudgets would block the eviction of %d pod(s): %s", len(blocked), strings.Join(names, ", "))
+
+	switch policy {
+	case navarchosv1alpha1.PDBPolicyWait:
+		result.Requeue = true
+		result.RequeueAfter = pdbRequeuePeriod
+		result.RequeueReason = result.DrainFeasibleError.Error()
+		result.RequeueCause = string(result.DrainFeasibleReason)
+		return true, nil
+	case navarchosv1alpha1.PDBPolicySkip:
+		abortedPhase := navarchosv1alpha1.ReplacementPhaseAborted
+		result.Phase = &abortedPhase
+		return true, nil
+	case navarchosv1alpha1.PDBPolicyFail:
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		result.Phase = &failedPhase
+		return true, nil
+	default:
+		result.DrainFeasibleReason = navarchosv1alpha1.ReasonErrorCheckingDrain
+		result.DrainFeasibleError = fmt.Errorf("invalid PDB policy %q", policy)
+		return true, result.DrainFeasibleError
+	}
+}
+
+// disruptionBlockedPods returns the pods on the node that would be evicted
+// when it is drained but are covered by a PodDisruptionBudget that currently
+// allows no disruptions. The pods are sorted by name
+func (h *NodeReplacementHandler) disruptionBlockedPods(node *corev1.Node) ([]navarchosv1alpha1.PodReason, error) {
+	podList := &corev1.PodList{}
+	err := h.client.List(context.Background(), podList, client.MatchingField("spec.nodeName", node.GetName()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+
+	pdbs := make(map[string][]policyv1beta1.PodDisruptionBudget)
+	blocked := []navarchosv1alpha1.PodReason{}
+	for _, pod := range podList.Items {
+		if !IsEvictable(&pod) {
+			continue
+		}
+
+		namespacePDBs, ok := pdbs[pod.GetNamespace()]
+		if !ok {
+			pdbList := &policyv1beta1.PodDisruptionBudgetList{}
+			err = h.client.List(context.Background(), pdbList, client.InNamespace(pod.GetNamespace()))
+			if err != nil {
+				return nil, fmt.Errorf("error listing PodDisruptionBudgets in namespace %s: %v", pod.GetNamespace(), err)
+			}
+			namespacePDBs = pdbList.Items
+			pdbs[pod.GetNamespace()] = namespacePDBs
+		}
+
+		if reason, ok := DisruptionBlockedReason(&pod, namespacePDBs); ok {
+			blocked = append(blocked, navarchosv1alpha1.PodReason{Name: pod.GetName(), Namespace: pod.GetNamespace(), Reason: reason})
+		}
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].Name < blocked[j].Name
+	})
+	return blocked, nil
+}
+
+// DisruptionBlockedReason returns why the eviction of the pod would be
+// blocked by one of the PodDisruptionBudgets, or false if it would not. The
+// PodDisruptionBudgets must be in the namespace of the pod
+func DisruptionBlockedReason(pod *corev1.Pod, pdbs []policyv1beta1.PodDisruptionBudget) (string, bool) {
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		// An empty selector matches no pods, as in the disruption controller
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.GetLabels())) {
+			continue
+		}
+		if pdb.Status.PodDisruptionsAllowed < 1 {
+			return fmt.Sprintf("PodDisruptionBudget %s allows no disruptions, %d of %d desired pods are healthy", pdb.GetName(), pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy), true
+		}
+	}
+	return "", false
+}
+
+// IsEvictable returns true if the pod would be evicted when its node is
+// drained. Finished pods, mirror pods and pods owned by a DaemonSet are not
+func IsEvictable(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, ok := pod.GetAnnotations()[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	for _, ref := range pod.GetOwnerReferences() {
+		if ref.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkPendingPods returns the reason for the PendingPodsCleared condition and,
+// if pending pods block NodeReplacements from starting, an error listing them.
+// Pending pods only block NodeReplacements once the scheduler has marked them
+// unschedulable for longer than the grace period, unless they are in an
+// ignored namespace or match the ignore selector
+func (h *NodeReplacementHandler) checkPendingPods() (navarchosv1alpha1.NodeReplacementConditionReason, error) {
+	pods := corev1.PodList{}
+	err := h.client.List(context.Background(), &pods, client.MatchingFields{"status.phase": "Pending"})
+	if err != nil {
+		return navarchosv1alpha1.ReasonErrorListingPendingPods, fmt.Errorf("failed to list pending pods: %v", err)
+	}
+
+	now := time.Now()
+	blocking := []string{}
+	for _, pod := range pods.Items {
+		if h.isBlockingPendingPod(&pod, now) {
+			blocking = append(blocking, fmt.Sprintf("%s/%s", pod.GetNamespace(), pod.GetName()))
+		}
+	}
+	if len(blocking) == 0 {
+		return navarchosv1alpha1.ReasonNoPendingPods, nil
+	}
+	sort.Strings(blocking)
+	return navarchosv1alpha1.ReasonPendingPods, fmt.Errorf("%d pod(s) have been unschedulable for longer than %v: %s", len(blocking), h.pendingPodsGracePeriod, strings.Join(blocking, ", "))
+}
+
+// isBlockingPendingPod returns true if the pending pod should block
+// NodeReplacements from starting
+func (h *NodeReplacementHandler) isBlockingPendingPod(pod *corev1.Pod, now time.Time) bool {
+	for _, namespace := range h.pendingPodsIgnoreNamespaces {
+		if pod.GetNamespace() == namespace {
+			return false
+		}
+	}
+	if h.pendingPodsIgnoreSelector.Matches(labels.Set(pod.GetLabels())) {
+		return false
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return now.Sub(cond.LastTransitionTime.Time) >= h.pendingPodsGracePeriod
+		}
+	}
+	return false
+}
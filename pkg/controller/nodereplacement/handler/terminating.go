@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/pkg/provider"
+)
+
+// handleTerminating handles a NodeReplacement in the terminating phase. It asks
+// the provider to terminate the instance backing the drained node and requeues
+// until the provider reports the instance as terminated, at which point the
+// replacement moves on to waiting for a replacement node, or is marked
+// completed
+func (h *NodeReplacementHandler) handleTerminating(instance *navarchosv1alpha1.NodeReplacement) (*status.Result, error) {
+	if h.provider == nil {
+		// The provider was removed since the replacement entered this phase,
+		// there is nothing more the controller can do
+		return drainedResult(instance), nil
+	}
+
+	node, exists, err := h.getNode(instance)
+	if err != nil {
+		return &status.Result{}, fmt.Errorf("error getting node: %v", err)
+	}
+	if !exists {
+		// The node object is removed once its instance has gone away
+		result := drainedResult(instance)
+		result.InstanceTerminatedReason = navarchosv1alpha1.ReasonInstanceTerminated
+		return result, nil
+	}
+
+	// Without a providerID the instance can never be terminated, retrying
+	// would not help
+	providerID := node.Spec.ProviderID
+	if providerID == "" {
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		return &status.Result{
+			Phase:                    &failedPhase,
+			InstanceTerminatedError:  fmt.Errorf("node %s has no providerID set", node.GetName()),
+			InstanceTerminatedReason: navarchosv1alpha1.ReasonMissingProviderID,
+		}, nil
+	}
+
+	state, err := h.provider.InstanceState(providerID)
+	if err != nil {
+		err = fmt.Errorf("error getting state of instance %s: %v", providerID, err)
+		return &status.Result{
+			InstanceTerminatedError:  err,
+			InstanceTerminatedReason: navarchosv1alpha1.ReasonErrorTerminatingInstance,
+		}, err
+	}
+	if state == provider.InstanceStateTerminated {
+		result := drainedResult(instance)
+		result.InstanceTerminatedReason = navarchosv1alpha1.ReasonInstanceTerminated
+		return result, nil
+	}
+
+	if state == provider.InstanceStateRunning {
+		err = h.provider.TerminateInstance(providerID)
+		if err != nil {
+			err = fmt.Errorf("error terminating instance %s: %v", providerID, err)
+			return &status.Result{
+				InstanceTerminatedError:  err,
+				InstanceTerminatedReason: navarchosv1alpha1.ReasonErrorTerminatingInstance,
+			}, err
+		}
+	}
+
+	reason := fmt.Sprintf("waiting for instance %s to terminate", providerID)
+	return &status.Result{
+		Requeue:                  true,
+		RequeueReason:            reason,
+		RequeueCause:             string(navarchosv1alpha1.ReasonInstanceTerminating),
+		InstanceTerminatedError:  errors.New(reason),
+		InstanceTerminatedReason: navarchosv1alpha1.ReasonInstanceTerminating,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/pkg/provider"
+	"github.com/pusher/navarchos/pkg/provider/fake"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("terminating replacement handler", func() {
+	var m utils.Matcher
+	var h *NodeReplacementHandler
+	var opts *Options
+	var fakeProvider *fake.Provider
+
+	var nodeReplacement *navarchosv1alpha1.NodeReplacement
+	var mgrStopped *sync.WaitGroup
+	var stopMgr chan struct{}
+
+	var workerNode1 *corev1.Node
+
+	var result *status.Result
+	var handleErr error
+
+	const timeout = time.Second * 5
+	const providerID = "fake:///example-worker-1"
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+
+		fakeProvider = fake.NewProvider()
+		fakeProvider.AddInstance(providerID)
+		opts = &Options{
+			Provider: fakeProvider,
+		}
+
+		workerNode1 = utils.ExampleNodeWorker1.DeepCopy()
+		workerNode1.Spec.ProviderID = providerID
+		m.Create(workerNode1).Should(Succeed())
+
+		nodeReplacement = utils.ExampleNodeReplacement.DeepCopy()
+		nodeReplacement.SetOwnerReferences([]metav1.OwnerReference{utils.GetOwnerReferenceForNode(workerNode1)})
+		nodeReplacement.Spec.NodeUID = workerNode1.GetUID()
+		nodeReplacement.Spec.NodeName = workerNode1.GetName()
+		nodeReplacement.Status.Phase = navarchosv1alpha1.ReplacementPhaseTerminating
+		m.Create(nodeReplacement).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeReplacementList{},
+			&corev1.NodeList{},
+		)
+	})
+
+	JustBeforeEach(func() {
+		h = NewNodeReplacementHandler(m.Client, opts)
+		result, handleErr = h.handleTerminating(nodeReplacement)
+	})
+
+	Context("when the instance is running", func() {
+		It("requests termination of the instance", func() {
+			Expect(fakeProvider.InstanceState(providerID)).To(Equal(provider.InstanceStateTerminating))
+		})
+
+		It("requeues the NodeReplacement", func() {
+			Expect(result.Requeue).To(BeTrue())
+			Expect(result.RequeueReason).To(Equal("waiting for instance fake:///example-worker-1 to terminate"))
+		})
+
+		It("sets the InstanceTerminatedReason to InstanceTerminating", func() {
+			Expect(result.InstanceTerminatedReason).To(Equal(navarchosv1alpha1.ReasonInstanceTerminating))
+		})
+
+		It("does not set the phase", func() {
+			Expect(result.Phase).To(BeNil())
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when the instance has terminated", func() {
+		BeforeEach(func() {
+			Expect(fakeProvider.TerminateInstance(providerID)).To(Succeed())
+			Expect(fakeProvider.CompleteTermination(providerID)).To(Succeed())
+		})
+
+		It("sets the phase to completed", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseCompleted
+			Expect(result.Phase).To(Equal(&phase))
+			Expect(result.CompletionTimestamp).ToNot(BeNil())
+		})
+
+		It("sets the InstanceTerminatedReason to InstanceTerminated", func() {
+			Expect(result.InstanceTerminatedReason).To(Equal(navarchosv1alpha1.ReasonInstanceTerminated))
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when the node has been removed", func() {
+		BeforeEach(func() {
+			m.Delete(workerNode1).Should(Succeed())
+			m.Get(workerNode1, timeout).ShouldNot(Succeed())
+		})
+
+		It("sets the phase to completed", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseCompleted
+			Expect(result.Phase).To(Equal(&phase))
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when the node has no providerID", func() {
+		BeforeEach(func() {
+			m.Update(workerNode1, func(obj utils.Object) utils.Object {
+				node, _ := obj.(*corev1.Node)
+				node.Spec.ProviderID = ""
+				return node
+			}, timeout).Should(Succeed())
+		})
+
+		It("sets the InstanceTerminatedReason to MissingProviderID", func() {
+			Expect(result.InstanceTerminatedReason).To(Equal(navarchosv1alpha1.ReasonMissingProviderID))
+		})
+
+		It("sets the InstanceTerminatedError", func() {
+			Expect(result.InstanceTerminatedError).To(MatchError("node example-worker-1 has no providerID set"))
+		})
+
+		It("sets the phase to Failed", func() {
+			Expect(result.Phase).ToNot(BeNil())
+			Expect(*result.Phase).To(Equal(navarchosv1alpha1.ReplacementPhaseFailed))
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when the provider fails to terminate the instance", func() {
+		BeforeEach(func() {
+			fakeProvider.TerminateErr = errors.New("api unavailable")
+		})
+
+		It("sets the InstanceTerminatedReason to ErrorTerminatingInstance", func() {
+			Expect(result.InstanceTerminatedReason).To(Equal(navarchosv1alpha1.ReasonErrorTerminatingInstance))
+		})
+
+		It("should return an error", func() {
+			Expect(handleErr).To(MatchError("error terminating instance fake:///example-worker-1: api unavailable"))
+		})
+	})
+
+	Context("when no provider is configured", func() {
+		BeforeEach(func() {
+			opts.Provider = nil
+		})
+
+		It("sets the phase to completed", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseCompleted
+			Expect(result.Phase).To(Equal(&phase))
+		})
+
+		It("does not terminate the instance", func() {
+			Expect(fakeProvider.InstanceState(providerID)).To(Equal(provider.InstanceStateRunning))
+		})
+	})
+})
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metalabels "k8s.io/apimachinery/pkg/labels"
+)
+
+// handleWaitingForReplacement handles a NodeReplacement in the waiting for
+// replacement phase. It looks for a Ready node, matching the replacement node
+// selector, that was created after the node was cordoned. Once one is found
+// the replacement is marked completed, otherwise it is requeued until the
+// timeout has passed, at which point it is marked failed
+func (h *NodeReplacementHandler) handleWaitingForReplacement(instance *navarchosv1alpha1.NodeReplacement) (*status.Result, error) {
+	labelSelector := instance.Status.ReplacementNodeSelector
+	if labelSelector == nil {
+		node, exists, err := h.getNode(instance)
+		if err != nil {
+			return &status.Result{}, fmt.Errorf("error getting node: %v", err)
+		}
+		if !exists {
+			node = nil
+		}
+		labelSelector = replacementNodeSelector(instance, node)
+		if labelSelector == nil {
+			err := fmt.Errorf("no replacement node selector set and node %s has none of the labels to default it from", instance.Spec.NodeName)
+			if !exists {
+				err = fmt.Errorf("no replacement node selector set and node %s no longer exists", instance.Spec.NodeName)
+			}
+			return &status.Result{
+				ReplacementNodeReadyError:  err,
+				ReplacementNodeReadyReason: navarchosv1alpha1.ReasonErrorFindingReplacementNode,
+			}, err
+		}
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		err = fmt.Errorf("invalid replacement node selector: %v", err)
+		return &status.Result{
+			ReplacementNodeReadyError:  err,
+			ReplacementNodeReadyReason: navarchosv1alpha1.ReasonErrorFindingReplacementNode,
+		}, err
+	}
+
+	createdAfter := instance.GetCreationTimestamp()
+	if instance.Status.CordonTimestamp != nil {
+		createdAfter = *instance.Status.CordonTimestamp
+	}
+
+	replacementNode, err := h.findReplacementNode(instance, selector, createdAfter)
+	if err != nil {
+		err = fmt.Errorf("error finding replacement node: %v", err)
+		return &status.Result{
+			ReplacementNodeReadyError:  err,
+			ReplacementNodeReadyReason: navarchosv1alpha1.ReasonErrorFindingReplacementNode,
+		}, err
+	}
+	if replacementNode != "" {
+		completedPhase := navarchosv1alpha1.ReplacementPhaseCompleted
+		completedTime := metav1.Now()
+
+		return &status.Result{
+			Phase:                      &completedPhase,
+			CompletionTimestamp:        &completedTime,
+			ReplacementNode:            replacementNode,
+			ReplacementNodeReadyReason: navarchosv1alpha1.ReasonReplacementNodeReady,
+		}, nil
+	}
+
+	timeout := h.replacementTimeout
+	if instance.Spec.ReplacementSpec.WaitForReplacement != nil && instance.Spec.ReplacementSpec.WaitForReplacement.Timeout != nil {
+		timeout = instance.Spec.ReplacementSpec.WaitForReplacement.Timeout.Duration
+	}
+	if time.Since(waitingSince(instance)) > timeout {
+		failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+		return &status.Result{
+			Phase:                      &failedPhase,
+			ReplacementNodeReadyError:  fmt.Errorf("timed out after %v waiting for a Ready node matching selector %q", timeout, selector.String()),
+			ReplacementNodeReadyReason: navarchosv1alpha1.ReasonReplacementNodeTimedOut,
+		}, nil
+	}
+
+	reason := fmt.Sprintf("waiting for a Ready node matching selector %q created after %s", selector.String(), createdAfter.UTC().Format(time.RFC3339))
+	return &status.Result{
+		Requeue:                    true,
+		RequeueReason:              reason,
+		RequeueCause:               string(navarchosv1alpha1.ReasonWaitingForReplacementNode),
+		ReplacementNodeReadyError:  errors.New(reason),
+		ReplacementNodeReadyReason: navarchosv1alpha1.ReasonWaitingForReplacementNode,
+	}, nil
+}
+
+// findReplacementNode returns the name of a Ready and schedulable node that
+// matches the selector and was created after the given time. Nodes that are
+// being replaced, or that already replaced another node, are never returned.
+// If no such node exists an empty string is returned
+func (h *NodeReplacementHandler) findReplacementNode(instance *navarchosv1alpha1.NodeReplacement, selector metalabels.Selector, createdAfter metav1.Time) (string, error) {
+	replacements := &navarchosv1alpha1.NodeReplacementList{}
+	err := h.client.List(context.Background(), replacements)
+	if err != nil {
+		return "", fmt.Errorf("failed to list NodeReplacements: %v", err)
+	}
+
+	unavailable := map[string]struct{}{}
+	for _, replacement := range replacements.Items {
+		unavailable[replacement.Spec.NodeName] = struct{}{}
+		if replacement.GetUID() != instance.GetUID() && replacement.Status.ReplacementNode != "" {
+			unavailable[replacement.Status.ReplacementNode] = struct{}{}
+		}
+	}
+
+	nodes := &corev1.NodeList{}
+	err = h.client.List(context.Background(), nodes)
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	for _, node := range nodes.Items {
+		if _, ok := unavailable[node.GetName()]; ok {
+			continue
+		}
+		if !selector.Matches(metalabels.Set(node.GetLabels())) {
+			continue
+		}
+		if node.CreationTimestamp.Before(&createdAfter) {
+			continue
+		}
+		if node.Spec.Unschedulable || !isNodeReady(&node) {
+			continue
+		}
+		return node.GetName(), nil
+	}
+
+	return "", nil
+}
+
+// waitingSince returns the time at which the NodeReplacement started waiting
+// for a replacement node. This is the last transition of the
+// ReplacementNodeReady condition, or now if it has not been set yet
+func waitingSince(instance *navarchosv1alpha1.NodeReplacement) time.Time {
+	for _, cond := range instance.Status.Conditions {
+		if cond.Type == navarchosv1alpha1.ReplacementNodeReadyType && cond.Status == corev1.ConditionFalse {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return time.Now()
+}
+
+// replacementNodeLabels are the labels of a node that are copied into the
+// default replacement node selector. They describe the node group the node
+// belongs to rather than the node itself, so that a node launched in its place
+// carries the same values
+var replacementNodeLabels = []string{
+	"beta.kubernetes.io/instance-type",
+	"node.kubernetes.io/instance-type",
+	"failure-domain.beta.kubernetes.io/zone",
+	"failure-domain.beta.kubernetes.io/region",
+	"topology.kubernetes.io/zone",
+	"topology.kubernetes.io/region",
+	"kubernetes.io/role",
+	"eks.amazonaws.com/nodegroup",
+	"alpha.eksctl.io/nodegroup-name",
+	"kops.k8s.io/instancegroup",
+	"cloud.google.com/gke-nodepool",
+	"agentpool",
+}
+
+// replacementNodeRolePrefix is the prefix of the node role labels, which are
+// also copied into the default replacement node selector
+const replacementNodeRolePrefix = "node-role.kubernetes.io/"
+
+// replacementNodeSelector returns the selector for nodes that can replace the
+// node in the NodeReplacement. If the NodeReplacement does not set one, the
+// replacementNodeLabels and node role labels of the node are used. If neither
+// is available nil is returned
+func replacementNodeSelector(instance *navarchosv1alpha1.NodeReplacement, node *corev1.Node) *metav1.LabelSelector {
+	waitSpec := instance.Spec.ReplacementSpec.WaitForReplacement
+	if waitSpec != nil && waitSpec.Selector != nil {
+		return waitSpec.Selector.DeepCopy()
+	}
+	if node == nil {
+		return nil
+	}
+
+	labels := node.GetLabels()
+	matchLabels := map[string]string{}
+	for _, key := range replacementNodeLabels {
+		if value, ok := labels[key]; ok {
+			matchLabels[key] = value
+		}
+	}
+	for key, value := range labels {
+		if strings.HasPrefix(key, replacementNodeRolePrefix) {
+			matchLabels[key] = value
+		}
+	}
+	if len(matchLabels) == 0 {
+		return nil
+	}
+	return &metav1.LabelSelector{
+		MatchLabels: matchLabels,
+	}
+}
+
+// isNodeReady returns true if the node has a Ready condition with status True
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
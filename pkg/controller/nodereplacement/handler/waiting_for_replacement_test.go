@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("waiting for replacement handler", func() {
+	var m utils.Matcher
+	var h *NodeReplacementHandler
+	var opts *Options
+
+	var nodeReplacement *navarchosv1alpha1.NodeReplacement
+	var mgrStopped *sync.WaitGroup
+	var stopMgr chan struct{}
+
+	var workerNode1 *corev1.Node
+	var workerNode2 *corev1.Node
+
+	var result *status.Result
+	var handleErr error
+
+	const timeout = time.Second * 5
+
+	var setNodeReady = func(obj utils.Object) utils.Object {
+		node, _ := obj.(*corev1.Node)
+		node.Status.Conditions = []corev1.NodeCondition{
+			{
+				Type:   corev1.NodeReady,
+				Status: corev1.ConditionTrue,
+			},
+		}
+		return node
+	}
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+
+		opts = &Options{}
+
+		workerNode1 = utils.ExampleNodeWorker1.DeepCopy()
+		m.Create(workerNode1).Should(Succeed())
+
+		cordonTime := metav1.NewTime(time.Now().Add(-time.Minute))
+		nodeReplacement = utils.ExampleNodeReplacement.DeepCopy()
+		nodeReplacement.SetOwnerReferences([]metav1.OwnerReference{utils.GetOwnerReferenceForNode(workerNode1)})
+		nodeReplacement.Spec.NodeUID = workerNode1.GetUID()
+		nodeReplacement.Spec.NodeName = workerNode1.GetName()
+		nodeReplacement.Spec.ReplacementSpec.WaitForReplacement = &navarchosv1alpha1.WaitForReplacementSpec{}
+		nodeReplacement.Status.Phase = navarchosv1alpha1.ReplacementPhaseWaitingForReplacement
+		nodeReplacement.Status.CordonTimestamp = &cordonTime
+		m.Create(nodeReplacement).Should(Succeed())
+
+		workerNode2 = utils.ExampleNodeWorker2.DeepCopy()
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeReplacementList{},
+			&corev1.NodeList{},
+		)
+	})
+
+	JustBeforeEach(func() {
+		h = NewNodeReplacementHandler(m.Client, opts)
+		result, handleErr = h.handleWaitingForReplacement(nodeReplacement)
+	})
+
+	Context("when no replacement node has joined the cluster", func() {
+		It("requeues the NodeReplacement", func() {
+			Expect(result.Requeue).To(BeTrue())
+			Expect(result.RequeueReason).To(HavePrefix("waiting for a Ready node matching selector \"node-role.kubernetes.io/master=false,node-role.kubernetes.io/worker=true\""))
+		})
+
+		It("sets the ReplacementNodeReadyReason to WaitingForReplacementNode", func() {
+			Expect(result.ReplacementNodeReadyReason).To(Equal(navarchosv1alpha1.ReasonWaitingForReplacementNode))
+			Expect(result.ReplacementNodeReadyError).To(HaveOccurred())
+		})
+
+		It("does not set the phase", func() {
+			Expect(result.Phase).To(BeNil())
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when the node has labels of its own", func() {
+		BeforeEach(func() {
+			m.Update(workerNode1, func(obj utils.Object) utils.Object {
+				node, _ := obj.(*corev1.Node)
+				labels := node.GetLabels()
+				labels["beta.kubernetes.io/instance-type"] = "m5.large"
+				labels["example.com/instance-id"] = "i-0123456789abcdef0"
+				node.SetLabels(labels)
+				return node
+			}, timeout).Should(Succeed())
+		})
+
+		It("only selects replacement nodes by the labels of its node group", func() {
+			Expect(result.RequeueReason).To(HavePrefix("waiting for a Ready node matching selector \"beta.kubernetes.io/instance-type=m5.large,node-role.kubernetes.io/master=false,node-role.kubernetes.io/worker=true\""))
+		})
+	})
+
+	Context("when the node has none of the labels of its node group", func() {
+		BeforeEach(func() {
+			m.Update(workerNode1, func(obj utils.Object) utils.Object {
+				node, _ := obj.(*corev1.Node)
+				node.SetLabels(map[string]string{"example.com/instance-id": "i-0123456789abcdef0"})
+				return node
+			}, timeout).Should(Succeed())
+		})
+
+		It("sets the ReplacementNodeReadyReason to ErrorFindingReplacementNode", func() {
+			Expect(result.ReplacementNodeReadyReason).To(Equal(navarchosv1alpha1.ReasonErrorFindingReplacementNode))
+		})
+
+		It("should return an error", func() {
+			Expect(handleErr).To(MatchError("no replacement node selector set and node example-worker-1 has none of the labels to default it from"))
+		})
+	})
+
+	Context("when a matching node has joined but is not Ready", func() {
+		BeforeEach(func() {
+			m.Create(workerNode2).Should(Succeed())
+		})
+
+		It("requeues the NodeReplacement", func() {
+			Expect(result.Requeue).To(BeTrue())
+		})
+
+		It("does not set the replacement node", func() {
+			Expect(result.ReplacementNode).To(BeEmpty())
+		})
+	})
+
+	Context("when a matching node has joined and is Ready", func() {
+		BeforeEach(func() {
+			m.Create(workerNode2).Should(Succeed())
+			m.UpdateStatus(workerNode2, setNodeReady, timeout).Should(Succeed())
+		})
+
+		It("sets the phase to completed", func() {
+			phase := navarchosv1alpha1.ReplacementPhaseCompleted
+			Expect(result.Phase).To(Equal(&phase))
+			Expect(result.CompletionTimestamp).ToNot(BeNil())
+		})
+
+		It("sets the replacement node", func() {
+			Expect(result.ReplacementNode).To(Equal(workerNode2.GetName()))
+		})
+
+		It("sets the ReplacementNodeReadyReason to ReplacementNodeReady", func() {
+			Expect(result.ReplacementNodeReadyReason).To(Equal(navarchosv1alpha1.ReasonReplacementNodeReady))
+			Expect(result.ReplacementNodeReadyError).To(BeNil())
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+
+		Context("and it was created before the node was cordoned", func() {
+			BeforeEach(func() {
+				cordonTime := metav1.NewTime(time.Now().Add(time.Minute))
+				nodeReplacement.Status.CordonTimestamp = &cordonTime
+			})
+
+			It("does not set the replacement node", func() {
+				Expect(result.ReplacementNode).To(BeEmpty())
+				Expect(result.Requeue).To(BeTrue())
+			})
+		})
+
+		Context("and it has already replaced another node", func() {
+			BeforeEach(func() {
+				other := utils.ExampleNodeReplacement.DeepCopy()
+				other.SetName("other")
+				other.Spec.NodeName = "example-worker-0"
+				other.Status.ReplacementNode = workerNode2.GetName()
+				m.Create(other).Should(Succeed())
+				m.Get(other, timeout).Should(Succeed())
+			})
+
+			It("does not set the replacement node", func() {
+				Expect(result.ReplacementNode).To(BeEmpty())
+				Expect(result.Requeue).To(BeTrue())
+			})
+		})
+
+		Context("and it does not match the configured selector", func() {
+			BeforeEach(func() {
+				nodeReplacement.Spec.ReplacementSpec.WaitForReplacement.Selector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"node-role.kubernetes.io/other": "true",
+					},
+				}
+			})
+
+			It("does not set the replacement node", func() {
+				Expect(result.ReplacementNode).To(BeEmpty())
+				Expect(result.Requeue).To(BeTrue())
+			})
+		})
+	})
+
+	Context("when the timeout has passed", func() {
+		BeforeEach(func() {
+			nodeReplacement.Spec.ReplacementSpec.WaitForReplacement.Timeout = &metav1.Duration{Duration: time.Minute}
+			nodeReplacement.Status.Conditions = []navarchosv1alpha1.NodeReplacementCondition{
+				{
+					Type:               navarchosv1alpha1.ReplacementNodeReadyType,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+				},
+			}
+		})
+
+		It("sets the ReplacementNodeReadyReason to ReplacementNodeTimedOut", func() {
+			Expect(result.ReplacementNodeReadyReason).To(Equal(navarchosv1alpha1.ReasonReplacementNodeTimedOut))
+		})
+
+		It("sets the ReplacementNodeReadyError", func() {
+			Expect(result.ReplacementNodeReadyError).To(MatchError(HavePrefix("timed out after 1m0s waiting for a Ready node")))
+		})
+
+		It("sets the phase to Failed", func() {
+			Expect(result.Phase).ToNot(BeNil())
+			Expect(*result.Phase).To(Equal(navarchosv1alpha1.ReplacementPhaseFailed))
+		})
+
+		It("should not return an error", func() {
+			Expect(handleErr).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when the node has been removed and no selector is known", func() {
+		BeforeEach(func() {
+			m.Delete(workerNode1).Should(Succeed())
+			m.Get(workerNode1, timeout).ShouldNot(Succeed())
+		})
+
+		It("sets the ReplacementNodeReadyReason to ErrorFindingReplacementNode", func() {
+			Expect(result.ReplacementNodeReadyReason).To(Equal(navarchosv1alpha1.ReasonErrorFindingReplacementNode))
+		})
+
+		It("should return an error", func() {
+			Expect(handleErr).To(MatchError("no replacement node selector set and node example-worker-1 no longer exists"))
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podWave is a group of pods that share an eviction order
+type podWave struct {
+	order int32
+	pods  []corev1.Pod
+}
+
+// evictionWaves groups the pods into waves by their eviction order, lowest
+// order first. If the eviction order is None all pods are in a single wave
+func evictionWaves(pods []corev1.Pod, evictionOrder navarchosv1alpha1.EvictionOrder) ([]podWave, error) {
+	if len(pods) == 0 {
+		return []podWave{}, nil
+	}
+
+	waveMap := make(map[int32][]corev1.Pod)
+	for _, pod := range pods {
+		order, err := podEvictionOrder(pod, evictionOrder)
+		if err != nil {
+			return nil, err
+		}
+		waveMap[order] = append(waveMap[order], pod)
+	}
+
+	waves := []podWave{}
+	for order, wavePods := range waveMap {
+		waves = append(waves, podWave{order: order, pods: wavePods})
+	}
+	sort.Slice(waves, func(i, j int) bool {
+		return waves[i].order < waves[j].order
+	})
+	return waves, nil
+}
+
+// podEvictionOrder returns the order of the wave the pod is evicted in
+func podEvictionOrder(pod corev1.Pod, evictionOrder navarchosv1alpha1.EvictionOrder) (int32, error) {
+	switch evictionOrder {
+	case navarchosv1alpha1.EvictionOrderNone:
+		return 0, nil
+	case navarchosv1alpha1.EvictionOrderByPriorityClass:
+		if pod.Spec.Priority == nil {
+			return 0, nil
+		}
+		return *pod.Spec.Priority, nil
+	case navarchosv1alpha1.EvictionOrderByAnnotation:
+		value, ok := pod.GetAnnotations()[navarchosv1alpha1.EvictionOrderAnnotation]
+		if !ok {
+			return 0, nil
+		}
+		order, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			log.Printf("ignoring invalid %s annotation on pod %s/%s: %v", navarchosv1alpha1.EvictionOrderAnnotation, pod.GetNamespace(), pod.GetName(), err)
+			return 0, nil
+		}
+		return int32(order), nil
+	default:
+		return 0, fmt.Errorf("invalid eviction order %q", evictionOrder)
+	}
+}
+
+// evictionWavesStatus returns the waves recorded in the status of the
+// NodeReplacement that have completed, followed by the remaining waves
+func evictionWavesStatus(instance *navarchosv1alpha1.NodeReplacement, waves []podWave) []navarchosv1alpha1.EvictionWave {
+	statusWaves := []navarchosv1alpha1.EvictionWave{}
+	for _, wave := range instance.Status.EvictionWaves {
+		if wave.Completed {
+			statusWaves = append(statusWaves, wave)
+		}
+	}
+	for _, wave := range waves {
+		statusWave := navarchosv1alpha1.EvictionWave{
+			Order: wave.order,
+			Pods:  []string{},
+		}
+		for _, pod := range wave.pods {
+			statusWave.Pods = append(statusWave.Pods, pod.GetName())
+		}
+		statusWaves = append(statusWaves, statusWave)
+	}
+	return statusWaves
+}
+
+// completeNextWave marks the first wave that has not completed as completed
+func completeNextWave(waves []navarchosv1alpha1.EvictionWave) {
+	for i := range waves {
+		if !waves[i].Completed {
+			waves[i].Completed = true
+			return
+		}
+	}
+}
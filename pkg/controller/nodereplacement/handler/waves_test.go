@@ -0,0 +1,145 @@
+package handler
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("eviction waves", func() {
+	var newPod = func(name string, priority *int32, order string) corev1.Pod {
+		pod := utils.ExamplePod.DeepCopy()
+		pod.Name = name
+		pod.Spec.Priority = priority
+		if order != "" {
+			pod.SetAnnotations(map[string]string{navarchosv1alpha1.EvictionOrderAnnotation: order})
+		}
+		return *pod
+	}
+
+	var int32Ptr = func(i int32) *int32 {
+		return &i
+	}
+
+	var podNames = func(wave podWave) []string {
+		names := []string{}
+		for _, pod := range wave.pods {
+			names = append(names, pod.GetName())
+		}
+		return names
+	}
+
+	var pods []corev1.Pod
+
+	BeforeEach(func() {
+		pods = []corev1.Pod{
+			newPod("ingress", int32Ptr(1000), "10"),
+			newPod("app-1", int32Ptr(100), ""),
+			newPod("app-2", int32Ptr(100), "invalid"),
+			newPod("batch", nil, "-5"),
+		}
+	})
+
+	Context("evictionWaves", func() {
+		var waves []podWave
+		var wavesErr error
+		var evictionOrder navarchosv1alpha1.EvictionOrder
+
+		JustBeforeEach(func() {
+			waves, wavesErr = evictionWaves(pods, evictionOrder)
+		})
+
+		Context("when the eviction order is None", func() {
+			BeforeEach(func() {
+				evictionOrder = navarchosv1alpha1.EvictionOrderNone
+			})
+
+			It("puts all pods in a single wave", func() {
+				Expect(waves).To(HaveLen(1))
+				Expect(podNames(waves[0])).To(ConsistOf("ingress", "app-1", "app-2", "batch"))
+			})
+		})
+
+		Context("when the eviction order is PriorityClass", func() {
+			BeforeEach(func() {
+				evictionOrder = navarchosv1alpha1.EvictionOrderByPriorityClass
+			})
+
+			It("groups the pods by priority, lowest first", func() {
+				Expect(waves).To(HaveLen(3))
+				Expect(waves[0].order).To(Equal(int32(0)))
+				Expect(podNames(waves[0])).To(ConsistOf("batch"))
+				Expect(waves[1].order).To(Equal(int32(100)))
+				Expect(podNames(waves[1])).To(ConsistOf("app-1", "app-2"))
+				Expect(waves[2].order).To(Equal(int32(1000)))
+				Expect(podNames(waves[2])).To(ConsistOf("ingress"))
+			})
+		})
+
+		Context("when the eviction order is Annotation", func() {
+			BeforeEach(func() {
+				evictionOrder = navarchosv1alpha1.EvictionOrderByAnnotation
+			})
+
+			It("groups the pods by annotation, treating missing and invalid values as 0", func() {
+				Expect(waves).To(HaveLen(3))
+				Expect(podNames(waves[0])).To(ConsistOf("batch"))
+				Expect(podNames(waves[1])).To(ConsistOf("app-1", "app-2"))
+				Expect(podNames(waves[2])).To(ConsistOf("ingress"))
+			})
+		})
+
+		Context("when the eviction order is invalid", func() {
+			BeforeEach(func() {
+				evictionOrder = "Random"
+			})
+
+			It("returns an error", func() {
+				Expect(wavesErr).To(MatchError("invalid eviction order \"Random\""))
+			})
+		})
+
+		Context("when there are no pods", func() {
+			BeforeEach(func() {
+				pods = []corev1.Pod{}
+				evictionOrder = navarchosv1alpha1.EvictionOrderNone
+			})
+
+			It("returns no waves", func() {
+				Expect(waves).To(BeEmpty())
+				Expect(wavesErr).ToNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("evictionWavesStatus", func() {
+		var instance *navarchosv1alpha1.NodeReplacement
+		var statusWaves []navarchosv1alpha1.EvictionWave
+
+		BeforeEach(func() {
+			instance = utils.ExampleNodeReplacement.DeepCopy()
+			instance.Status.EvictionWaves = []navarchosv1alpha1.EvictionWave{
+				{Order: -5, Pods: []string{"batch"}, Completed: true},
+				{Order: 0, Pods: []string{"app-1", "app-2"}},
+				{Order: 10, Pods: []string{"ingress"}},
+			}
+		})
+
+		JustBeforeEach(func() {
+			waves, err := evictionWaves(pods[:3], navarchosv1alpha1.EvictionOrderByAnnotation)
+			Expect(err).ToNot(HaveOccurred())
+			statusWaves = evictionWavesStatus(instance, waves)
+			completeNextWave(statusWaves)
+		})
+
+		It("keeps the completed waves and completes the next wave", func() {
+			Expect(statusWaves).To(Equal([]navarchosv1alpha1.EvictionWave{
+				{Order: -5, Pods: []string{"batch"}, Completed: true},
+				{Order: 0, Pods: []string{"app-1", "app-2"}, Completed: true},
+				{Order: 10, Pods: []string{"ingress"}},
+			}))
+		})
+	})
+})
@@ -23,9 +23,13 @@ import (
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/nodereplacement/handler"
 	"github.com/pusher/navarchos/pkg/controller/nodereplacement/status"
+	"github.com/pusher/navarchos/pkg/metrics"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -44,15 +48,14 @@ modifying this file.*
 // Add creates a new NodeReplacement Controller and adds it to the Manager with
 // default RBAC. The Manager will set fields on the Controller and Start it when
 // the Manager is Started.
-func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+func Add(mgr manager.Manager, opts *handler.Options) error {
+	return add(mgr, newReconciler(mgr, opts))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	h := handler.NewNodeReplacementHandler(mgr.GetClient(), &handler.Options{
-		Config: mgr.GetConfig(),
-	})
+func newReconciler(mgr manager.Manager, opts *handler.Options) reconcile.Reconciler {
+	opts.Config = mgr.GetConfig()
+	h := handler.NewNodeReplacementHandler(mgr.GetClient(), opts)
 	return &ReconcileNodeReplacement{Client: mgr.GetClient(),
 		handler:  h,
 		scheme:   mgr.GetScheme(),
@@ -73,6 +76,39 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to Nodes so that NodeReplacements waiting for a
+	// replacement node are reconciled as soon as a node joins the cluster
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &watchhandler.EnqueueRequestsFromMapFunc{
+		ToRequests: watchhandler.ToRequestsFunc(func(watchhandler.MapObject) []reconcile.Request {
+			return waitingReplacementRequests(mgr.GetClient())
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to NodeRollouts so that NodeReplacements react to their
+	// NodeRollout being paused, resumed or aborted
+	err = c.Watch(&source.Kind{Type: &navarchosv1alpha1.NodeRollout{}}, &watchhandler.EnqueueRequestsFromMapFunc{
+		ToRequests: watchhandler.ToRequestsFunc(func(obj watchhandler.MapObject) []reconcile.Request {
+			return ownedReplacementRequests(mgr.GetClient(), obj.Meta)
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to hook Jobs so that NodeReplacements continue as soon
+	// as their hook completes
+	err = c.Watch(&source.Kind{Type: &batchv1.Job{}}, &watchhandler.EnqueueRequestsFromMapFunc{
+		ToRequests: watchhandler.ToRequestsFunc(func(obj watchhandler.MapObject) []reconcile.Request {
+			return controllingReplacementRequests(obj.Meta)
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
 	err = mgr.GetCache().IndexField(&corev1.Pod{}, "spec.nodeName", func(obj runtime.Object) []string {
 		pod, _ := obj.(*corev1.Pod)
 		return []string{pod.Spec.NodeName}
@@ -92,6 +128,72 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	return nil
 }
 
+// waitingReplacementRequests returns a reconcile.Request for every
+// NodeReplacement waiting for a replacement node
+func waitingReplacementRequests(c client.Client) []reconcile.Request {
+	replacements := &navarchosv1alpha1.NodeReplacementList{}
+	err := c.List(context.TODO(), replacements)
+	if err != nil {
+		log.Printf("error listing NodeReplacements: %v", err)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, replacement := range replacements.Items {
+		if replacement.Status.Phase != navarchosv1alpha1.ReplacementPhaseWaitingForReplacement {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      replacement.GetName(),
+				Namespace: replacement.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
+
+// ownedReplacementRequests returns a reconcile.Request for every
+// NodeReplacement controlled by the owner
+func ownedReplacementRequests(c client.Client, owner metav1.Object) []reconcile.Request {
+	replacements := &navarchosv1alpha1.NodeReplacementList{}
+	err := c.List(context.TODO(), replacements)
+	if err != nil {
+		log.Printf("error listing NodeReplacements: %v", err)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, replacement := range replacements.Items {
+		if !metav1.IsControlledBy(&replacement, owner) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      replacement.GetName(),
+				Namespace: replacement.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
+
+// controllingReplacementRequests returns a reconcile.Request for the
+// NodeReplacement controlling the object, if any
+func controllingReplacementRequests(obj metav1.Object) []reconcile.Request {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "NodeReplacement" {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name: owner.Name,
+			},
+		},
+	}
+}
+
 var _ reconcile.Reconciler = &ReconcileNodeReplacement{}
 
 // ReconcileNodeReplacement reconciles a NodeReplacement object
@@ -113,6 +215,8 @@ type ReconcileNodeReplacement struct {
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
 func (r *ReconcileNodeReplacement) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// Fetch the NodeReplacement instance
 	instance := &navarchosv1alpha1.NodeReplacement{}
@@ -127,6 +231,7 @@ func (r *ReconcileNodeReplacement) Reconcile(request reconcile.Request) (reconci
 		return reconcile.Result{}, err
 	}
 
+	finished := instance.Status.CompletionTimestamp != nil
 	result, err := r.handler.Handle(instance)
 	if err != nil {
 		// Ensure we attempt to update the status even when the handler fails
@@ -141,13 +246,37 @@ func (r *ReconcileNodeReplacement) Reconcile(request reconcile.Request) (reconci
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("error updating status: %v", err)
 	}
+	if !finished {
+		observeCordonToComplete(instance)
+	}
 	if result.Requeue {
+		metrics.Requeues.WithLabelValues(requeueCause(result)).Inc()
 		log.Printf("requeueing replacement %s: %s", instance.GetName(), result.RequeueReason)
 		r.recorder.Eventf(instance, corev1.EventTypeNormal, "ReplacementRequeue", result.RequeueReason)
 		return reconcile.Result{
-			Requeue: true,
+			Requeue:      true,
+			RequeueAfter: result.RequeueAfter,
 		}, nil
 	}
 
 	return reconcile.Result{}, nil
 }
+
+// observeCordonToComplete records the time between cordoning the node of the
+// NodeReplacement and the NodeReplacement finishing, once it has finished
+func observeCordonToComplete(instance *navarchosv1alpha1.NodeReplacement) {
+	cordoned, finished := instance.Status.CordonTimestamp, instance.Status.CompletionTimestamp
+	if cordoned == nil || finished == nil {
+		return
+	}
+	metrics.CordonToCompleteDuration.WithLabelValues(string(instance.Status.Phase)).Observe(finished.Sub(cordoned.Time).Seconds())
+}
+
+// requeueCause returns the cause used to count the requeue of the Result,
+// defaulting to Unknown if the handler did not set one
+func requeueCause(result *status.Result) string {
+	if result.RequeueCause == "" {
+		return "Unknown"
+	}
+	return result.RequeueCause
+}
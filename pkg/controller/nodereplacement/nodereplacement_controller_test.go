@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/nodereplacement/handler"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -58,7 +59,7 @@ var _ = Describe("NodeReplacement controller suite", func() {
 		c = mgr.GetClient()
 
 		var recFn reconcile.Reconciler
-		recFn, requests = SetupTestReconcile(newReconciler(mgr))
+		recFn, requests = SetupTestReconcile(newReconciler(mgr, &handler.Options{}))
 		Expect(add(mgr, recFn)).NotTo(HaveOccurred())
 
 		stopMgr, mgrStopped = StartTestManager(mgr)
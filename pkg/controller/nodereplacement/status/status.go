@@ -32,6 +32,20 @@ func UpdateStatus(c client.Client, instance *navarchosv1alpha1.NodeReplacement,
 	}
 
 	setFailedPods(&status, result)
+	setDisruptionBlockedPods(&status, result)
+	setUnschedulablePods(&status, result)
+	setBlockingPods(&status, result)
+
+	setCordonTimestamp(&status, result)
+	setCordonedByController(&status, result)
+	setDrainStartTimestamp(&status, result)
+	setAttempts(&status, result)
+	setLastAttemptTime(&status, result)
+	setNextAttemptTime(&status, result)
+	setDrain(&status, result)
+	setEvictionWaves(&status, result)
+	setReplacementNodeSelector(&status, result)
+	setReplacementNode(&status, result)
 
 	err = setCompletionTimestamp(&status, result)
 	if err != nil {
@@ -43,6 +57,63 @@ func UpdateStatus(c client.Client, instance *navarchosv1alpha1.NodeReplacement,
 		return err
 	}
 
+	err = setCondition(&status, navarchosv1alpha1.NodeDrainedType, result.NodeDrainedError, result.NodeDrainedReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.ApprovedType, result.ApprovedError, result.ApprovedReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.PendingPodsClearedType, result.PendingPodsClearedError, result.PendingPodsClearedReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.DrainFeasibleType, result.DrainFeasibleError, result.DrainFeasibleReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.PodsSchedulableType, result.PodsSchedulableError, result.PodsSchedulableReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.PreCordonHookType, result.PreCordonHookError, result.PreCordonHookReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.PreDrainHookType, result.PreDrainHookError, result.PreDrainHookReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.PostDrainHookType, result.PostDrainHookError, result.PostDrainHookReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.InstanceTerminatedType, result.InstanceTerminatedError, result.InstanceTerminatedReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.ReplacementNodeReadyType, result.ReplacementNodeReadyError, result.ReplacementNodeReadyReason)
+	if err != nil {
+		return err
+	}
+
+	err = setCondition(&status, navarchosv1alpha1.NodeRestoredType, result.NodeRestoredError, result.NodeRestoredReason)
+	if err != nil {
+		return err
+	}
+
+	setPausedCondition(&status, result)
+
 	if !reflect.DeepEqual(status, instance.Status) {
 		instance.Status = status
 
@@ -113,6 +184,114 @@ func setFailedPods(status *navarchosv1alpha1.NodeReplacementStatus, result *Resu
 	}
 }
 
+// setDisruptionBlockedPods sets the DisruptionBlockedPods field if it is set
+// in the result
+func setDisruptionBlockedPods(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.DisruptionBlockedPods != nil {
+		status.DisruptionBlockedPods = result.DisruptionBlockedPods
+		status.DisruptionBlockedPodsCount = len(result.DisruptionBlockedPods)
+	}
+}
+
+// setUnschedulablePods sets the UnschedulablePods field if it is set in the
+// result
+func setUnschedulablePods(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.UnschedulablePods != nil {
+		status.UnschedulablePods = result.UnschedulablePods
+		status.UnschedulablePodsCount = len(result.UnschedulablePods)
+	}
+}
+
+// setBlockingPods sets the BlockingPods field if it is set in the result
+func setBlockingPods(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.BlockingPods != nil {
+		status.BlockingPods = result.BlockingPods
+		status.BlockingPodsCount = len(result.BlockingPods)
+	}
+}
+
+// setCordonTimestamp sets the CordonTimestamp field, provided it has not been
+// set before
+func setCordonTimestamp(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if status.CordonTimestamp == nil && result.CordonTimestamp != nil {
+		status.CordonTimestamp = result.CordonTimestamp
+	}
+}
+
+// setCordonedByController sets the CordonedByController field when it is set
+// in the result
+func setCordonedByController(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.CordonedByController {
+		status.CordonedByController = true
+	}
+}
+
+// setDrainStartTimestamp sets the DrainStartTimestamp field, provided it has
+// not been set before
+func setDrainStartTimestamp(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if status.DrainStartTimestamp == nil && result.DrainStartTimestamp != nil {
+		status.DrainStartTimestamp = result.DrainStartTimestamp
+	}
+}
+
+// setAttempts sets the Attempts field when it is set in the result
+func setAttempts(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.Attempts != 0 {
+		status.Attempts = result.Attempts
+	}
+}
+
+// setLastAttemptTime sets the LastAttemptTime field when it is set in the
+// result
+func setLastAttemptTime(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.LastAttemptTime != nil {
+		status.LastAttemptTime = result.LastAttemptTime
+	}
+}
+
+// setNextAttemptTime sets the NextAttemptTime field when it is set in the
+// result. It is cleared when the phase moves on from InProgress as no further
+// attempts will be made
+func setNextAttemptTime(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.NextAttemptTime != nil {
+		status.NextAttemptTime = result.NextAttemptTime
+		return
+	}
+	if result.Phase != nil && *result.Phase != navarchosv1alpha1.ReplacementPhaseInProgress {
+		status.NextAttemptTime = nil
+	}
+}
+
+// setDrain sets the Drain field when it is set in the result
+func setDrain(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.Drain != nil {
+		status.Drain = result.Drain
+	}
+}
+
+// setEvictionWaves sets the EvictionWaves field when it is set in the result
+func setEvictionWaves(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.EvictionWaves != nil {
+		status.EvictionWaves = result.EvictionWaves
+	}
+}
+
+// setReplacementNodeSelector sets the ReplacementNodeSelector field, provided
+// it has not been set before
+func setReplacementNodeSelector(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if status.ReplacementNodeSelector == nil && result.ReplacementNodeSelector != nil {
+		status.ReplacementNodeSelector = result.ReplacementNodeSelector
+	}
+}
+
+// setReplacementNode sets the ReplacementNode field when it is set in the
+// result
+func setReplacementNode(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	if result.ReplacementNode != "" {
+		status.ReplacementNode = result.ReplacementNode
+	}
+}
+
 // setCompletionTimestamp sets the setCompletionTimestamp field. If it has not
 // been set before it is added. If it has been set before an error is returned
 func setCompletionTimestamp(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) error {
@@ -180,9 +359,12 @@ func filterOutCondition(conditions []navarchosv1alpha1.NodeReplacementCondition,
 	return newConditions
 }
 
+// setCondition sets the condition of the given type. If condErr is set the
+// condition status is False and the error is used as the message, otherwise if
+// a reason is set the condition status is True
 func setCondition(status *navarchosv1alpha1.NodeReplacementStatus, condType navarchosv1alpha1.NodeReplacementConditionType, condErr error, reason navarchosv1alpha1.NodeReplacementConditionReason) error {
 	if condErr != nil && reason == "" {
-		return fmt.Errorf("if NodeCordonError is set, NodeCordonReason must also be set")
+		return fmt.Errorf("if the error for condition %s is set, its reason must also be set", condType)
 	}
 	if condErr != nil {
 		// Error for condition , set condition appropriately
@@ -210,6 +392,19 @@ func setCondition(status *navarchosv1alpha1.NodeReplacementStatus, condType nava
 	return nil
 }
 
+// setPausedCondition sets the Paused condition to True when the PausedReason
+// is RolloutPaused and to False when it is RolloutResumed
+func setPausedCondition(status *navarchosv1alpha1.NodeReplacementStatus, result *Result) {
+	switch result.PausedReason {
+	case navarchosv1alpha1.ReasonRolloutPaused:
+		cond := newNodeReplacementCondition(navarchosv1alpha1.ReplacementPausedType, corev1.ConditionTrue, result.PausedReason, "")
+		setNodeReplacementCondition(status, *cond)
+	case navarchosv1alpha1.ReasonRolloutResumed:
+		cond := newNodeReplacementCondition(navarchosv1alpha1.ReplacementPausedType, corev1.ConditionFalse, result.PausedReason, "")
+		setNodeReplacementCondition(status, *cond)
+	}
+}
+
 // appendIfMissingStr will append two []string(s) dropping duplicate elements
 func appendIfMissingStr(slice []string, str ...string) []string {
 	merged := slice
@@ -270,6 +270,43 @@ var _ = Describe("NodeReplacement Status Suite", func() {
 			})
 		})
 
+		Context("when BlockingPods is set in the Result", func() {
+			var blockingPods []navarchosv1alpha1.PodReason
+
+			BeforeEach(func() {
+				blockingPods = []navarchosv1alpha1.PodReason{
+					{Name: "example-pod-1", Reason: "pod is owned by Job example-job"},
+				}
+				result.BlockingPods = blockingPods
+			})
+
+			It("sets the BlockingPods field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.BlockingPods", Equal(blockingPods)))
+			})
+
+			It("sets the BlockingPodsCount field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.BlockingPodsCount", Equal(len(blockingPods))))
+			})
+		})
+
+		Context("when an existing BlockingPods is set and the Result has none", func() {
+			BeforeEach(func() {
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.Status.BlockingPods = []navarchosv1alpha1.PodReason{{Name: "example-pod-1", Reason: "reason-1"}}
+					nr.Status.BlockingPodsCount = 1
+					return nr
+				}, timeout).Should(Succeed())
+
+				result.BlockingPods = []navarchosv1alpha1.PodReason{}
+			})
+
+			It("clears the BlockingPods field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.BlockingPods", BeEmpty()))
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.BlockingPodsCount", BeZero()))
+			})
+		})
+
 		Context("when an existing FailedPods is set", func() {
 			var failedPods []navarchosv1alpha1.PodReason
 			var existingFailedPods []navarchosv1alpha1.PodReason
@@ -365,6 +402,317 @@ var _ = Describe("NodeReplacement Status Suite", func() {
 			})
 		})
 
+		Context("when no existing CordonTimestamp is set and CordonTimestamp is set in the Result", func() {
+			var cordonTimestamp metav1.Time
+
+			BeforeEach(func() {
+				cordonTimestamp = metav1.Now()
+				Expect(nodeReplacement.Status.CordonTimestamp).To(BeNil())
+				result.CordonTimestamp = &cordonTimestamp
+			})
+
+			It("sets the CordonTimestamp field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.CordonTimestamp", Equal(&cordonTimestamp)))
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("when an existing CordonTimestamp is set and CordonTimestamp is set in the Result", func() {
+			var existingCordonTimestamp metav1.Time
+
+			BeforeEach(func() {
+				existingCordonTimestamp = metav1.NewTime(metav1.Now().Add(-time.Hour))
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.Status.CordonTimestamp = &existingCordonTimestamp
+					return nr
+				}, timeout).Should(Succeed())
+
+				cordonTimestamp := metav1.Now()
+				result.CordonTimestamp = &cordonTimestamp
+			})
+
+			It("does not update the CordonTimestamp field", func() {
+				m.Consistently(nodeReplacement, consistentlyTimeout).Should(utils.WithField("Status.CordonTimestamp", Equal(&existingCordonTimestamp)))
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("when CordonedByController is set in the Result", func() {
+			BeforeEach(func() {
+				result.CordonedByController = true
+			})
+
+			It("sets the CordonedByController field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.CordonedByController", BeTrue()))
+			})
+		})
+
+		Context("when CordonedByController is set and not set in the Result", func() {
+			BeforeEach(func() {
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.Status.CordonedByController = true
+					return nr
+				}, timeout).Should(Succeed())
+			})
+
+			It("does not unset the CordonedByController field", func() {
+				m.Consistently(nodeReplacement, consistentlyTimeout).Should(utils.WithField("Status.CordonedByController", BeTrue()))
+			})
+		})
+
+		Context("when an existing DrainStartTimestamp is set and DrainStartTimestamp is set in the Result", func() {
+			var existingDrainStartTimestamp metav1.Time
+
+			BeforeEach(func() {
+				existingDrainStartTimestamp = metav1.NewTime(metav1.Now().Add(-time.Hour))
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.Status.DrainStartTimestamp = &existingDrainStartTimestamp
+					return nr
+				}, timeout).Should(Succeed())
+
+				drainStartTimestamp := metav1.Now()
+				result.DrainStartTimestamp = &drainStartTimestamp
+			})
+
+			It("does not update the DrainStartTimestamp field", func() {
+				m.Consistently(nodeReplacement, consistentlyTimeout).Should(utils.WithField("Status.DrainStartTimestamp", Equal(&existingDrainStartTimestamp)))
+			})
+		})
+
+		Context("when Drain is set in the Result", func() {
+			var drain *navarchosv1alpha1.DrainSpec
+
+			BeforeEach(func() {
+				ignoreAllDaemonSets := true
+				drain = &navarchosv1alpha1.DrainSpec{
+					Timeout:             &metav1.Duration{Duration: 2 * time.Hour},
+					IgnoreAllDaemonSets: &ignoreAllDaemonSets,
+				}
+				result.Drain = drain
+			})
+
+			It("sets the Drain field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.Drain", Equal(drain)))
+			})
+		})
+
+		Context("when EvictionWaves is set in the Result", func() {
+			var waves []navarchosv1alpha1.EvictionWave
+
+			BeforeEach(func() {
+				waves = []navarchosv1alpha1.EvictionWave{
+					{Order: 0, Pods: []string{"pod-1"}, Completed: true},
+					{Order: 1000, Pods: []string{"pod-2"}},
+				}
+				result.EvictionWaves = waves
+			})
+
+			It("sets the EvictionWaves field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.EvictionWaves", Equal(waves)))
+			})
+		})
+
+		Context("when a ReplacementNodeSelector is set in the Result", func() {
+			var selector *metav1.LabelSelector
+
+			BeforeEach(func() {
+				selector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"node-role.kubernetes.io/worker": "true",
+					},
+				}
+				result.ReplacementNodeSelector = selector
+			})
+
+			It("sets the ReplacementNodeSelector field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.ReplacementNodeSelector", Equal(selector)))
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("when a ReplacementNode is set in the Result", func() {
+			BeforeEach(func() {
+				result.ReplacementNode = "example-worker-2"
+			})
+
+			It("sets the ReplacementNode field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.ReplacementNode", Equal("example-worker-2")))
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("when Attempts is set in the Result", func() {
+			BeforeEach(func() {
+				result.Attempts = 2
+			})
+
+			It("sets the Attempts field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.Attempts", Equal(2)))
+			})
+		})
+
+		Context("when a NextAttemptTime is set in the Result", func() {
+			var nextAttempt metav1.Time
+
+			BeforeEach(func() {
+				nextAttempt = metav1.NewTime(time.Now().Add(time.Minute).Truncate(time.Second))
+				result.NextAttemptTime = &nextAttempt
+			})
+
+			It("sets the NextAttemptTime field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.NextAttemptTime", Equal(&nextAttempt)))
+			})
+		})
+
+		Context("when an existing NextAttemptTime is set and the phase moves on", func() {
+			BeforeEach(func() {
+				m.Update(nodeReplacement, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nextAttempt := metav1.NewTime(time.Now().Add(time.Minute))
+					nr.Status.NextAttemptTime = &nextAttempt
+					return nr
+				}, timeout).Should(Succeed())
+
+				failedPhase := navarchosv1alpha1.ReplacementPhaseFailed
+				result.Phase = &failedPhase
+			})
+
+			It("clears the NextAttemptTime field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.NextAttemptTime", BeNil()))
+			})
+		})
+
+		Context("when the NodeDrainedError is set in the Result", func() {
+			BeforeEach(func() {
+				result.NodeDrainedError = errors.New("error draining node")
+				result.NodeDrainedReason = navarchosv1alpha1.ReasonErrorDrainingNode
+			})
+
+			It("sets the NodeDrained condition to False", func() {
+				m.Eventually(nodeReplacement, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.NodeDrainedType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Reason", Equal(navarchosv1alpha1.ReasonErrorDrainingNode)),
+							utils.WithField("Message", Equal("error draining node")),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when the DrainFeasibleError is set in the Result", func() {
+			var blockedPods []navarchosv1alpha1.PodReason
+
+			BeforeEach(func() {
+				blockedPods = []navarchosv1alpha1.PodReason{
+					{Name: "example-pod-1", Reason: "PodDisruptionBudget default/example allows no disruptions"},
+				}
+				result.DisruptionBlockedPods = blockedPods
+				result.DrainFeasibleError = errors.New("PodDisruptionBudgets would block the eviction of 1 pod(s)")
+				result.DrainFeasibleReason = navarchosv1alpha1.ReasonDrainBlocked
+			})
+
+			It("sets the DrainFeasible condition to False", func() {
+				m.Eventually(nodeReplacement, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.DrainFeasibleType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Reason", Equal(navarchosv1alpha1.ReasonDrainBlocked)),
+						)),
+					),
+				)
+			})
+
+			It("sets the DisruptionBlockedPods field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.DisruptionBlockedPods", Equal(blockedPods)))
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.DisruptionBlockedPodsCount", Equal(1)))
+			})
+		})
+
+		Context("when the PodsSchedulableError is set in the Result", func() {
+			var unschedulablePods []navarchosv1alpha1.PodReason
+
+			BeforeEach(func() {
+				unschedulablePods = []navarchosv1alpha1.PodReason{
+					{Name: "example-pod-1", Reason: "0/2 nodes are available: 2 Insufficient cpu."},
+				}
+				result.UnschedulablePods = unschedulablePods
+				result.PodsSchedulableError = errors.New("1 pod(s) would not fit on the remaining nodes: example-pod-1")
+				result.PodsSchedulableReason = navarchosv1alpha1.ReasonPodsUnschedulable
+			})
+
+			It("sets the PodsSchedulable condition to False", func() {
+				m.Eventually(nodeReplacement, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.PodsSchedulableType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Reason", Equal(navarchosv1alpha1.ReasonPodsUnschedulable)),
+						)),
+					),
+				)
+			})
+
+			It("sets the UnschedulablePods field", func() {
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.UnschedulablePods", Equal(unschedulablePods)))
+				m.Eventually(nodeReplacement, timeout).Should(utils.WithField("Status.UnschedulablePodsCount", Equal(1)))
+			})
+		})
+
+		Context("when the PausedReason is set to RolloutPaused in the Result", func() {
+			BeforeEach(func() {
+				result.PausedReason = navarchosv1alpha1.ReasonRolloutPaused
+			})
+
+			It("sets the Paused condition to True", func() {
+				m.Eventually(nodeReplacement, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.ReplacementPausedType)),
+							utils.WithField("Status", Equal(corev1.ConditionTrue)),
+							utils.WithField("Reason", Equal(navarchosv1alpha1.ReasonRolloutPaused)),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when the PausedReason is set to RolloutResumed in the Result", func() {
+			BeforeEach(func() {
+				result.PausedReason = navarchosv1alpha1.ReasonRolloutResumed
+			})
+
+			It("sets the Paused condition to False", func() {
+				m.Eventually(nodeReplacement, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.ReplacementPausedType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Reason", Equal(navarchosv1alpha1.ReasonRolloutResumed)),
+						)),
+					),
+				)
+			})
+		})
+
 		Context("when the NodeCordonError is not set in the Result", func() {
 			Context("and NodeCordonReason is set", func() {
 				BeforeEach(func() {
@@ -1,6 +1,8 @@
 package status
 
 import (
+	"time"
+
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -24,6 +26,16 @@ type Result struct {
 	// Requeued
 	RequeueReason string
 
+	// This should contain a short, CamelCase cause for requeueing the
+	// NodeReplacement. It is used to count requeues by reason, so it must be
+	// one of the NodeReplacementConditionReasons to keep the label values
+	// bounded
+	RequeueCause string
+
+	// This allows the Handler to requeue the object after a delay, rather than
+	// immediately. It is only used when Requeue is set
+	RequeueAfter time.Duration
+
 	// This should contain any error the controller had cordoning the node.
 	NodeCordonError error
 
@@ -31,6 +43,155 @@ type Result struct {
 	// cordoning the node
 	NodeCordonReason navarchosv1alpha1.NodeReplacementConditionReason
 
+	// This should contain any error the controller had terminating the instance
+	// backing the node, or a message describing why it is still waiting for the
+	// instance to terminate.
+	InstanceTerminatedError error
+
+	// This should contain a short description of the state of the instance
+	// termination
+	InstanceTerminatedReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had finding a replacement
+	// node, or a message describing what replacement node it is waiting for.
+	ReplacementNodeReadyError error
+
+	// This should contain a short description of the state of the replacement
+	// node
+	ReplacementNodeReadyReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had uncordoning the node
+	// after the NodeRollout was aborted.
+	NodeRestoredError error
+
+	// This should contain a short description of the state of the node after
+	// the NodeRollout was aborted
+	NodeRestoredReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had draining the node.
+	NodeDrainedError error
+
+	// This should contain a short description of the state of the drain
+	NodeDrainedReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had running the pre-cordon
+	// hook, or a message describing the state of its Job.
+	PreCordonHookError error
+
+	// This should contain a short description of the state of the pre-cordon
+	// hook
+	PreCordonHookReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had running the pre-drain
+	// hook, or a message describing the state of its Job.
+	PreDrainHookError error
+
+	// This should contain a short description of the state of the pre-drain
+	// hook
+	PreDrainHookReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had running the post-drain
+	// hook, or a message describing the state of its Job.
+	PostDrainHookError error
+
+	// This should contain a short description of the state of the post-drain
+	// hook
+	PostDrainHookReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had requesting approval,
+	// or a message describing the decision of the approval webhook.
+	ApprovedError error
+
+	// This should contain a short description of the decision of the approval
+	// webhook
+	ApprovedReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain any error the controller had checking the
+	// PodDisruptionBudgets of the pods on the node, or a message describing
+	// the pods they would block.
+	DrainFeasibleError error
+
+	// This should contain a short description of whether the
+	// PodDisruptionBudgets allow the node to be drained
+	DrainFeasibleReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should list the pods whose eviction would be blocked by a
+	// PodDisruptionBudget. It replaces the existing status list.
+	DisruptionBlockedPods []navarchosv1alpha1.PodReason
+
+	// This should contain any error the controller had simulating the
+	// rescheduling of the pods on the node, or a message describing the pods
+	// that would not fit.
+	PodsSchedulableError error
+
+	// This should contain a short description of whether the pods on the node
+	// would fit on the remaining nodes
+	PodsSchedulableReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should list the pods on the node that would not fit on the
+	// remaining nodes. It replaces the existing status list.
+	UnschedulablePods []navarchosv1alpha1.PodReason
+
+	// This should contain any error the controller had listing the pending
+	// pods, or a message listing the unschedulable pods blocking the
+	// NodeReplacement from starting.
+	PendingPodsClearedError error
+
+	// This should contain a short description of whether unschedulable pods
+	// block the NodeReplacement from starting
+	PendingPodsClearedReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// This should contain the number of failed attempts to drain the node,
+	// including the current one. If zero, the existing count is kept.
+	Attempts int
+
+	// LastAttemptTime is a timestamp for the current failed attempt to drain
+	// the node.
+	LastAttemptTime *metav1.Time
+
+	// NextAttemptTime is a timestamp for when the controller will next attempt
+	// to drain the node. It is cleared once the NodeReplacement leaves the
+	// InProgress phase.
+	NextAttemptTime *metav1.Time
+
+	// This is the short reason description for the paused state of the
+	// NodeRollout that created the NodeReplacement. RolloutPaused sets the
+	// Paused condition to True, RolloutResumed sets it to False.
+	PausedReason navarchosv1alpha1.NodeReplacementConditionReason
+
+	// CordonTimestamp is a timestamp for when the node was cordoned. This
+	// should be set on the first pass of the controller only.
+	CordonTimestamp *metav1.Time
+
+	// CordonedByController should be set if the node was schedulable until
+	// the controller cordoned it. Once set it cannot be unset.
+	CordonedByController bool
+
+	// DrainStartTimestamp is a timestamp for when the controller started
+	// evicting the pods of the node. This should be set on the first drain
+	// only.
+	DrainStartTimestamp *metav1.Time
+
+	// This should list the pods the controller is waiting for to finish before
+	// draining the node. An empty list clears the existing status list.
+	BlockingPods []navarchosv1alpha1.PodReason
+
+	// This should list the waves the pods on the node are evicted in. It
+	// replaces the existing status list.
+	EvictionWaves []navarchosv1alpha1.EvictionWave
+
+	// This should contain the settings used to drain the node, with the
+	// controller's defaults applied.
+	Drain *navarchosv1alpha1.DrainSpec
+
+	// This should contain the selector used to find a replacement node. This
+	// should be set on the first pass of the controller only.
+	ReplacementNodeSelector *metav1.LabelSelector
+
+	// This should contain the name of the node that replaced the node once it
+	// is Ready.
+	ReplacementNode string
+
 	// This should list all Pods on the Node at the time the controller cordons
 	// the node.  This should be set on the first pass of the controller only.
 	NodePods []string
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/noderollout/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// handleAborted handles a NodeRollout that has been aborted. It records the
+// nodes restored by its NodeReplacements in the result. Once none of its
+// NodeReplacements are still to be aborted it updates the phase of the
+// NodeRollout to 'Aborted'
+func (h *NodeRolloutHandler) handleAborted(instance *navarchosv1alpha1.NodeRollout) (*status.Result, error) {
+	result := &status.Result{
+		ReplacementsInProgressReason: "RolloutAborting",
+	}
+
+	nodeReplacementList := &navarchosv1alpha1.NodeReplacementList{}
+	err := h.client.List(context.Background(), nodeReplacementList)
+	if err != nil {
+		result.ReplacementsInProgressError = fmt.Errorf("failed to list NodeReplacements: %v", err)
+		result.ReplacementsInProgressReason = "ErrorListingNodes"
+		return result, result.ReplacementsInProgressError
+	}
+	owned := filterReplacementsByOwner(nodeReplacementList, instance)
+
+	result.ReplacementsCompleted = completedNodeReplacements(owned)
+	result.RestoredNodes = restoredNodeReplacements(owned)
+
+	for _, replacement := range owned {
+		switch replacement.Status.Phase {
+		case navarchosv1alpha1.ReplacementPhaseNew, navarchosv1alpha1.ReplacementPhaseInProgress, navarchosv1alpha1.ReplacementPhaseFailed, "":
+			// Still to be aborted
+			return result, nil
+		}
+	}
+
+	result.ReplacementsInProgressReason = "RolloutAborted"
+	abortedPhase := navarchosv1alpha1.RolloutPhaseAborted
+	result.Phase = &abortedPhase
+	// A failed NodeRollout has already been given a CompletionTimestamp
+	if instance.Status.CompletionTimestamp == nil {
+		now := metav1.Now()
+		result.CompletionTimestamp = &now
+	}
+
+	return result, nil
+}
+
+// restoredNodeReplacements takes a slice of replacements and returns a list of
+// the nodes' names that were made schedulable again after being aborted
+func restoredNodeReplacements(replacements []navarchosv1alpha1.NodeReplacement) []string {
+	var restoredList = []string{}
+	for _, replacement := range replacements {
+		if replacement.Status.Phase != navarchosv1alpha1.ReplacementPhaseAborted {
+			continue
+		}
+		for _, cond := range replacement.Status.Conditions {
+			if cond.Type == navarchosv1alpha1.NodeRestoredType && cond.Status == corev1.ConditionTrue {
+				restoredList = append(restoredList, replacement.Spec.NodeName)
+			}
+		}
+	}
+	return restoredList
+}
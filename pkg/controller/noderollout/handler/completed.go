@@ -6,20 +6,27 @@ import (
 
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/noderollout/status"
+	"github.com/pusher/navarchos/pkg/metrics"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// handleCompleted handles a NodeRollout in the 'Completed' phase. It checks to
-// see if the rollout is older than the cutoff defined as h.maxAge, if it is it
-// deletes the rollout
+// handleCompleted handles a NodeRollout in the 'Completed', 'Aborted' or
+// 'Failed' phase.
+// It checks to see if the rollout is older than the cutoff defined as h.maxAge,
+// if it is it deletes the rollout. Rollouts created by a NodeRolloutSchedule
+// are left to the history limits of the schedule
 func (h *NodeRolloutHandler) handleCompleted(instance *navarchosv1alpha1.NodeRollout) (*status.Result, error) {
 	result := &status.Result{}
+	if owner := metav1.GetControllerOf(instance); owner != nil && owner.Kind == "NodeRolloutSchedule" {
+		return result, nil
+	}
+
 	cutoff := metav1.NewTime(metav1.Now().Add(-h.maxAge))
 
 	if instance.Status.CompletionTimestamp != nil && instance.Status.CompletionTimestamp.Before(&cutoff) {
 		err := h.client.Delete(context.Background(), instance)
 		if err != nil {
-			// todo: expose prometheus metric
+			metrics.GCDeletionErrors.WithLabelValues("noderollout").Inc()
 			return nil, fmt.Errorf("error deleting resource: %v", err)
 		}
 	}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metalabels "k8s.io/apimachinery/pkg/labels"
+)
+
+// excludeNodes removes the nodes that have the exclude annotation, or that
+// match any of the exclude selectors, from the nodeMap. It returns the excluded
+// nodes along with the reason they were excluded
+func excludeNodes(nodeMap map[string]nodeReplacementSpec, excludeSelectors []metav1.LabelSelector) (map[string]nodeReplacementSpec, []navarchosv1alpha1.ExcludedNode, error) {
+	selectors := []metalabels.Selector{}
+	for _, ls := range excludeSelectors {
+		selector, err := metav1.LabelSelectorAsSelector(&ls)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid exclude selector: %v", err)
+		}
+		selectors = append(selectors, selector)
+	}
+
+	var excluded []navarchosv1alpha1.ExcludedNode
+	for name, spec := range nodeMap {
+		reason := exclusionReason(&spec.node, selectors)
+		if reason == "" {
+			continue
+		}
+		excluded = append(excluded, navarchosv1alpha1.ExcludedNode{
+			Name:   name,
+			Reason: reason,
+		})
+		delete(nodeMap, name)
+	}
+	sort.Slice(excluded, func(i, j int) bool {
+		return excluded[i].Name < excluded[j].Name
+	})
+	return nodeMap, excluded, nil
+}
+
+// exclusionReason returns why the node is excluded, or an empty string if it
+// is not
+func exclusionReason(node *corev1.Node, selectors []metalabels.Selector) string {
+	if navarchosv1alpha1.HasExcludeAnnotation(node) {
+		return fmt.Sprintf("node has the %s annotation", navarchosv1alpha1.ExcludeAnnotation)
+	}
+	labels := metalabels.Set(node.GetLabels())
+	for _, selector := range selectors {
+		if selector.Matches(labels) {
+			return fmt.Sprintf("node matches exclude selector %q", selector.String())
+		}
+	}
+	return ""
+}
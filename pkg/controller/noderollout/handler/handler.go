@@ -1,17 +1,21 @@
 package handler
 
 import (
+	"fmt"
 	"time"
 
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/noderollout/status"
+	"github.com/pusher/navarchos/pkg/schedule"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Options are used to configure the NodeRolloutHandler
 type Options struct {
 	// MaxAge determines the maximum age a NodeRollout should be before it is
-	// garbage collected
+	// garbage collected. It does not apply to NodeRollouts created by a
+	// NodeRolloutSchedule
 	MaxAge *time.Duration
 }
 
@@ -41,14 +45,77 @@ func NewNodeRolloutHandler(c client.Client, opts *Options) *NodeRolloutHandler {
 // Handle performs the business logic of the NodeRollout and returns information
 // in a Result
 func (h *NodeRolloutHandler) Handle(instance *navarchosv1alpha1.NodeRollout) (*status.Result, error) {
-	switch instance.Status.Phase {
-	case navarchosv1alpha1.RolloutPhaseNew:
-		return h.handleNew(instance)
-	case navarchosv1alpha1.RolloutPhaseInProgress:
-		return h.handleInProgress(instance)
-	case navarchosv1alpha1.RolloutPhaseCompleted:
-		return h.handleCompleted(instance)
+	var result *status.Result
+	var err error
+
+	phase := instance.Status.Phase
+	switch {
+	case phase == navarchosv1alpha1.RolloutPhaseCompleted, phase == navarchosv1alpha1.RolloutPhaseAborted:
+		result, err = h.handleCompleted(instance)
+	case instance.Spec.Abort:
+		result, err = h.handleAborted(instance)
+	case phase == navarchosv1alpha1.RolloutPhaseFailed:
+		result, err = h.handleCompleted(instance)
+	case phase == navarchosv1alpha1.RolloutPhaseInProgress:
+		result, err = h.handleInProgress(instance)
 	default:
-		return h.handleNew(instance)
+		result, err = h.handleNew(instance)
+	}
+
+	if result != nil {
+		result.PausedReason = pausedReason(instance)
+		if !instance.Spec.Abort && instance.Status.CompletionTimestamp == nil && result.CompletionTimestamp == nil {
+			setMaintenanceWindow(instance, result)
+		}
+	}
+	return result, err
+}
+
+// setMaintenanceWindow records whether a maintenance window of the NodeRollout
+// is open and when the next one starts in the result. The NodeRollout is
+// requeued when the next window starts so that its status stays up to date
+func setMaintenanceWindow(instance *navarchosv1alpha1.NodeRollout, result *status.Result) {
+	if len(instance.Spec.MaintenanceWindows) == 0 {
+		return
+	}
+
+	now := time.Now()
+	open, next, err := schedule.Windows(instance.Spec.MaintenanceWindows, now)
+	if err != nil {
+		result.MaintenanceWindowReason = "InvalidMaintenanceWindow"
+		result.MaintenanceWindowError = err
+		return
+	}
+
+	if !next.IsZero() {
+		nextTime := metav1.NewTime(next)
+		result.NextMaintenanceWindow = &nextTime
+		result.RequeueAfter = next.Sub(now)
+	}
+
+	if open {
+		result.MaintenanceWindowReason = "WindowOpen"
+		return
+	}
+	result.MaintenanceWindowReason = "WindowClosed"
+	if next.IsZero() {
+		result.MaintenanceWindowError = fmt.Errorf("no maintenance window starts within the next %d years", schedule.MaxSearchYears)
+		return
+	}
+	result.MaintenanceWindowError = fmt.Errorf("NodeReplacements will not start until the next maintenance window at %s", next.UTC().Format(time.RFC3339))
+}
+
+// pausedReason returns the reason for the Paused condition of the NodeRollout.
+// It is Paused while the NodeRollout is paused and Resumed once it has been
+// unpaused. If the NodeRollout has never been paused the reason is empty
+func pausedReason(instance *navarchosv1alpha1.NodeRollout) navarchosv1alpha1.NodeRolloutConditionReason {
+	if instance.Spec.Paused {
+		return navarchosv1alpha1.RolloutReasonPaused
+	}
+	for _, cond := range instance.Status.Conditions {
+		if cond.Type == navarchosv1alpha1.RolloutPausedType {
+			return navarchosv1alpha1.RolloutReasonResumed
+		}
 	}
+	return ""
 }
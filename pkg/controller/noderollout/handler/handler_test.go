@@ -418,6 +418,90 @@ var _ = Describe("Handler suite", func() {
 				})
 			})
 		})
+
+		Context("when computing the plan", func() {
+			var expectedPlan []navarchosv1alpha1.PlannedNode
+
+			BeforeEach(func() {
+				expectedPlan = []navarchosv1alpha1.PlannedNode{
+					{Name: "example-master-1", UID: masterNode1.GetUID(), Priority: 20, Selector: "nodeNames[0]", Order: 1},
+					{Name: "example-master-2", UID: masterNode2.GetUID(), Priority: 15, Selector: "nodeSelectors[0]", Order: 2},
+					{Name: "example-worker-1", UID: workerNode1.GetUID(), Priority: 10, Selector: "nodeNames[1]", Order: 3},
+					{Name: "example-worker-2", UID: workerNode2.GetUID(), Priority: 5, Selector: "nodeSelectors[1]", Order: 4},
+				}
+			})
+
+			It("sets the Result Plan", func() {
+				Expect(result.Plan).To(Equal(expectedPlan))
+			})
+
+			Context("if the NodeRollout is a dry run", func() {
+				BeforeEach(func() {
+					nodeRollout.Spec.DryRun = true
+				})
+
+				It("sets the Result Plan", func() {
+					Expect(result.Plan).To(Equal(expectedPlan))
+				})
+
+				It("does not create any NodeReplacements", func() {
+					m.Consistently(&navarchosv1alpha1.NodeReplacementList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+				})
+
+				It("does not set the Result Phase", func() {
+					Expect(result.Phase).To(BeNil())
+				})
+
+				It("sets the ReplacementsCreatedReason to DryRun", func() {
+					Expect(result.ReplacementsCreatedReason).To(Equal(navarchosv1alpha1.NodeRolloutConditionReason("DryRun")))
+					Expect(result.ReplacementsCreatedError).To(MatchError("dry run, 4 node(s) planned but no NodeReplacements created"))
+				})
+
+				It("should not return an error", func() {
+					Expect(handleErr).ToNot(HaveOccurred())
+				})
+			})
+
+			Context("if the NodeRollout has a plan", func() {
+				BeforeEach(func() {
+					nodeRollout.Status.Plan = []navarchosv1alpha1.PlannedNode{
+						{Name: "example-master-2", UID: masterNode2.GetUID(), Priority: 30, Selector: "nodeSelectors[0]", Order: 1},
+						{Name: "example-worker-1", UID: "recreated", Priority: 10, Selector: "nodeNames[1]", Order: 2},
+						{Name: "example-gone", UID: "gone", Priority: 10, Selector: "nodeNames[2]", Order: 2},
+					}
+				})
+
+				It("only creates NodeReplacements for the planned nodes, with their planned priority", func() {
+					checkForNodeReplacement("example-master-2", masterNode2, 30)
+					m.Consistently(&navarchosv1alpha1.NodeReplacementList{}, consistentlyTimeout).Should(utils.WithField("Items", HaveLen(1)))
+				})
+
+				It("does not change the Result Plan", func() {
+					Expect(result.Plan).To(BeNil())
+				})
+
+				It("excludes the nodes that differ from the plan", func() {
+					Expect(result.ExcludedNodes).To(ConsistOf(
+						navarchosv1alpha1.ExcludedNode{
+							Name:   "example-gone",
+							Reason: "node is no longer selected by the NodeRollout",
+						},
+						navarchosv1alpha1.ExcludedNode{
+							Name:   "example-master-1",
+							Reason: "node is not part of the plan of the NodeRollout",
+						},
+						navarchosv1alpha1.ExcludedNode{
+							Name:   "example-worker-1",
+							Reason: "node was recreated after the plan of the NodeRollout was computed",
+						},
+						navarchosv1alpha1.ExcludedNode{
+							Name:   "example-worker-2",
+							Reason: "node is not part of the plan of the NodeRollout",
+						},
+					))
+				})
+			})
+		})
 	})
 
 	Context("when the Handler function is called on an InProgress NodeRollout", func() {
@@ -458,6 +542,47 @@ var _ = Describe("Handler suite", func() {
 			})
 		})
 
+		Context("if the NodeRollout has never been paused", func() {
+			It("does not set the Result PausedReason field", func() {
+				Expect(result.PausedReason).To(BeEmpty())
+			})
+		})
+
+		Context("if the NodeRollout is paused", func() {
+			BeforeEach(func() {
+				m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+					nr.Spec.Paused = true
+					return nr
+				}, timeout).Should(Succeed())
+			})
+
+			It("sets the Result PausedReason field to Paused", func() {
+				Expect(result.PausedReason).To(Equal(navarchosv1alpha1.RolloutReasonPaused))
+			})
+		})
+
+		Context("if the NodeRollout has been resumed", func() {
+			BeforeEach(func() {
+				m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+					nr.Spec.Paused = false
+					nr.Status.Conditions = []navarchosv1alpha1.NodeRolloutCondition{
+						{
+							Type:   navarchosv1alpha1.RolloutPausedType,
+							Status: corev1.ConditionTrue,
+							Reason: navarchosv1alpha1.RolloutReasonPaused,
+						},
+					}
+					return nr
+				}, timeout).Should(Succeed())
+			})
+
+			It("sets the Result PausedReason field to Resumed", func() {
+				Expect(result.PausedReason).To(Equal(navarchosv1alpha1.RolloutReasonResumed))
+			})
+		})
+
 		Context("if a NodeReplacement has been marked as Completed", func() {
 			BeforeEach(func() {
 				m.Update(nrMaster1, func(obj utils.Object) utils.Object {
@@ -505,6 +630,278 @@ var _ = Describe("Handler suite", func() {
 				Expect(result.ReplacementsInProgressReason).To(Equal(navarchosv1alpha1.NodeRolloutConditionReason("ReplacementsCompleted")))
 			})
 		})
+
+		Context("if a NodeReplacement has failed", func() {
+			BeforeEach(func() {
+				m.Update(nrMaster1, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+					nr.Status.Phase = navarchosv1alpha1.ReplacementPhaseFailed
+					return nr
+				}, timeout).Should(Succeed())
+				m.Eventually(nrMaster1, timeout).Should(utils.WithField("Status.Phase", Equal(navarchosv1alpha1.ReplacementPhaseFailed)))
+			})
+
+			It("lists the failed NodeReplacement in the Result ReplacementsFailed field", func() {
+				Expect(result.ReplacementsFailed).To(ConsistOf(nrMaster1.Spec.NodeName))
+			})
+
+			It("sets the ReplacementsFailedReason", func() {
+				Expect(result.ReplacementsFailedReason).To(Equal(navarchosv1alpha1.RolloutReasonReplacementsFailed))
+			})
+
+			It("does not set the Result Phase field", func() {
+				Expect(result.Phase).To(BeNil())
+			})
+
+			Context("and the failure budget is exceeded", func() {
+				BeforeEach(func() {
+					m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+						nr.Spec.FailurePolicy = &navarchosv1alpha1.FailurePolicy{
+							MaxFailedReplacements: intPtr(0),
+						}
+						return nr
+					}, timeout).Should(Succeed())
+				})
+
+				It("sets the Result Phase field to Failed", func() {
+					failedPhase := navarchosv1alpha1.RolloutPhaseFailed
+					Expect(result.Phase).To(Equal(&failedPhase))
+					Expect(result.CompletionTimestamp).ToNot(BeNil())
+				})
+
+				It("sets the ReplacementsFailedReason to FailureBudgetExceeded", func() {
+					Expect(result.ReplacementsFailedReason).To(Equal(navarchosv1alpha1.RolloutReasonFailureBudgetExceeded))
+				})
+			})
+
+			Context("and the failure budget is not exceeded", func() {
+				BeforeEach(func() {
+					m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+						nr.Spec.FailurePolicy = &navarchosv1alpha1.FailurePolicy{
+							MaxFailedReplacements: intPtr(1),
+						}
+						return nr
+					}, timeout).Should(Succeed())
+				})
+
+				It("does not set the Result Phase field", func() {
+					Expect(result.Phase).To(BeNil())
+				})
+			})
+		})
+
+		Context("if the failed NodeReplacements have been retried", func() {
+			BeforeEach(func() {
+				m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+					nr.Status.ReplacementsFailed = []string{"example-master-1"}
+					return nr
+				}, timeout).Should(Succeed())
+			})
+
+			It("does not list any nodes in the Result ReplacementsFailed field", func() {
+				Expect(result.ReplacementsFailed).To(BeEmpty())
+			})
+
+			It("sets the ReplacementsFailedReason to NoReplacementsFailed", func() {
+				Expect(result.ReplacementsFailedReason).To(Equal(navarchosv1alpha1.RolloutReasonNoReplacementsFailed))
+			})
+		})
+
+		Context("once all NodeReplacements have completed or failed", func() {
+			BeforeEach(func() {
+				for _, nr := range []*navarchosv1alpha1.NodeReplacement{nrMaster1, nrMaster2, nrWorker1, nrWorker2} {
+					phase := navarchosv1alpha1.ReplacementPhaseCompleted
+					if nr == nrWorker2 {
+						phase = navarchosv1alpha1.ReplacementPhaseFailed
+					}
+					m.Update(nr, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+						nr.Status.Phase = phase
+						return nr
+					}, timeout).Should(Succeed())
+					m.Eventually(nr, timeout).Should(utils.WithField("Status.Phase", Equal(phase)))
+				}
+			})
+
+			It("sets the Result Phase field to Completed", func() {
+				completedPhase := navarchosv1alpha1.RolloutPhaseCompleted
+				Expect(result.Phase).To(Equal(&completedPhase))
+			})
+		})
+
+		Context("if the NodeRollout has no maintenance windows", func() {
+			It("does not set the Result MaintenanceWindowReason field", func() {
+				Expect(result.MaintenanceWindowReason).To(BeEmpty())
+				Expect(result.NextMaintenanceWindow).To(BeNil())
+			})
+		})
+
+		Context("if the NodeRollout has maintenance windows", func() {
+			var windows []navarchosv1alpha1.MaintenanceWindow
+
+			BeforeEach(func() {
+				m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+					nr.Spec.MaintenanceWindows = windows
+					return nr
+				}, timeout).Should(Succeed())
+			})
+
+			Context("and a window is open", func() {
+				BeforeEach(func() {
+					windows = []navarchosv1alpha1.MaintenanceWindow{
+						{
+							Schedule: "* * * * *",
+							Duration: metav1.Duration{Duration: time.Hour},
+						},
+					}
+				})
+
+				It("sets the Result MaintenanceWindowReason field to WindowOpen", func() {
+					Expect(result.MaintenanceWindowReason).To(Equal(navarchosv1alpha1.NodeRolloutConditionReason("WindowOpen")))
+				})
+
+				It("sets the Result NextMaintenanceWindow field", func() {
+					Expect(result.NextMaintenanceWindow).ToNot(BeNil())
+					Expect(result.NextMaintenanceWindow.Time).To(BeTemporally(">", time.Now()))
+				})
+			})
+
+			Context("and no window is open", func() {
+				BeforeEach(func() {
+					windows = []navarchosv1alpha1.MaintenanceWindow{
+						{
+							Schedule: "0 0 1 1 *",
+							Duration: metav1.Duration{Duration: time.Minute},
+							TimeZone: "Europe/London",
+						},
+					}
+				})
+
+				It("sets the Result MaintenanceWindowReason field to WindowClosed", func() {
+					Expect(result.MaintenanceWindowReason).To(Equal(navarchosv1alpha1.NodeRolloutConditionReason("WindowClosed")))
+					Expect(result.MaintenanceWindowError).To(HaveOccurred())
+				})
+
+				It("requeues the NodeRollout when the next window starts", func() {
+					Expect(result.NextMaintenanceWindow).ToNot(BeNil())
+					Expect(result.RequeueAfter).To(BeNumerically("~", time.Until(result.NextMaintenanceWindow.Time), time.Second))
+				})
+			})
+
+			Context("and a window is invalid", func() {
+				BeforeEach(func() {
+					windows = []navarchosv1alpha1.MaintenanceWindow{
+						{
+							Schedule: "* * * * *",
+							Duration: metav1.Duration{Duration: time.Hour},
+							TimeZone: "Nowhere/Special",
+						},
+					}
+				})
+
+				It("sets the Result MaintenanceWindowReason field to InvalidMaintenanceWindow", func() {
+					Expect(result.MaintenanceWindowReason).To(Equal(navarchosv1alpha1.NodeRolloutConditionReason("InvalidMaintenanceWindow")))
+					Expect(result.MaintenanceWindowError).To(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Context("when the Handler function is called on an aborted NodeRollout", func() {
+		var nrMaster1, nrWorker1 *navarchosv1alpha1.NodeReplacement
+
+		var setReplacementPhase = func(nr *navarchosv1alpha1.NodeReplacement, phase navarchosv1alpha1.NodeReplacementPhase, conditions ...navarchosv1alpha1.NodeReplacementCondition) {
+			m.Update(nr, func(obj utils.Object) utils.Object {
+				replacement, _ := obj.(*navarchosv1alpha1.NodeReplacement)
+				replacement.Status.Phase = phase
+				replacement.Status.Conditions = conditions
+				return replacement
+			}, timeout).Should(Succeed())
+			m.Eventually(nr, timeout).Should(utils.WithField("Status.Phase", Equal(phase)))
+		}
+
+		BeforeEach(func() {
+			nrMaster1 = nodeReplacementFor(masterNode1)
+			nrWorker1 = nodeReplacementFor(workerNode1)
+			m.Create(nrMaster1).Should(Succeed())
+			m.Create(nrWorker1).Should(Succeed())
+			m.Get(nrMaster1).Should(Succeed())
+			m.Get(nrWorker1).Should(Succeed())
+
+			m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+				nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+				nr.Spec.Abort = true
+				nr.Status.Phase = navarchosv1alpha1.RolloutPhaseInProgress
+				return nr
+			}, timeout).Should(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			result, handleErr = h.Handle(nodeRollout)
+		})
+
+		Context("while NodeReplacements are still to be aborted", func() {
+			BeforeEach(func() {
+				setReplacementPhase(nrMaster1, navarchosv1alpha1.ReplacementPhaseInProgress)
+			})
+
+			It("does not set the Result Phase field", func() {
+				Expect(result.Phase).To(BeNil())
+			})
+
+			It("sets the ReplacementsInProgressReason to RolloutAborting", func() {
+				Expect(result.ReplacementsInProgressReason).To(Equal(navarchosv1alpha1.NodeRolloutConditionReason("RolloutAborting")))
+			})
+
+			It("does not return an error", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("once all NodeReplacements have been aborted", func() {
+			BeforeEach(func() {
+				setReplacementPhase(nrMaster1, navarchosv1alpha1.ReplacementPhaseAborted, navarchosv1alpha1.NodeReplacementCondition{
+					Type:   navarchosv1alpha1.NodeRestoredType,
+					Status: corev1.ConditionTrue,
+					Reason: navarchosv1alpha1.ReasonNodeRestored,
+				})
+				setReplacementPhase(nrWorker1, navarchosv1alpha1.ReplacementPhaseAborted)
+			})
+
+			It("sets the Result Phase field to Aborted", func() {
+				abortedPhase := navarchosv1alpha1.RolloutPhaseAborted
+				Expect(result.Phase).To(Equal(&abortedPhase))
+				Expect(result.CompletionTimestamp).ToNot(BeNil())
+			})
+
+			It("lists the restored nodes in the Result RestoredNodes field", func() {
+				Expect(result.RestoredNodes).To(ConsistOf("example-master-1"))
+			})
+
+			It("sets the ReplacementsInProgressReason to RolloutAborted", func() {
+				Expect(result.ReplacementsInProgressReason).To(Equal(navarchosv1alpha1.NodeRolloutConditionReason("RolloutAborted")))
+			})
+		})
+
+		Context("and a NodeReplacement has already drained its node", func() {
+			BeforeEach(func() {
+				setReplacementPhase(nrMaster1, navarchosv1alpha1.ReplacementPhaseWaitingForReplacement)
+				setReplacementPhase(nrWorker1, navarchosv1alpha1.ReplacementPhaseAborted)
+			})
+
+			It("sets the Result Phase field to Aborted", func() {
+				abortedPhase := navarchosv1alpha1.RolloutPhaseAborted
+				Expect(result.Phase).To(Equal(&abortedPhase))
+			})
+
+			It("does not list any restored nodes", func() {
+				Expect(result.RestoredNodes).To(BeEmpty())
+			})
+		})
 	})
 
 	Context("when the Handler function is called on a Completed NodeRollout", func() {
@@ -546,6 +943,25 @@ var _ = Describe("Handler suite", func() {
 			It("deletes the NodeRollout", func() {
 				m.Get(nodeRollout, timeout).ShouldNot(Succeed())
 			})
+
+			Context("and the NodeRollout was created by a NodeRolloutSchedule", func() {
+				BeforeEach(func() {
+					isController := true
+					nodeRollout.SetOwnerReferences([]metav1.OwnerReference{
+						{
+							APIVersion: navarchosv1alpha1.SchemeGroupVersion.String(),
+							Kind:       "NodeRolloutSchedule",
+							Name:       utils.ExampleNodeRolloutSchedule.GetName(),
+							UID:        "example-uid",
+							Controller: &isController,
+						},
+					})
+				})
+
+				It("leaves the NodeRollout to the history limits of the schedule", func() {
+					m.Consistently(nodeRollout, consistentlyTimeout).Should(utils.WithField("ObjectMeta.DeletionTimestamp", BeNil()))
+				})
+			})
 		})
 
 	})
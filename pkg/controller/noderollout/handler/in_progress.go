@@ -10,9 +10,10 @@ import (
 )
 
 // handleInProgress handles a NodeRollout in the 'InProgress' phase. It checks
-// the number of completed NodeReplacements, and updates the result. If all
-// replacements are completed it updates the status of the NodeReplacement to
-// 'Complete'
+// the number of completed and failed NodeReplacements, and updates the result.
+// If more replacements have failed than the failure policy allows it updates
+// the status of the NodeRollout to 'Failed'. If all replacements are finished
+// it updates the status of the NodeRollout to 'Complete'
 func (h *NodeRolloutHandler) handleInProgress(instance *navarchosv1alpha1.NodeRollout) (*status.Result, error) {
 	result := &status.Result{
 		ReplacementsInProgressReason: "StillProgressing",
@@ -26,10 +27,36 @@ func (h *NodeRolloutHandler) handleInProgress(instance *navarchosv1alpha1.NodeRo
 		return result, result.ReplacementsInProgressError
 	}
 
-	completed := completedNodeReplacements(nodeReplacementList.Items)
+	owned := filterReplacementsByOwner(nodeReplacementList, instance)
+
+	completed := completedNodeReplacements(owned)
 	result.ReplacementsCompleted = completed
 
-	if len(completed) == len(nodeReplacementList.Items) {
+	failed := failedNodeReplacements(owned)
+	result.ReplacementsFailed = failed
+	if len(failed) > 0 {
+		result.ReplacementsFailedReason = navarchosv1alpha1.RolloutReasonReplacementsFailed
+	} else if len(instance.Status.ReplacementsFailed) > 0 {
+		// The failed NodeReplacements have been retried
+		result.ReplacementsFailedReason = navarchosv1alpha1.RolloutReasonNoReplacementsFailed
+	}
+
+	if failureBudgetExceeded(instance, len(failed)) {
+		result.ReplacementsFailedReason = navarchosv1alpha1.RolloutReasonFailureBudgetExceeded
+		result.ReplacementsInProgressReason = navarchosv1alpha1.RolloutReasonFailed
+		failedPhase := navarchosv1alpha1.RolloutPhaseFailed
+		result.Phase = &failedPhase
+
+		now := metav1.Now()
+		result.CompletionTimestamp = &now
+		return result, nil
+	}
+
+	// Aborted and failed replacements will never complete
+	aborted := abortedNodeReplacements(owned)
+	result.ExcludedNodes = excludedNodeReplacements(owned)
+
+	if len(completed)+len(aborted)+len(failed) == len(owned) {
 		result.ReplacementsInProgressReason = "ReplacementsCompleted"
 		completedPhase := navarchosv1alpha1.RolloutPhaseCompleted
 		result.Phase = &completedPhase
@@ -52,3 +79,58 @@ func completedNodeReplacements(replacements []navarchosv1alpha1.NodeReplacement)
 	}
 	return completedList
 }
+
+// failedNodeReplacements takes a slice of replacements and returns a list of
+// the nodes' names with the replacement phase set to failed
+func failedNodeReplacements(replacements []navarchosv1alpha1.NodeReplacement) []string {
+	var failedList = []string{}
+	for _, replacement := range replacements {
+		if replacement.Status.Phase == navarchosv1alpha1.ReplacementPhaseFailed {
+			failedList = append(failedList, replacement.Spec.NodeName)
+		}
+	}
+	return failedList
+}
+
+// failureBudgetExceeded returns true if the number of failed replacements is
+// greater than the MaxFailedReplacements of the NodeRollout's FailurePolicy
+func failureBudgetExceeded(instance *navarchosv1alpha1.NodeRollout, failed int) bool {
+	policy := instance.Spec.FailurePolicy
+	if policy == nil || policy.MaxFailedReplacements == nil {
+		return false
+	}
+	return failed > *policy.MaxFailedReplacements
+}
+
+// abortedNodeReplacements takes a slice of replacements and returns a list of
+// the nodes' names with the replacement phase set to aborted
+func abortedNodeReplacements(replacements []navarchosv1alpha1.NodeReplacement) []string {
+	var abortedList = []string{}
+	for _, replacement := range replacements {
+		if replacement.Status.Phase == navarchosv1alpha1.ReplacementPhaseAborted {
+			abortedList = append(abortedList, replacement.Spec.NodeName)
+		}
+	}
+	return abortedList
+}
+
+// excludedNodeReplacements takes a slice of replacements and returns the nodes
+// of the replacements that were aborted as their node was excluded after the
+// replacement was created
+func excludedNodeReplacements(replacements []navarchosv1alpha1.NodeReplacement) []navarchosv1alpha1.ExcludedNode {
+	var excludedList []navarchosv1alpha1.ExcludedNode
+	for _, replacement := range replacements {
+		if replacement.Status.Phase != navarchosv1alpha1.ReplacementPhaseAborted {
+			continue
+		}
+		for _, condition := range replacement.Status.Conditions {
+			if condition.Type == navarchosv1alpha1.NodeCordonedType && condition.Reason == navarchosv1alpha1.ReasonNodeExcluded {
+				excludedList = append(excludedList, navarchosv1alpha1.ExcludedNode{
+					Name:   replacement.Spec.NodeName,
+					Reason: condition.Message,
+				})
+			}
+		}
+	}
+	return excludedList
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// matchesConditions returns true if the node meets every condition
+// requirement. A requirement with a duration is only met once the condition
+// has had the status for at least that long
+func matchesConditions(requirements []navarchosv1alpha1.NodeConditionRequirement, node *corev1.Node, now time.Time) bool {
+	for _, requirement := range requirements {
+		if !matchesCondition(requirement, node, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesCondition returns true if the node has the condition with the
+// required status
+func matchesCondition(requirement navarchosv1alpha1.NodeConditionRequirement, node *corev1.Node, now time.Time) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != requirement.Type {
+			continue
+		}
+		if condition.Status != requirement.Status {
+			return false
+		}
+		if requirement.For == nil {
+			return true
+		}
+		return now.Sub(condition.LastTransitionTime.Time) >= requirement.For.Duration
+	}
+	return false
+}
+
+// matchesTaints returns true if the node has a taint matching every taint
+// requirement
+func matchesTaints(requirements []navarchosv1alpha1.TaintRequirement, node *corev1.Node) bool {
+	for _, requirement := range requirements {
+		if !hasTaint(requirement, node) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTaint returns true if the node has a taint with the key of the
+// requirement, and its value and effect when they are set
+func hasTaint(requirement navarchosv1alpha1.TaintRequirement, node *corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != requirement.Key {
+			continue
+		}
+		if requirement.Value != "" && taint.Value != requirement.Value {
+			continue
+		}
+		if requirement.Effect != "" && taint.Effect != requirement.Effect {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// matchesFields returns true if the node meets every node field requirement.
+// An error is returned if a requirement is invalid
+func matchesFields(requirements []navarchosv1alpha1.NodeFieldRequirement, node *corev1.Node) (bool, error) {
+	matches := true
+	for _, requirement := range requirements {
+		match, err := matchesField(requirement, node)
+		if err != nil {
+			return false, err
+		}
+		matches = matches && match
+	}
+	return matches, nil
+}
+
+// matchesField returns true if the node's field has the relationship to the
+// values required by the operator
+func matchesField(requirement navarchosv1alpha1.NodeFieldRequirement, node *corev1.Node) (bool, error) {
+	value, err := nodeField(requirement.Key, node)
+	if err != nil {
+		return false, err
+	}
+
+	switch requirement.Operator {
+	case navarchosv1alpha1.NodeFieldOpIn:
+		return containsString(requirement.Values, value), nil
+	case navarchosv1alpha1.NodeFieldOpNotIn:
+		return !containsString(requirement.Values, value), nil
+	case navarchosv1alpha1.NodeFieldOpLt, navarchosv1alpha1.NodeFieldOpGt:
+		if requirement.Key != navarchosv1alpha1.NodeFieldKubeletVersion {
+			return false, fmt.Errorf("operator %s is only valid for %s, not %s", requirement.Operator, navarchosv1alpha1.NodeFieldKubeletVersion, requirement.Key)
+		}
+		if len(requirement.Values) != 1 {
+			return false, fmt.Errorf("operator %s requires exactly one value, got %d", requirement.Operator, len(requirement.Values))
+		}
+		required, err := version.ParseGeneric(requirement.Values[0])
+		if err != nil {
+			return false, fmt.Errorf("invalid version %q: %v", requirement.Values[0], err)
+		}
+		// Nodes reporting a version that cannot be parsed are not selected
+		nodeVersion, err := version.ParseGeneric(value)
+		if err != nil {
+			return false, nil
+		}
+		if requirement.Operator == navarchosv1alpha1.NodeFieldOpLt {
+			return nodeVersion.LessThan(required), nil
+		}
+		return required.LessThan(nodeVersion), nil
+	default:
+		return false, fmt.Errorf("invalid node field operator %q", requirement.Operator)
+	}
+}
+
+// nodeField returns the value of the node's status.nodeInfo field
+func nodeField(field navarchosv1alpha1.NodeField, node *corev1.Node) (string, error) {
+	switch field {
+	case navarchosv1alpha1.NodeFieldKubeletVersion:
+		return node.Status.NodeInfo.KubeletVersion, nil
+	case navarchosv1alpha1.NodeFieldOSImage:
+		return node.Status.NodeInfo.OSImage, nil
+	case navarchosv1alpha1.NodeFieldContainerRuntimeVersion:
+		return node.Status.NodeInfo.ContainerRuntimeVersion, nil
+	case navarchosv1alpha1.NodeFieldKernelVersion:
+		return node.Status.NodeInfo.KernelVersion, nil
+	default:
+		return "", fmt.Errorf("invalid node field %q", field)
+	}
+}
+
+// matchesAge returns true if the node was created at least minAge ago, or if
+// no minimum age is set
+func matchesAge(minAge *metav1.Duration, node *corev1.Node, now time.Time) bool {
+	if minAge == nil {
+		return true
+	}
+	return now.Sub(node.GetCreationTimestamp().Time) >= minAge.Duration
+}
+
+// containsString returns true if the slice contains the string
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	"github.com/pusher/navarchos/pkg/controller/noderollout/status"
@@ -20,6 +21,7 @@ import (
 type nodeReplacementSpec struct {
 	node            corev1.Node
 	replacementSpec navarchosv1alpha1.NodeReplacementSpec
+	selector        string
 }
 
 // replacementCreationResult is a container struct used for returning errors and
@@ -31,7 +33,8 @@ type replacementCreationResult struct {
 
 // handleNew handles a NodeRollout in the 'New' phase. It creates
 // NodeReplacements from the provided NodeRollout instance and updates the phase
-// to in progress if it does not fail
+// to in progress if it does not fail. A dry run only records the plan of the
+// NodeRollout, once it has been recorded only the planned nodes are replaced
 func (h *NodeRolloutHandler) handleNew(instance *navarchosv1alpha1.NodeRollout) (*status.Result, error) {
 	result := &status.Result{}
 	nodes := &corev1.NodeList{}
@@ -42,14 +45,26 @@ func (h *NodeRolloutHandler) handleNew(instance *navarchosv1alpha1.NodeRollout)
 		return result, result.ReplacementsCreatedError
 	}
 
-	nodeReplacementMap := make(map[string]nodeReplacementSpec)
-	nodeReplacementMap, err = filterNodeSelectors(nodes, instance.Spec.NodeSelectors, nodeReplacementMap)
+	nodeReplacementMap, excluded, err := selectNodes(instance, nodes)
 	if err != nil {
-		result.ReplacementsCreatedError = fmt.Errorf("failed to filter nodes: %v", err)
+		result.ReplacementsCreatedError = err
 		result.ReplacementsCreatedReason = "ErrorFilteringNodes"
 		return result, result.ReplacementsCreatedError
 	}
-	nodeReplacementMap = filterNodeNames(nodes, instance.Spec.NodeNames, nodeReplacementMap)
+	result.ExcludedNodes = excluded
+
+	if instance.Spec.DryRun {
+		result.Plan = newPlan(nodeReplacementMap)
+		result.ReplacementsCreatedError = fmt.Errorf("dry run, %d node(s) planned but no NodeReplacements created", len(result.Plan))
+		result.ReplacementsCreatedReason = "DryRun"
+		return result, nil
+	}
+
+	if len(instance.Status.Plan) > 0 {
+		nodeReplacementMap, result.ExcludedNodes = applyPlan(nodeReplacementMap, instance.Status.Plan, result.ExcludedNodes)
+	} else {
+		result.Plan = newPlan(nodeReplacementMap)
+	}
 
 	outputChannel, err := h.createNodeReplacements(nodeReplacementMap, instance)
 	if err != nil {
@@ -87,29 +102,63 @@ func (h *NodeRolloutHandler) handleNew(instance *navarchosv1alpha1.NodeRollout)
 	return result, nil
 }
 
-// filterNodeSelectors filters the list of all nodes.  If a nodes labels match
-// it adds the node to the nodeMap
+// selectNodes returns the nodes selected by the NodeRollout by selector or by
+// name that are not excluded from it, along with the excluded nodes
+func selectNodes(instance *navarchosv1alpha1.NodeRollout, nodes *corev1.NodeList) (map[string]nodeReplacementSpec, []navarchosv1alpha1.ExcludedNode, error) {
+	nodeReplacementMap := make(map[string]nodeReplacementSpec)
+	nodeReplacementMap, err := filterNodeSelectors(nodes, instance.Spec.NodeSelectors, nodeReplacementMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to filter nodes: %v", err)
+	}
+	nodeReplacementMap = filterNodeNames(nodes, instance.Spec.NodeNames, nodeReplacementMap)
+	nodeReplacementMap, excluded, err := excludeNodes(nodeReplacementMap, instance.Spec.ExcludeSelectors)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to exclude nodes: %v", err)
+	}
+	return nodeReplacementMap, excluded, nil
+}
+
+// filterNodeSelectors filters the list of all nodes.  If a nodes labels,
+// conditions, taints, fields and age match it adds the node to the nodeMap
 func filterNodeSelectors(nodes *corev1.NodeList, selectors []navarchosv1alpha1.NodeLabelSelector, nodeMap map[string]nodeReplacementSpec) (map[string]nodeReplacementSpec, error) {
-	for _, nls := range selectors {
+	now := time.Now()
+	for i, nls := range selectors {
 		selector, err := metav1.LabelSelectorAsSelector(&nls.LabelSelector)
 		if err != nil {
 			return nil, err
 		}
-		// check which nodes match the LabelSelector
+		// check which nodes match the NodeLabelSelector
 		for _, node := range nodes.Items {
 			labels := metalabels.Set(node.GetLabels())
-			if selector.Matches(labels) {
-				nodeMap[node.GetName()] = newNodeReplacementSpec(node, nls.ReplacementSpec)
+			if !selector.Matches(labels) || !matchesConditions(nls.MatchConditions, &node, now) || !matchesTaints(nls.MatchTaints, &node) || !matchesAge(nls.MinAge, &node, now) {
+				continue
+			}
+			matches, err := matchesFields(nls.MatchFields, &node)
+			if err != nil {
+				return nil, err
+			}
+			if matches {
+				nodeMap[node.GetName()] = newNodeReplacementSpec(node, selectorReplacementSpec(nls), fmt.Sprintf("nodeSelectors[%d]", i))
 			}
-
 		}
 	}
 	return nodeMap, nil
 }
 
-// newNodeReplacementSpec takes a node and a ReplacementSpec and returns a
-// nodeReplacementSpec
-func newNodeReplacementSpec(node corev1.Node, replacementSpec navarchosv1alpha1.ReplacementSpec) nodeReplacementSpec {
+// selectorReplacementSpec returns the ReplacementSpec of the NodeLabelSelector.
+// If the ReplacementSpec waits for a replacement node without specifying a
+// selector for it, the NodeLabelSelector's own selector is used
+func selectorReplacementSpec(nls navarchosv1alpha1.NodeLabelSelector) navarchosv1alpha1.ReplacementSpec {
+	replacementSpec := nls.ReplacementSpec.DeepCopy()
+	if replacementSpec.WaitForReplacement != nil && replacementSpec.WaitForReplacement.Selector == nil {
+		replacementSpec.WaitForReplacement.Selector = nls.LabelSelector.DeepCopy()
+	}
+	return *replacementSpec
+}
+
+// newNodeReplacementSpec takes a node, a ReplacementSpec and the entry of the
+// NodeRollout that selected the node and returns a nodeReplacementSpec
+func newNodeReplacementSpec(node corev1.Node, replacementSpec navarchosv1alpha1.ReplacementSpec, selector string) nodeReplacementSpec {
 	return nodeReplacementSpec{
 		node: node,
 		replacementSpec: navarchosv1alpha1.NodeReplacementSpec{
@@ -117,16 +166,17 @@ func newNodeReplacementSpec(node corev1.Node, replacementSpec navarchosv1alpha1.
 			NodeName:        node.GetName(),
 			NodeUID:         node.GetUID(),
 		},
+		selector: selector,
 	}
 }
 
 // filterNodeNames filters the list of all nodes. If a nodes name matches one
 // provided it adds the node to the nodeMap
 func filterNodeNames(nodes *corev1.NodeList, nodeNames []navarchosv1alpha1.NodeName, nodeMap map[string]nodeReplacementSpec) map[string]nodeReplacementSpec {
-	for _, selectedName := range nodeNames {
+	for i, selectedName := range nodeNames {
 		for _, node := range nodes.Items {
 			if node.GetName() == selectedName.Name {
-				nodeMap[node.GetName()] = newNodeReplacementSpec(node, selectedName.ReplacementSpec)
+				nodeMap[node.GetName()] = newNodeReplacementSpec(node, selectedName.ReplacementSpec, fmt.Sprintf("nodeNames[%d]", i))
 				break
 			}
 		}
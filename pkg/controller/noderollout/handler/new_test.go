@@ -72,11 +72,11 @@ var _ = Describe("when handling new NodeRollouts", func() {
 				Expect(filteredNodes).To(SatisfyAll(
 					HaveKeyWithValue(
 						masterNode1.GetName(),
-						newNodeReplacementSpec(*masterNode1, replacementSpec1),
+						newNodeReplacementSpec(*masterNode1, replacementSpec1, "nodeSelectors[0]"),
 					),
 					HaveKeyWithValue(
 						masterNode2.GetName(),
-						newNodeReplacementSpec(*masterNode2, replacementSpec1),
+						newNodeReplacementSpec(*masterNode2, replacementSpec1, "nodeSelectors[0]"),
 					),
 					Not(HaveKey(workerNode1.GetName())),
 					Not(HaveKey(workerNode2.GetName())),
@@ -127,6 +127,263 @@ var _ = Describe("when handling new NodeRollouts", func() {
 			AssertReturnsMatchingNodes()
 
 		})
+
+		Context("when waiting for replacements without a replacement selector", func() {
+			var labelSelector metav1.LabelSelector
+
+			BeforeEach(func() {
+				labelSelector = metav1.LabelSelector{
+					MatchLabels: map[string]string{"node-role.kubernetes.io/master": "true"},
+				}
+				replacementSpec1.WaitForReplacement = &navarchosv1alpha1.WaitForReplacementSpec{}
+				selectors = []navarchosv1alpha1.NodeLabelSelector{
+					{
+						LabelSelector:   labelSelector,
+						ReplacementSpec: replacementSpec1,
+					},
+				}
+			})
+
+			It("uses the node selector as the replacement selector", func() {
+				Expect(filteredNodes).To(HaveKey(masterNode1.GetName()))
+				Expect(filteredNodes[masterNode1.GetName()].replacementSpec.ReplacementSpec.WaitForReplacement.Selector).To(Equal(&labelSelector))
+			})
+
+			It("does not modify the NodeLabelSelector", func() {
+				Expect(selectors[0].ReplacementSpec.WaitForReplacement.Selector).To(BeNil())
+			})
+		})
+
+		Context("when using MatchConditions", func() {
+			BeforeEach(func() {
+				notReadySince := metav1.NewTime(time.Now().Add(-time.Hour))
+				nodes.Items[0].Status.Conditions = []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse, LastTransitionTime: notReadySince},
+				}
+				nodes.Items[1].Status.Conditions = []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse, LastTransitionTime: metav1.Now()},
+				}
+				nodes.Items[2].Status.Conditions = []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastTransitionTime: notReadySince},
+				}
+				selectors = []navarchosv1alpha1.NodeLabelSelector{
+					{
+						MatchConditions: []navarchosv1alpha1.NodeConditionRequirement{
+							{
+								Type:   corev1.NodeReady,
+								Status: corev1.ConditionFalse,
+								For:    &metav1.Duration{Duration: 10 * time.Minute},
+							},
+						},
+						ReplacementSpec: replacementSpec1,
+					},
+				}
+			})
+
+			It("only returns nodes that have had the condition for the duration", func() {
+				Expect(filteredNodes).To(SatisfyAll(
+					HaveKey(masterNode1.GetName()),
+					Not(HaveKey(masterNode2.GetName())),
+					Not(HaveKey(workerNode1.GetName())),
+					Not(HaveKey(workerNode2.GetName())),
+				))
+			})
+
+			It("does not throw an error", func() {
+				Expect(filterError).To(BeNil())
+			})
+		})
+
+		Context("when using MatchTaints", func() {
+			BeforeEach(func() {
+				nodes.Items[0].Spec.Taints = []corev1.Taint{
+					{Key: "example.com/broken", Value: "disk", Effect: corev1.TaintEffectNoSchedule},
+				}
+				nodes.Items[1].Spec.Taints = []corev1.Taint{
+					{Key: "example.com/broken", Value: "network", Effect: corev1.TaintEffectNoExecute},
+				}
+				nodes.Items[2].Spec.Taints = []corev1.Taint{
+					{Key: "example.com/other", Value: "disk", Effect: corev1.TaintEffectNoSchedule},
+				}
+				selectors = []navarchosv1alpha1.NodeLabelSelector{
+					{
+						MatchTaints: []navarchosv1alpha1.TaintRequirement{
+							{
+								Key:    "example.com/broken",
+								Effect: corev1.TaintEffectNoSchedule,
+							},
+						},
+						ReplacementSpec: replacementSpec1,
+					},
+				}
+			})
+
+			It("only returns nodes that have a matching taint", func() {
+				Expect(filteredNodes).To(SatisfyAll(
+					HaveKey(masterNode1.GetName()),
+					Not(HaveKey(masterNode2.GetName())),
+					Not(HaveKey(workerNode1.GetName())),
+					Not(HaveKey(workerNode2.GetName())),
+				))
+			})
+		})
+
+		Context("when using MatchFields", func() {
+			BeforeEach(func() {
+				nodes.Items[0].Status.NodeInfo.KubeletVersion = "v1.15.3"
+				nodes.Items[1].Status.NodeInfo.KubeletVersion = "v1.16.0"
+				nodes.Items[2].Status.NodeInfo.KubeletVersion = "v1.14.6"
+				nodes.Items[3].Status.NodeInfo.KubeletVersion = "v1.15.3"
+				for i := range nodes.Items {
+					nodes.Items[i].Status.NodeInfo.OSImage = "Container Linux by CoreOS 2191.5.0 (Rhyolite)"
+				}
+				nodes.Items[2].Status.NodeInfo.OSImage = "Ubuntu 18.04.3 LTS"
+
+				selectors = []navarchosv1alpha1.NodeLabelSelector{
+					{
+						MatchFields: []navarchosv1alpha1.NodeFieldRequirement{
+							{
+								Key:      navarchosv1alpha1.NodeFieldKubeletVersion,
+								Operator: navarchosv1alpha1.NodeFieldOpLt,
+								Values:   []string{"v1.16"},
+							},
+							{
+								Key:      navarchosv1alpha1.NodeFieldOSImage,
+								Operator: navarchosv1alpha1.NodeFieldOpIn,
+								Values:   []string{"Container Linux by CoreOS 2191.5.0 (Rhyolite)"},
+							},
+						},
+						ReplacementSpec: replacementSpec1,
+					},
+				}
+			})
+
+			It("only returns nodes that match every field requirement", func() {
+				Expect(filteredNodes).To(SatisfyAll(
+					HaveKey(masterNode1.GetName()),
+					Not(HaveKey(masterNode2.GetName())),
+					Not(HaveKey(workerNode1.GetName())),
+					HaveKey(workerNode2.GetName()),
+				))
+			})
+
+			It("does not throw an error", func() {
+				Expect(filterError).To(BeNil())
+			})
+
+			Context("and a version operator is used with another field", func() {
+				BeforeEach(func() {
+					selectors[0].MatchFields[1].Operator = navarchosv1alpha1.NodeFieldOpGt
+				})
+
+				It("throws an error", func() {
+					Expect(filterError).To(MatchError("operator Gt is only valid for kubeletVersion, not osImage"))
+				})
+			})
+
+			Context("and the version is invalid", func() {
+				BeforeEach(func() {
+					selectors[0].MatchFields[0].Values = []string{"latest"}
+				})
+
+				It("throws an error", func() {
+					Expect(filterError).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when using MinAge", func() {
+			BeforeEach(func() {
+				for i := range nodes.Items {
+					nodes.Items[i].CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+				}
+				nodes.Items[0].CreationTimestamp = metav1.NewTime(time.Now().Add(-48 * time.Hour))
+
+				selectors = []navarchosv1alpha1.NodeLabelSelector{
+					{
+						MinAge:          &metav1.Duration{Duration: 24 * time.Hour},
+						ReplacementSpec: replacementSpec1,
+					},
+				}
+			})
+
+			It("only returns nodes that are at least the minimum age", func() {
+				Expect(filteredNodes).To(SatisfyAll(
+					HaveKey(masterNode1.GetName()),
+					Not(HaveKey(masterNode2.GetName())),
+					Not(HaveKey(workerNode1.GetName())),
+					Not(HaveKey(workerNode2.GetName())),
+				))
+			})
+		})
+	})
+
+	Context("excludeNodes", func() {
+		var excludeSelectors []metav1.LabelSelector
+		var excluded []navarchosv1alpha1.ExcludedNode
+		var excludeError error
+
+		BeforeEach(func() {
+			excludeSelectors = []metav1.LabelSelector{
+				{
+					MatchLabels: map[string]string{"node-role.kubernetes.io/master": "true"},
+				},
+			}
+			workerNode1.SetAnnotations(map[string]string{navarchosv1alpha1.ExcludeAnnotation: "true"})
+			workerNode2.SetAnnotations(map[string]string{navarchosv1alpha1.ExcludeAnnotation: "false"})
+			for _, node := range []*corev1.Node{masterNode1, masterNode2, workerNode1, workerNode2} {
+				filteredNodes[node.GetName()] = newNodeReplacementSpec(*node, replacementSpec1, "nodeSelectors[0]")
+			}
+		})
+
+		JustBeforeEach(func() {
+			filteredNodes, excluded, excludeError = excludeNodes(filteredNodes, excludeSelectors)
+		})
+
+		It("only keeps nodes that are not excluded", func() {
+			Expect(filteredNodes).To(SatisfyAll(
+				Not(HaveKey(masterNode1.GetName())),
+				Not(HaveKey(masterNode2.GetName())),
+				Not(HaveKey(workerNode1.GetName())),
+				HaveKey(workerNode2.GetName()),
+			))
+		})
+
+		It("returns the excluded nodes with the reason", func() {
+			Expect(excluded).To(ConsistOf(
+				navarchosv1alpha1.ExcludedNode{
+					Name:   masterNode1.GetName(),
+					Reason: "node matches exclude selector \"node-role.kubernetes.io/master=true\"",
+				},
+				navarchosv1alpha1.ExcludedNode{
+					Name:   masterNode2.GetName(),
+					Reason: "node matches exclude selector \"node-role.kubernetes.io/master=true\"",
+				},
+				navarchosv1alpha1.ExcludedNode{
+					Name:   workerNode1.GetName(),
+					Reason: "node has the navarchos.pusher.com/exclude annotation",
+				},
+			))
+		})
+
+		It("does not throw an error", func() {
+			Expect(excludeError).To(BeNil())
+		})
+
+		Context("when an exclude selector is invalid", func() {
+			BeforeEach(func() {
+				excludeSelectors[0].MatchExpressions = []metav1.LabelSelectorRequirement{
+					{
+						Key:      "node-role.kubernetes.io/master",
+						Operator: "Invalid",
+					},
+				}
+			})
+
+			It("throws an error", func() {
+				Expect(excludeError).To(HaveOccurred())
+			})
+		})
 	})
 
 	Context("filterNodeNames", func() {
@@ -153,11 +410,11 @@ var _ = Describe("when handling new NodeRollouts", func() {
 				Expect(filteredNodes).To(SatisfyAll(
 					HaveKeyWithValue(
 						masterNode1.GetName(),
-						newNodeReplacementSpec(*masterNode1, replacementSpec1),
+						newNodeReplacementSpec(*masterNode1, replacementSpec1, "nodeNames[0]"),
 					),
 					HaveKeyWithValue(
 						masterNode2.GetName(),
-						newNodeReplacementSpec(*masterNode2, replacementSpec2),
+						newNodeReplacementSpec(*masterNode2, replacementSpec2, "nodeNames[1]"),
 					),
 					Not(HaveKey(workerNode1.GetName())),
 					Not(HaveKey(workerNode2.GetName())),
@@ -184,6 +441,63 @@ var _ = Describe("when handling new NodeRollouts", func() {
 		})
 	})
 
+	Context("Plan", func() {
+		var nodes *corev1.NodeList
+		var plan []navarchosv1alpha1.PlannedNode
+		var excluded []navarchosv1alpha1.ExcludedNode
+		var planErr error
+
+		BeforeEach(func() {
+			workerNode2.SetAnnotations(map[string]string{navarchosv1alpha1.ExcludeAnnotation: "true"})
+			nodes = &corev1.NodeList{
+				Items: []corev1.Node{
+					*masterNode1,
+					*masterNode2,
+					*workerNode1,
+					*workerNode2,
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			plan, excluded, planErr = Plan(rollout, nodes)
+		})
+
+		It("returns the selected nodes in the order they are replaced", func() {
+			Expect(plan).To(Equal([]navarchosv1alpha1.PlannedNode{
+				{Name: masterNode1.GetName(), Priority: 20, Selector: "nodeNames[0]", Order: 1},
+				{Name: masterNode2.GetName(), Priority: 15, Selector: "nodeSelectors[0]", Order: 2},
+				{Name: workerNode1.GetName(), Priority: 10, Selector: "nodeNames[1]", Order: 3},
+			}))
+		})
+
+		It("returns the excluded nodes", func() {
+			Expect(excluded).To(ConsistOf(navarchosv1alpha1.ExcludedNode{
+				Name:   workerNode2.GetName(),
+				Reason: "node has the navarchos.pusher.com/exclude annotation",
+			}))
+		})
+
+		It("does not throw an error", func() {
+			Expect(planErr).To(BeNil())
+		})
+
+		Context("when a node selector is invalid", func() {
+			BeforeEach(func() {
+				rollout.Spec.NodeSelectors[0].MatchExpressions = []metav1.LabelSelectorRequirement{
+					{
+						Key:      "node-role.kubernetes.io/master",
+						Operator: "Invalid",
+					},
+				}
+			})
+
+			It("throws an error", func() {
+				Expect(planErr).To(HaveOccurred())
+			})
+		})
+	})
+
 	Context("filterReplacementsByOwner", func() {
 		var replacements []navarchosv1alpha1.NodeReplacement
 		var nodeReplacementList *navarchosv1alpha1.NodeReplacementList
@@ -278,10 +592,10 @@ var _ = Describe("when handling new NodeRollouts", func() {
 			m.Get(workerNode2, timeout).Should(Succeed())
 
 			filteredNodes = map[string]nodeReplacementSpec{
-				masterNode1.GetName(): newNodeReplacementSpec(*masterNode1, replacementSpec1),
-				workerNode1.GetName(): newNodeReplacementSpec(*workerNode1, replacementSpec2),
-				masterNode2.GetName(): newNodeReplacementSpec(*masterNode2, replacementSpec1),
-				workerNode2.GetName(): newNodeReplacementSpec(*workerNode2, replacementSpec2),
+				masterNode1.GetName(): newNodeReplacementSpec(*masterNode1, replacementSpec1, "nodeSelectors[0]"),
+				workerNode1.GetName(): newNodeReplacementSpec(*workerNode1, replacementSpec2, "nodeSelectors[1]"),
+				masterNode2.GetName(): newNodeReplacementSpec(*masterNode2, replacementSpec1, "nodeSelectors[0]"),
+				workerNode2.GetName(): newNodeReplacementSpec(*workerNode2, replacementSpec2, "nodeSelectors[1]"),
 			}
 		})
 
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"sort"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Plan computes the plan of the NodeRollout for the given nodes without
+// creating any NodeReplacements. It returns the planned nodes in the order
+// they are replaced and the nodes excluded from the NodeRollout
+func Plan(instance *navarchosv1alpha1.NodeRollout, nodes *corev1.NodeList) ([]navarchosv1alpha1.PlannedNode, []navarchosv1alpha1.ExcludedNode, error) {
+	nodeMap, excluded, err := selectNodes(instance, nodes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newPlan(nodeMap), excluded, nil
+}
+
+// newPlan returns the nodes of the nodeMap in the order they are replaced,
+// highest priority first. Nodes of the same priority share an order and are
+// sorted by name. The plan is empty rather than nil if no nodes are selected
+func newPlan(nodeMap map[string]nodeReplacementSpec) []navarchosv1alpha1.PlannedNode {
+	plan := []navarchosv1alpha1.PlannedNode{}
+	for name, spec := range nodeMap {
+		plan = append(plan, navarchosv1alpha1.PlannedNode{
+			Name:     name,
+			UID:      spec.node.GetUID(),
+			Priority: replacementPriority(spec.replacementSpec.ReplacementSpec),
+			Selector: spec.selector,
+		})
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		if plan[i].Priority != plan[j].Priority {
+			return plan[i].Priority > plan[j].Priority
+		}
+		return plan[i].Name < plan[j].Name
+	})
+
+	order := 0
+	for i := range plan {
+		if i == 0 || plan[i].Priority != plan[i-1].Priority {
+			order++
+		}
+		plan[i].Order = order
+	}
+	return plan
+}
+
+// applyPlan removes the nodes that are not part of the plan from the nodeMap
+// and sets the priority of the remaining nodes to their planned priority.
+// Planned nodes that are no longer selected, or that have been recreated since
+// the plan was computed, are added to the excluded nodes along with the reason
+func applyPlan(nodeMap map[string]nodeReplacementSpec, plan []navarchosv1alpha1.PlannedNode, excluded []navarchosv1alpha1.ExcludedNode) (map[string]nodeReplacementSpec, []navarchosv1alpha1.ExcludedNode) {
+	planned := make(map[string]nodeReplacementSpec)
+	for _, node := range plan {
+		spec, ok := nodeMap[node.Name]
+		switch {
+		case ok && spec.node.GetUID() != node.UID:
+			excluded = append(excluded, navarchosv1alpha1.ExcludedNode{
+				Name:   node.Name,
+				Reason: "node was recreated after the plan of the NodeRollout was computed",
+			})
+		case ok:
+			priority := node.Priority
+			spec.replacementSpec.ReplacementSpec.Priority = &priority
+			planned[node.Name] = spec
+		case !isExcluded(excluded, node.Name):
+			excluded = append(excluded, navarchosv1alpha1.ExcludedNode{
+				Name:   node.Name,
+				Reason: "node is no longer selected by the NodeRollout",
+			})
+		}
+	}
+
+	for name := range nodeMap {
+		if _, ok := planned[name]; ok || isExcluded(excluded, name) {
+			continue
+		}
+		excluded = append(excluded, navarchosv1alpha1.ExcludedNode{
+			Name:   name,
+			Reason: "node is not part of the plan of the NodeRollout",
+		})
+	}
+
+	sort.Slice(excluded, func(i, j int) bool {
+		return excluded[i].Name < excluded[j].Name
+	})
+	return planned, excluded
+}
+
+// replacementPriority returns the priority of the ReplacementSpec, defaulting
+// to 0
+func replacementPriority(spec navarchosv1alpha1.ReplacementSpec) int {
+	if spec.Priority == nil {
+		return 0
+	}
+	return *spec.Priority
+}
+
+// isExcluded returns true if the node is in the list of excluded nodes
+func isExcluded(excluded []navarchosv1alpha1.ExcludedNode, name string) bool {
+	for _, node := range excluded {
+		if node.Name == name {
+			return true
+		}
+	}
+	return false
+}
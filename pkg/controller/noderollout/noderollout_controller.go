@@ -41,13 +41,13 @@ import (
 
 // Add creates a new NodeRollout Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
-func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+func Add(mgr manager.Manager, opts *handler.Options) error {
+	return add(mgr, newReconciler(mgr, opts))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	h := handler.NewNodeRolloutHandler(mgr.GetClient(), &handler.Options{})
+func newReconciler(mgr manager.Manager, opts *handler.Options) reconcile.Reconciler {
+	h := handler.NewNodeRolloutHandler(mgr.GetClient(), opts)
 	return &ReconcileNodeRollout{Client: mgr.GetClient(), handler: h, scheme: mgr.GetScheme()}
 }
 
@@ -120,5 +120,5 @@ func (r *ReconcileNodeRollout) Reconcile(request reconcile.Request) (reconcile.R
 		return reconcile.Result{}, fmt.Errorf("error updating status: %v", err)
 	}
 
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: result.RequeueAfter}, nil
 }
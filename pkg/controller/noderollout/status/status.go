@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
@@ -25,13 +26,19 @@ func UpdateStatus(c client.Client, instance *navarchosv1alpha1.NodeRollout, resu
 		return err
 	}
 
+	setPlan(&status, result)
+	setExcludedNodes(&status, result)
 	setReplacementsCompleted(&status, result)
+	setReplacementsFailed(&status, result)
+	setRestoredNodes(&status, result)
 
 	err = setCompletionTimestamp(&status, result)
 	if err != nil {
 		return err
 	}
 
+	setNextMaintenanceWindow(&status, result)
+
 	err = setCreatedCondition(&status, result)
 	if err != nil {
 		return err
@@ -41,6 +48,10 @@ func UpdateStatus(c client.Client, instance *navarchosv1alpha1.NodeRollout, resu
 		return err
 	}
 
+	setPausedCondition(&status, result)
+	setFailedCondition(&status, result)
+	setMaintenanceWindowCondition(&status, result)
+
 	if !reflect.DeepEqual(status, instance.Status) {
 		copy := instance.DeepCopy()
 		copy.Status = status
@@ -76,6 +87,43 @@ func setReplacementsCreated(status *navarchosv1alpha1.NodeRolloutStatus, result
 	return nil
 }
 
+// setPlan sets the Plan when it is set in the Result, replacing any existing
+// plan. An empty plan clears the existing plan
+func setPlan(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	if result.Plan == nil {
+		return
+	}
+	status.Plan = nil
+	if len(result.Plan) > 0 {
+		status.Plan = result.Plan
+	}
+	status.PlannedNodesCount = len(result.Plan)
+}
+
+// setExcludedNodes sets the ExcludedNodes, if it has not been set before it is
+// added. If it has been set before the two are merged by node name
+func setExcludedNodes(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	if len(result.ExcludedNodes) == 0 {
+		return
+	}
+	for _, excluded := range result.ExcludedNodes {
+		if !hasExcludedNode(status.ExcludedNodes, excluded.Name) {
+			status.ExcludedNodes = append(status.ExcludedNodes, excluded)
+		}
+	}
+	status.ExcludedNodesCount = len(status.ExcludedNodes)
+}
+
+// hasExcludedNode returns true if the node is in the list of excluded nodes
+func hasExcludedNode(excludedNodes []navarchosv1alpha1.ExcludedNode, name string) bool {
+	for _, excluded := range excludedNodes {
+		if excluded.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // setReplacementsCompleted sets the ReplacementsCompleted, if it has not been
 // set before it is added. If it has been set before the two are appended
 func setReplacementsCompleted(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
@@ -91,6 +139,28 @@ func setReplacementsCompleted(status *navarchosv1alpha1.NodeRolloutStatus, resul
 
 }
 
+// setReplacementsFailed replaces the ReplacementsFailed when they are set in
+// the result, so that retried NodeReplacements are no longer listed
+func setReplacementsFailed(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	if result.ReplacementsFailed == nil {
+		return
+	}
+	status.ReplacementsFailed = nil
+	if len(result.ReplacementsFailed) > 0 {
+		status.ReplacementsFailed = result.ReplacementsFailed
+	}
+	status.ReplacementsFailedCount = len(status.ReplacementsFailed)
+}
+
+// setRestoredNodes sets the RestoredNodes, if it has not been set before it is
+// added. If it has been set before the two are appended
+func setRestoredNodes(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	if len(result.RestoredNodes) == 0 {
+		return
+	}
+	status.RestoredNodes = appendIfMissingStr(status.RestoredNodes, result.RestoredNodes...)
+}
+
 // setCompletionTimestamp sets the setCompletionTimestamp field. If it has not
 // been set before it is added. If it has been set before an error is returned
 func setCompletionTimestamp(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) error {
@@ -104,6 +174,18 @@ func setCompletionTimestamp(status *navarchosv1alpha1.NodeRolloutStatus, result
 	return nil
 }
 
+// setNextMaintenanceWindow sets the NextMaintenanceWindow when it is set in
+// the Result. It is cleared once the NodeRollout has completed
+func setNextMaintenanceWindow(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	if status.CompletionTimestamp != nil {
+		status.NextMaintenanceWindow = nil
+		return
+	}
+	if result.NextMaintenanceWindow != nil {
+		status.NextMaintenanceWindow = result.NextMaintenanceWindow
+	}
+}
+
 // newNodeRolloutCondition creates a new condition NodeRolloutCondition
 func newNodeRolloutCondition(condType navarchosv1alpha1.NodeRolloutConditionType, status corev1.ConditionStatus, reason navarchosv1alpha1.NodeRolloutConditionReason, message string) navarchosv1alpha1.NodeRolloutCondition {
 	return navarchosv1alpha1.NodeRolloutCondition{
@@ -128,10 +210,10 @@ func getNodeRolloutCondition(status navarchosv1alpha1.NodeRolloutStatus, condTyp
 }
 
 // setNodeRolloutCondition updates the NodeRollout to include the provided condition. If the condition that
-// we are about to add already exists and has the same status and reason then we are not going to update
+// we are about to add already exists and has the same status, reason and message then we are not going to update
 func setNodeRolloutCondition(status *navarchosv1alpha1.NodeRolloutStatus, condition navarchosv1alpha1.NodeRolloutCondition) {
 	currentCond := getNodeRolloutCondition(*status, condition.Type)
-	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
 		return
 	}
 	// Do not update lastTransitionTime if the status of the condition doesn't change
@@ -167,7 +249,8 @@ func setInProgressCondition(status *navarchosv1alpha1.NodeRolloutStatus, result
 			condition.Message = result.ReplacementsInProgressError.Error()
 		}
 
-		if result.ReplacementsInProgressReason == "ReplacementsCompleted" {
+		switch result.ReplacementsInProgressReason {
+		case "ReplacementsCompleted", "RolloutAborted", navarchosv1alpha1.RolloutReasonFailed:
 			condition.Status = corev1.ConditionFalse
 		}
 
@@ -196,6 +279,50 @@ func setCreatedCondition(status *navarchosv1alpha1.NodeRolloutStatus, result *Re
 	return nil
 }
 
+// setPausedCondition sets the Paused condition to True when the PausedReason
+// is Paused and to False when it is Resumed
+func setPausedCondition(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	switch result.PausedReason {
+	case navarchosv1alpha1.RolloutReasonPaused:
+		setNodeRolloutCondition(status, newNodeRolloutCondition(navarchosv1alpha1.RolloutPausedType, corev1.ConditionTrue, result.PausedReason, ""))
+	case navarchosv1alpha1.RolloutReasonResumed:
+		setNodeRolloutCondition(status, newNodeRolloutCondition(navarchosv1alpha1.RolloutPausedType, corev1.ConditionFalse, result.PausedReason, ""))
+	}
+}
+
+// setFailedCondition sets the ReplacementsFailed condition to True when the
+// ReplacementsFailedReason is set, listing the failed NodeReplacements in the
+// message. NoReplacementsFailed sets it to False
+func setFailedCondition(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	switch result.ReplacementsFailedReason {
+	case "":
+		return
+	case navarchosv1alpha1.RolloutReasonNoReplacementsFailed:
+		setNodeRolloutCondition(status, newNodeRolloutCondition(navarchosv1alpha1.ReplacementsFailedType, corev1.ConditionFalse, result.ReplacementsFailedReason, ""))
+		return
+	}
+	message := fmt.Sprintf("NodeReplacement(s) failed for node(s): %s", strings.Join(status.ReplacementsFailed, ", "))
+	condition := newNodeRolloutCondition(navarchosv1alpha1.ReplacementsFailedType, corev1.ConditionTrue, result.ReplacementsFailedReason, message)
+	setNodeRolloutCondition(status, condition)
+}
+
+// setMaintenanceWindowCondition sets the MaintenanceWindowOpen condition to
+// True when the MaintenanceWindowReason is WindowOpen and to False for any
+// other reason, using the MaintenanceWindowError as the message
+func setMaintenanceWindowCondition(status *navarchosv1alpha1.NodeRolloutStatus, result *Result) {
+	if result.MaintenanceWindowReason == "" {
+		return
+	}
+	condition := newNodeRolloutCondition(navarchosv1alpha1.MaintenanceWindowOpenType, corev1.ConditionTrue, result.MaintenanceWindowReason, "")
+	if result.MaintenanceWindowReason != "WindowOpen" {
+		condition.Status = corev1.ConditionFalse
+	}
+	if result.MaintenanceWindowError != nil {
+		condition.Message = result.MaintenanceWindowError.Error()
+	}
+	setNodeRolloutCondition(status, condition)
+}
+
 // appendIfMissingStr will append two []string(s) dropping duplicate elements
 func appendIfMissingStr(slice []string, str ...string) []string {
 	merged := slice
@@ -180,6 +180,142 @@ var _ = Describe("NodeRollout Status Suite", func() {
 			})
 		})
 
+		Context("when ReplacementsFailed is set in the Result", func() {
+			BeforeEach(func() {
+				result.ReplacementsFailed = []string{"example-worker-1"}
+				result.ReplacementsFailedReason = navarchosv1alpha1.RolloutReasonReplacementsFailed
+			})
+
+			It("sets the ReplacementsFailed field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.ReplacementsFailed", ConsistOf("example-worker-1")))
+			})
+
+			It("sets the ReplacementsFailedCount field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.ReplacementsFailedCount", Equal(1)))
+			})
+
+			It("sets the ReplacementsFailed condition to True listing the failed replacements", func() {
+				m.Eventually(nodeRollout, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.ReplacementsFailedType)),
+							utils.WithField("Status", Equal(corev1.ConditionTrue)),
+							utils.WithField("Reason", Equal(result.ReplacementsFailedReason)),
+							utils.WithField("Message", ContainSubstring("example-worker-1")),
+						)),
+					),
+				)
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("when a failed NodeReplacement has been retried", func() {
+			BeforeEach(func() {
+				m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+					nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+					nr.Status.ReplacementsFailed = []string{"example-worker-1"}
+					nr.Status.ReplacementsFailedCount = 1
+					return nr
+				}, timeout).Should(Succeed())
+
+				result.ReplacementsFailed = []string{}
+				result.ReplacementsFailedReason = navarchosv1alpha1.RolloutReasonNoReplacementsFailed
+			})
+
+			It("clears the ReplacementsFailed field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.ReplacementsFailed", BeEmpty()))
+			})
+
+			It("clears the ReplacementsFailedCount field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.ReplacementsFailedCount", Equal(0)))
+			})
+
+			It("sets the ReplacementsFailed condition to False", func() {
+				m.Eventually(nodeRollout, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.ReplacementsFailedType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when Plan is set in the Result", func() {
+			var plan []navarchosv1alpha1.PlannedNode
+
+			BeforeEach(func() {
+				plan = []navarchosv1alpha1.PlannedNode{
+					{Name: "example-master-1", UID: "uid-1", Priority: 20, Selector: "nodeNames[0]", Order: 1},
+					{Name: "example-worker-1", UID: "uid-2", Priority: 10, Selector: "nodeSelectors[1]", Order: 2},
+				}
+				result.Plan = plan
+			})
+
+			It("sets the Plan field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.Plan", Equal(plan)))
+			})
+
+			It("sets the PlannedNodesCount field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.PlannedNodesCount", Equal(2)))
+			})
+
+			Context("and the plan is empty", func() {
+				BeforeEach(func() {
+					m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+						nr.Status.Plan = plan
+						nr.Status.PlannedNodesCount = len(plan)
+						return nr
+					}, timeout).Should(Succeed())
+					result.Plan = []navarchosv1alpha1.PlannedNode{}
+				})
+
+				It("clears the Plan field", func() {
+					m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.Plan", BeEmpty()))
+					m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.PlannedNodesCount", Equal(0)))
+				})
+			})
+		})
+
+		Context("when ExcludedNodes is set in the Result", func() {
+			var excluded navarchosv1alpha1.ExcludedNode
+
+			BeforeEach(func() {
+				excluded = navarchosv1alpha1.ExcludedNode{
+					Name:   "example-worker-1",
+					Reason: "node has the navarchos.pusher.com/exclude annotation",
+				}
+				result.ExcludedNodes = []navarchosv1alpha1.ExcludedNode{excluded}
+			})
+
+			It("sets the ExcludedNodes field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.ExcludedNodes", ConsistOf(excluded)))
+			})
+
+			It("sets the ExcludedNodesCount field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.ExcludedNodesCount", Equal(1)))
+			})
+
+			Context("and the node is already excluded", func() {
+				BeforeEach(func() {
+					m.Update(nodeRollout, func(obj utils.Object) utils.Object {
+						nr, _ := obj.(*navarchosv1alpha1.NodeRollout)
+						nr.Status.ExcludedNodes = []navarchosv1alpha1.ExcludedNode{excluded}
+						return nr
+					}, timeout).Should(Succeed())
+				})
+
+				It("does not duplicate the node", func() {
+					m.Consistently(nodeRollout, consistentlyTimeout).Should(utils.WithField("Status.ExcludedNodes", HaveLen(1)))
+				})
+			})
+		})
+
 		Context("when no existing CompletionTimestamp is set", func() {
 			var completionTimestamp metav1.Time
 
@@ -406,6 +542,81 @@ var _ = Describe("NodeRollout Status Suite", func() {
 			})
 		})
 
+		Context("when the PausedReason is set to Paused in the Result", func() {
+			BeforeEach(func() {
+				result.PausedReason = navarchosv1alpha1.RolloutReasonPaused
+			})
+
+			It("sets the Paused condition to True", func() {
+				m.Eventually(nodeRollout, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.RolloutPausedType)),
+							utils.WithField("Status", Equal(corev1.ConditionTrue)),
+							utils.WithField("Reason", Equal(result.PausedReason)),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when the PausedReason is set to Resumed in the Result", func() {
+			BeforeEach(func() {
+				result.PausedReason = navarchosv1alpha1.RolloutReasonResumed
+			})
+
+			It("sets the Paused condition to False", func() {
+				m.Eventually(nodeRollout, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.RolloutPausedType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Reason", Equal(result.PausedReason)),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when the maintenance window is set in the Result", func() {
+			var next metav1.Time
+
+			BeforeEach(func() {
+				next = metav1.NewTime(time.Now().Add(time.Hour).Truncate(time.Second))
+				result.NextMaintenanceWindow = &next
+				result.MaintenanceWindowReason = "WindowClosed"
+				result.MaintenanceWindowError = errors.New("next window at " + next.UTC().Format(time.RFC3339))
+			})
+
+			It("sets the NextMaintenanceWindow field", func() {
+				m.Eventually(nodeRollout, timeout).Should(utils.WithField("Status.NextMaintenanceWindow", Equal(&next)))
+			})
+
+			It("sets the MaintenanceWindowOpen condition to False", func() {
+				m.Eventually(nodeRollout, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.MaintenanceWindowOpenType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Reason", Equal(result.MaintenanceWindowReason)),
+							utils.WithField("Message", Equal(result.MaintenanceWindowError.Error())),
+						)),
+					),
+				)
+			})
+
+			Context("and the NodeRollout has completed", func() {
+				BeforeEach(func() {
+					now := metav1.Now()
+					result.CompletionTimestamp = &now
+				})
+
+				It("clears the NextMaintenanceWindow field", func() {
+					m.Consistently(nodeRollout, consistentlyTimeout).Should(utils.WithField("Status.NextMaintenanceWindow", BeNil()))
+				})
+			})
+		})
+
 		Context("when the ReplacementsInProgressError is set in the Result", func() {
 			BeforeEach(func() {
 				result.ReplacementsInProgressError = errors.New("error in progress replacements")
@@ -1,6 +1,8 @@
 package status
 
 import (
+	"time"
+
 	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -27,18 +29,67 @@ type Result struct {
 	// NodeReplacements.
 	ReplacementsInProgressReason navarchosv1alpha1.NodeRolloutConditionReason
 
+	// This is the short reason description for failed NodeReplacements.
+	// ReplacementsFailed and FailureBudgetExceeded set the ReplacementsFailed
+	// condition to True, listing the failed NodeReplacements in the message.
+	// NoReplacementsFailed sets it to False.
+	ReplacementsFailedReason navarchosv1alpha1.NodeRolloutConditionReason
+
+	// This is the short reason description for the paused state of the
+	// NodeRollout. Paused sets the Paused condition to True, Resumed sets it to
+	// False.
+	PausedReason navarchosv1alpha1.NodeRolloutConditionReason
+
+	// This is the short reason description for the state of the maintenance
+	// windows of the NodeRollout. WindowOpen sets the MaintenanceWindowOpen
+	// condition to True, any other reason sets it to False.
+	MaintenanceWindowReason navarchosv1alpha1.NodeRolloutConditionReason
+
+	// This should contain any error evaluating the maintenance windows, or a
+	// message describing when the next window starts.
+	MaintenanceWindowError error
+
+	// NextMaintenanceWindow is a timestamp for when the next maintenance window
+	// of the NodeRollout starts. It is cleared once the NodeRollout has
+	// completed.
+	NextMaintenanceWindow *metav1.Time
+
+	// This allows the Handler to requeue the NodeRollout after a delay, so that
+	// its status is updated when the next maintenance window starts.
+	RequeueAfter time.Duration
+
 	// This should list all NodeReplacements created.
 	// This will be a list of the node names that are going to be replaced.
 	// This should only be set on the first pass of the controller while the
 	// NodeRollout is in Phase New.
 	ReplacementsCreated []string
 
+	// This should list the nodes replaced by the NodeRollout, in the order they
+	// are replaced. If set, it replaces the existing plan, an empty list clears
+	// it.
+	Plan []navarchosv1alpha1.PlannedNode
+
+	// This should list the nodes selected by the NodeRollout that were excluded
+	// from it, along with the reason.
+	// This list will be merged with the existing status list.
+	ExcludedNodes []navarchosv1alpha1.ExcludedNode
+
 	// This should be a list of any newly completed NodeReplacements.
 	// This will be any node name that is in the ReplacementsCreated list but
 	// does not exist on the cluster.
 	// This list will be merged with the existing status list.
 	ReplacementsCompleted []string
 
+	// This should list the nodes of all failed NodeReplacements.
+	// This list replaces the existing status list. If nil the existing list is
+	// kept.
+	ReplacementsFailed []string
+
+	// This should list the nodes that were made schedulable again by the
+	// NodeReplacements of an aborted NodeRollout.
+	// This list will be merged with the existing status list.
+	RestoredNodes []string
+
 	// CompletionTimestamp is a timestamp for when the rollout has completed
 	CompletionTimestamp *metav1.Time
 }
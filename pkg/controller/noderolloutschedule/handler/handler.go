@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/noderolloutschedule/status"
+	"github.com/pusher/navarchos/pkg/metrics"
+	"github.com/pusher/navarchos/pkg/schedule"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Options are used to configure the NodeRolloutScheduleHandler
+type Options struct {
+	// StartingDeadline determines how late a NodeRollout may be created after
+	// it was due. NodeRollouts that were missed by more than this, for
+	// example while the controller was not running, are skipped
+	StartingDeadline *time.Duration
+}
+
+// Complete defaults any values that are not explicitly set
+func (o *Options) Complete() {
+	if o.StartingDeadline == nil {
+		startingDeadline := time.Hour
+		o.StartingDeadline = &startingDeadline
+	}
+}
+
+// NodeRolloutScheduleHandler handles the business logic within the
+// NodeRolloutSchedule controller.
+type NodeRolloutScheduleHandler struct {
+	client           client.Client
+	startingDeadline time.Duration
+}
+
+// NewNodeRolloutScheduleHandler creates a new NodeRolloutScheduleHandler
+func NewNodeRolloutScheduleHandler(c client.Client, opts *Options) *NodeRolloutScheduleHandler {
+	opts.Complete()
+	return &NodeRolloutScheduleHandler{
+		client:           c,
+		startingDeadline: *opts.StartingDeadline,
+	}
+}
+
+// Handle performs the business logic of the NodeRolloutSchedule and returns
+// information in a Result. It creates a NodeRollout from the template when one
+// is due, prunes old NodeRollouts and requeues the NodeRolloutSchedule for
+// when the next NodeRollout is due
+func (h *NodeRolloutScheduleHandler) Handle(instance *navarchosv1alpha1.NodeRolloutSchedule) (*status.Result, error) {
+	// Keep the existing status should the NodeRollouts not be listed
+	result := &status.Result{
+		Active:           instance.Status.Active,
+		NextScheduleTime: instance.Status.NextScheduleTime,
+	}
+
+	rollouts, err := h.listOwnedRollouts(instance)
+	if err != nil {
+		return result, err
+	}
+	active := activeRollouts(rollouts)
+	result.Active = rolloutNames(active)
+
+	err = h.pruneHistory(instance, rollouts)
+	if err != nil {
+		return result, err
+	}
+
+	// The next schedule time is only known once the schedule has been parsed
+	result.NextScheduleTime = nil
+	sched, loc, err := parseSchedule(instance.Spec)
+	if err != nil {
+		result.RolloutScheduledReason = "InvalidSchedule"
+		result.RolloutScheduledError = err
+		return result, nil
+	}
+
+	if instance.Spec.Suspend {
+		result.RolloutScheduledReason = "Suspended"
+		result.RolloutScheduledError = fmt.Errorf("schedule is suspended, no NodeRollouts will be created")
+		return result, nil
+	}
+
+	now := time.Now().In(loc)
+	due, next := h.scheduleTimes(instance, sched, now)
+	if !next.IsZero() {
+		nextTime := metav1.NewTime(next)
+		result.NextScheduleTime = &nextTime
+		result.RequeueAfter = next.Sub(now)
+	}
+	if due.IsZero() {
+		return result, nil
+	}
+
+	if len(active) > 0 {
+		switch instance.Spec.ConcurrencyPolicy {
+		case navarchosv1alpha1.ReplaceConcurrent:
+			err = h.abortRollouts(active)
+			if err != nil {
+				result.RolloutScheduledReason = "ErrorAbortingNodeRollouts"
+				result.RolloutScheduledError = err
+				return result, err
+			}
+		default:
+			dueTime := metav1.NewTime(due)
+			result.LastScheduleTime = &dueTime
+			result.RolloutScheduledReason = "ConcurrentRolloutRunning"
+			result.RolloutScheduledError = fmt.Errorf("skipped NodeRollout due at %s as NodeRollout(s) %s are still running", due.UTC().Format(time.RFC3339), strings.Join(result.Active, ", "))
+			return result, nil
+		}
+	}
+
+	rollout := newNodeRollout(instance, due)
+	err = h.client.Create(context.Background(), rollout)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		result.RolloutScheduledReason = "ErrorCreatingNodeRollout"
+		result.RolloutScheduledError = fmt.Errorf("error creating NodeRollout %s: %v", rollout.GetName(), err)
+		return result, result.RolloutScheduledError
+	}
+
+	dueTime := metav1.NewTime(due)
+	result.LastScheduleTime = &dueTime
+	result.LastRollout = rollout.GetName()
+	result.RolloutScheduledReason = "RolloutCreated"
+	if instance.Spec.ConcurrencyPolicy == navarchosv1alpha1.ReplaceConcurrent {
+		result.Active = []string{rollout.GetName()}
+	} else {
+		result.Active = append(result.Active, rollout.GetName())
+	}
+	return result, nil
+}
+
+// parseSchedule parses the schedule of the NodeRolloutSchedule and loads the
+// time zone it is evaluated in
+func parseSchedule(spec navarchosv1alpha1.NodeRolloutScheduleSpec) (*schedule.Schedule, *time.Location, error) {
+	sched, err := schedule.Parse(spec.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %q: %v", spec.Schedule, err)
+	}
+
+	tz := spec.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid time zone %q: %v", tz, err)
+	}
+	return sched, loc, nil
+}
+
+// scheduleTimes returns the most recent time a NodeRollout was due that has
+// not yet been handled and the time the next NodeRollout is due. The due time
+// is zero if no NodeRollout is due, or if it was missed by more than the
+// starting deadline
+func (h *NodeRolloutScheduleHandler) scheduleTimes(instance *navarchosv1alpha1.NodeRolloutSchedule, sched *schedule.Schedule, now time.Time) (time.Time, time.Time) {
+	start := instance.GetCreationTimestamp().Time
+	if instance.Status.LastScheduleTime != nil {
+		start = instance.Status.LastScheduleTime.Time
+	}
+	if deadline := now.Add(-h.startingDeadline); start.Before(deadline) {
+		start = deadline
+	}
+
+	var due time.Time
+	for t := sched.Next(start.In(now.Location())); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		due = t
+	}
+	return due, sched.Next(now)
+}
+
+// newNodeRollout creates a NodeRollout from the template of the
+// NodeRolloutSchedule for the given due time. The name is derived from the due
+// time so that each NodeRollout is only created once
+func newNodeRollout(instance *navarchosv1alpha1.NodeRolloutSchedule, due time.Time) *navarchosv1alpha1.NodeRollout {
+	template := instance.Spec.RolloutTemplate.DeepCopy()
+	isController := true
+	blockOwnerDeletion := true
+	gvk := navarchosv1alpha1.SchemeGroupVersion.WithKind("NodeRolloutSchedule")
+
+	return &navarchosv1alpha1.NodeRollout{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: navarchosv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "NodeRollout",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", instance.GetName(), due.Unix()/60),
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         gvk.GroupVersion().String(),
+					Kind:               gvk.Kind,
+					Name:               instance.GetName(),
+					UID:                instance.GetUID(),
+					Controller:         &isController,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: template.Spec,
+		Status: navarchosv1alpha1.NodeRolloutStatus{
+			Phase: navarchosv1alpha1.RolloutPhaseNew,
+		},
+	}
+}
+
+// listOwnedRollouts lists the NodeRollouts controlled by the
+// NodeRolloutSchedule, oldest first
+func (h *NodeRolloutScheduleHandler) listOwnedRollouts(instance *navarchosv1alpha1.NodeRolloutSchedule) ([]navarchosv1alpha1.NodeRollout, error) {
+	rolloutList := &navarchosv1alpha1.NodeRolloutList{}
+	err := h.client.List(context.Background(), rolloutList)
+	if err != nil {
+		return nil, fmt.Errorf("error listing NodeRollouts: %v", err)
+	}
+
+	var owned []navarchosv1alpha1.NodeRollout
+	for _, rollout := range rolloutList.Items {
+		if metav1.IsControlledBy(&rollout, instance) {
+			owned = append(owned, rollout)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		return owned[i].CreationTimestamp.Before(&owned[j].CreationTimestamp)
+	})
+	return owned, nil
+}
+
+// abortRollouts sets the abort flag on each of the given NodeRollouts
+func (h *NodeRolloutScheduleHandler) abortRollouts(rollouts []navarchosv1alpha1.NodeRollout) error {
+	for _, rollout := range rollouts {
+		if rollout.Spec.Abort {
+			continue
+		}
+		copy := rollout.DeepCopy()
+		copy.Spec.Abort = true
+		err := h.client.Update(context.Background(), copy)
+		if err != nil {
+			return fmt.Errorf("error aborting NodeRollout %s: %v", rollout.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// pruneHistory deletes the oldest finished NodeRollouts beyond the history
+// limits of the NodeRolloutSchedule. Completed NodeRollouts count towards the
+// successful limit, failed and aborted NodeRollouts towards the failed limit
+func (h *NodeRolloutScheduleHandler) pruneHistory(instance *navarchosv1alpha1.NodeRolloutSchedule, rollouts []navarchosv1alpha1.NodeRollout) error {
+	var successful, failed []navarchosv1alpha1.NodeRollout
+	for _, rollout := range rollouts {
+		if rollout.Status.CompletionTimestamp == nil {
+			continue
+		}
+		if rollout.Status.Phase == navarchosv1alpha1.RolloutPhaseCompleted {
+			successful = append(successful, rollout)
+		} else {
+			failed = append(failed, rollout)
+		}
+	}
+
+	successfulLimit := 3
+	if instance.Spec.SuccessfulRolloutsHistoryLimit != nil {
+		successfulLimit = *instance.Spec.SuccessfulRolloutsHistoryLimit
+	}
+	failedLimit := 1
+	if instance.Spec.FailedRolloutsHistoryLimit != nil {
+		failedLimit = *instance.Spec.FailedRolloutsHistoryLimit
+	}
+
+	err := h.deleteOldest(successful, successfulLimit)
+	if err != nil {
+		return err
+	}
+	return h.deleteOldest(failed, failedLimit)
+}
+
+// deleteOldest deletes NodeRollouts from the start of the list until at most
+// limit remain
+func (h *NodeRolloutScheduleHandler) deleteOldest(rollouts []navarchosv1alpha1.NodeRollout, limit int) error {
+	for i := 0; i < len(rollouts)-limit; i++ {
+		err := h.client.Delete(context.Background(), &rollouts[i])
+		if err != nil && !errors.IsNotFound(err) {
+			metrics.GCDeletionErrors.WithLabelValues("noderolloutschedule").Inc()
+			return fmt.Errorf("error deleting NodeRollout %s: %v", rollouts[i].GetName(), err)
+		}
+	}
+	return nil
+}
+
+// activeRollouts returns the NodeRollouts that have not yet finished
+func activeRollouts(rollouts []navarchosv1alpha1.NodeRollout) []navarchosv1alpha1.NodeRollout {
+	var active []navarchosv1alpha1.NodeRollout
+	for _, rollout := range rollouts {
+		if rollout.Status.CompletionTimestamp == nil {
+			active = append(active, rollout)
+		}
+	}
+	return active
+}
+
+// rolloutNames returns the names of the NodeRollouts
+func rolloutNames(rollouts []navarchosv1alpha1.NodeRollout) []string {
+	var names []string
+	for _, rollout := range rollouts {
+		names = append(names, rollout.GetName())
+	}
+	return names
+}
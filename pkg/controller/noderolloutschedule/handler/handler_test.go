@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/noderolloutschedule/status"
+	"github.com/pusher/navarchos/pkg/schedule"
+	"github.com/pusher/navarchos/test/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("NodeRolloutSchedule Handler suite", func() {
+	var m utils.Matcher
+	var h *NodeRolloutScheduleHandler
+
+	var nodeRolloutSchedule *navarchosv1alpha1.NodeRolloutSchedule
+	var mgrStopped *sync.WaitGroup
+	var stopMgr chan struct{}
+
+	const timeout = time.Second * 5
+	const consistentlyTimeout = time.Second
+
+	// createRollout creates a NodeRollout owned by the NodeRolloutSchedule as
+	// if it had been due at the given time
+	var createRollout = func(due time.Time, phase navarchosv1alpha1.NodeRolloutPhase) *navarchosv1alpha1.NodeRollout {
+		rollout := newNodeRollout(nodeRolloutSchedule, due)
+		rollout.Status.Phase = phase
+		if phase != navarchosv1alpha1.RolloutPhaseNew && phase != navarchosv1alpha1.RolloutPhaseInProgress {
+			completed := metav1.NewTime(due)
+			rollout.Status.CompletionTimestamp = &completed
+		}
+		m.Create(rollout).Should(Succeed())
+		return rollout
+	}
+
+	BeforeEach(func() {
+		mgr, err := manager.New(cfg, manager.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		stopMgr, mgrStopped = StartTestManager(mgr)
+
+		h = NewNodeRolloutScheduleHandler(m.Client, &Options{})
+
+		nodeRolloutSchedule = utils.ExampleNodeRolloutSchedule.DeepCopy()
+		m.Create(nodeRolloutSchedule).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeRolloutList{},
+			&navarchosv1alpha1.NodeRolloutScheduleList{},
+		)
+	})
+
+	Context("scheduleTimes", func() {
+		var sched *schedule.Schedule
+		var now time.Time
+		var due, next time.Time
+
+		BeforeEach(func() {
+			var err error
+			sched, err = schedule.Parse("0 * * * *")
+			Expect(err).ToNot(HaveOccurred())
+			now = time.Date(2019, time.June, 1, 12, 30, 0, 0, time.UTC)
+		})
+
+		JustBeforeEach(func() {
+			due, next = h.scheduleTimes(nodeRolloutSchedule, sched, now)
+		})
+
+		It("returns when the next NodeRollout is due", func() {
+			Expect(next).To(Equal(time.Date(2019, time.June, 1, 13, 0, 0, 0, time.UTC)))
+		})
+
+		Context("when a NodeRollout has been due since the last schedule time", func() {
+			BeforeEach(func() {
+				last := metav1.NewTime(time.Date(2019, time.June, 1, 11, 0, 0, 0, time.UTC))
+				nodeRolloutSchedule.Status.LastScheduleTime = &last
+			})
+
+			It("returns the most recent due time", func() {
+				Expect(due).To(Equal(time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)))
+			})
+		})
+
+		Context("when no NodeRollout has been due since the last schedule time", func() {
+			BeforeEach(func() {
+				last := metav1.NewTime(time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC))
+				nodeRolloutSchedule.Status.LastScheduleTime = &last
+			})
+
+			It("returns a zero due time", func() {
+				Expect(due.IsZero()).To(BeTrue())
+			})
+		})
+
+		Context("when the NodeRollout was missed by more than the starting deadline", func() {
+			BeforeEach(func() {
+				startingDeadline := 15 * time.Minute
+				h = NewNodeRolloutScheduleHandler(m.Client, &Options{StartingDeadline: &startingDeadline})
+				last := metav1.NewTime(time.Date(2019, time.June, 1, 11, 0, 0, 0, time.UTC))
+				nodeRolloutSchedule.Status.LastScheduleTime = &last
+			})
+
+			It("returns a zero due time", func() {
+				Expect(due.IsZero()).To(BeTrue())
+			})
+		})
+	})
+
+	Context("Handle", func() {
+		var result *status.Result
+		var handleErr error
+
+		BeforeEach(func() {
+			// Schedule a NodeRollout every minute, the last of which was
+			// handled two minutes ago
+			nodeRolloutSchedule.Spec.Schedule = "* * * * *"
+			last := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+			nodeRolloutSchedule.Status.LastScheduleTime = &last
+		})
+
+		JustBeforeEach(func() {
+			result, handleErr = h.Handle(nodeRolloutSchedule)
+		})
+
+		Context("when a NodeRollout is due", func() {
+			It("creates a NodeRollout controlled by the NodeRolloutSchedule", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+				rollout := &navarchosv1alpha1.NodeRollout{ObjectMeta: metav1.ObjectMeta{Name: result.LastRollout}}
+				m.Get(rollout, timeout).Should(Succeed())
+				Expect(metav1.IsControlledBy(rollout, nodeRolloutSchedule)).To(BeTrue())
+			})
+
+			It("creates the NodeRollout from the template", func() {
+				rollout := &navarchosv1alpha1.NodeRollout{ObjectMeta: metav1.ObjectMeta{Name: result.LastRollout}}
+				m.Get(rollout, timeout).Should(Succeed())
+				Expect(rollout.GetLabels()).To(Equal(nodeRolloutSchedule.Spec.RolloutTemplate.Labels))
+				Expect(rollout.Spec.NodeSelectors).To(Equal(nodeRolloutSchedule.Spec.RolloutTemplate.Spec.NodeSelectors))
+			})
+
+			It("records the NodeRollout as active", func() {
+				Expect(result.Active).To(ConsistOf(result.LastRollout))
+			})
+
+			It("sets the RolloutScheduledReason to RolloutCreated", func() {
+				Expect(result.RolloutScheduledReason).To(Equal(navarchosv1alpha1.NodeRolloutScheduleConditionReason("RolloutCreated")))
+				Expect(result.LastScheduleTime).ToNot(BeNil())
+			})
+
+			It("requeues the NodeRolloutSchedule for the next NodeRollout", func() {
+				Expect(result.NextScheduleTime).ToNot(BeNil())
+				Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+			})
+		})
+
+		Context("when no NodeRollout is due", func() {
+			BeforeEach(func() {
+				nodeRolloutSchedule.Spec.Schedule = "0 2 * * *"
+				last := metav1.Now()
+				nodeRolloutSchedule.Status.LastScheduleTime = &last
+			})
+
+			It("does not create a NodeRollout", func() {
+				m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+			})
+
+			It("sets the NextScheduleTime", func() {
+				Expect(result.NextScheduleTime).ToNot(BeNil())
+				Expect(result.RolloutScheduledReason).To(BeEmpty())
+			})
+		})
+
+		Context("when a previous NodeRollout is still running", func() {
+			var running *navarchosv1alpha1.NodeRollout
+
+			BeforeEach(func() {
+				running = createRollout(time.Now().Add(-time.Hour), navarchosv1alpha1.RolloutPhaseInProgress)
+			})
+
+			Context("and the ConcurrencyPolicy is Forbid", func() {
+				It("does not create a NodeRollout", func() {
+					m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", HaveLen(1)))
+				})
+
+				It("sets the RolloutScheduledReason to ConcurrentRolloutRunning", func() {
+					Expect(result.RolloutScheduledReason).To(Equal(navarchosv1alpha1.NodeRolloutScheduleConditionReason("ConcurrentRolloutRunning")))
+					Expect(result.RolloutScheduledError).To(MatchError(ContainSubstring(running.GetName())))
+				})
+
+				It("skips the NodeRollout", func() {
+					Expect(result.LastScheduleTime).ToNot(BeNil())
+					Expect(result.Active).To(ConsistOf(running.GetName()))
+				})
+			})
+
+			Context("and the ConcurrencyPolicy is Replace", func() {
+				BeforeEach(func() {
+					nodeRolloutSchedule.Spec.ConcurrencyPolicy = navarchosv1alpha1.ReplaceConcurrent
+				})
+
+				It("aborts the running NodeRollout", func() {
+					m.Eventually(running, timeout).Should(utils.WithField("Spec.Abort", BeTrue()))
+				})
+
+				It("creates a new NodeRollout", func() {
+					m.Eventually(&navarchosv1alpha1.NodeRolloutList{}, timeout).Should(utils.WithField("Items", HaveLen(2)))
+					Expect(result.Active).To(ConsistOf(result.LastRollout))
+				})
+			})
+		})
+
+		Context("when the NodeRolloutSchedule is suspended", func() {
+			BeforeEach(func() {
+				nodeRolloutSchedule.Spec.Suspend = true
+			})
+
+			It("does not create a NodeRollout", func() {
+				m.Consistently(&navarchosv1alpha1.NodeRolloutList{}, consistentlyTimeout).Should(utils.WithField("Items", BeEmpty()))
+			})
+
+			It("sets the RolloutScheduledReason to Suspended", func() {
+				Expect(result.RolloutScheduledReason).To(Equal(navarchosv1alpha1.NodeRolloutScheduleConditionReason("Suspended")))
+				Expect(result.NextScheduleTime).To(BeNil())
+			})
+		})
+
+		Context("when the schedule is invalid", func() {
+			BeforeEach(func() {
+				nodeRolloutSchedule.Spec.Schedule = "every day"
+			})
+
+			It("sets the RolloutScheduledReason to InvalidSchedule", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+				Expect(result.RolloutScheduledReason).To(Equal(navarchosv1alpha1.NodeRolloutScheduleConditionReason("InvalidSchedule")))
+				Expect(result.RolloutScheduledError).To(HaveOccurred())
+			})
+		})
+
+		Context("when there are more finished NodeRollouts than the history limits", func() {
+			var completed []*navarchosv1alpha1.NodeRollout
+			var failed []*navarchosv1alpha1.NodeRollout
+
+			BeforeEach(func() {
+				nodeRolloutSchedule.Spec.Suspend = true
+				nodeRolloutSchedule.Spec.SuccessfulRolloutsHistoryLimit = intPtr(1)
+
+				now := time.Now()
+				completed = []*navarchosv1alpha1.NodeRollout{
+					createRollout(now.Add(-3*time.Hour), navarchosv1alpha1.RolloutPhaseCompleted),
+					createRollout(now.Add(-2*time.Hour), navarchosv1alpha1.RolloutPhaseCompleted),
+				}
+				failed = []*navarchosv1alpha1.NodeRollout{
+					createRollout(now.Add(-5*time.Hour), navarchosv1alpha1.RolloutPhaseFailed),
+					createRollout(now.Add(-4*time.Hour), navarchosv1alpha1.RolloutPhaseAborted),
+				}
+			})
+
+			It("deletes the oldest completed NodeRollouts", func() {
+				Expect(handleErr).ToNot(HaveOccurred())
+				m.Eventually(&navarchosv1alpha1.NodeRolloutList{}, timeout).ShouldNot(utils.WithField("Items", ContainElement(
+					utils.WithField("ObjectMeta.Name", Equal(completed[0].GetName())),
+				)))
+				Expect(m.Client.Get(context.TODO(), client.ObjectKey{Name: completed[1].GetName()}, completed[1])).To(Succeed())
+			})
+
+			It("deletes the oldest failed and aborted NodeRollouts", func() {
+				m.Eventually(&navarchosv1alpha1.NodeRolloutList{}, timeout).ShouldNot(utils.WithField("Items", ContainElement(
+					utils.WithField("ObjectMeta.Name", Equal(failed[0].GetName())),
+				)))
+				Expect(m.Client.Get(context.TODO(), client.ObjectKey{Name: failed[1].GetName()}, failed[1])).To(Succeed())
+			})
+		})
+	})
+})
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderolloutschedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/pkg/controller/noderolloutschedule/handler"
+	"github.com/pusher/navarchos/pkg/controller/noderolloutschedule/status"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	watchhandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Add creates a new NodeRolloutSchedule Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, opts *handler.Options) error {
+	return add(mgr, newReconciler(mgr, opts))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, opts *handler.Options) reconcile.Reconciler {
+	h := handler.NewNodeRolloutScheduleHandler(mgr.GetClient(), opts)
+	return &ReconcileNodeRolloutSchedule{Client: mgr.GetClient(), handler: h, scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("noderolloutschedule-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to NodeRolloutSchedule
+	err = c.Watch(&source.Kind{Type: &navarchosv1alpha1.NodeRolloutSchedule{}}, &watchhandler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for NodeRollouts created by NodeRolloutSchedule
+	err = c.Watch(&source.Kind{Type: &navarchosv1alpha1.NodeRollout{}}, &watchhandler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &navarchosv1alpha1.NodeRolloutSchedule{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileNodeRolloutSchedule{}
+
+// ReconcileNodeRolloutSchedule reconciles a NodeRolloutSchedule object
+type ReconcileNodeRolloutSchedule struct {
+	client.Client
+	handler *handler.NodeRolloutScheduleHandler
+	scheme  *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a NodeRolloutSchedule object and makes changes based on the state read
+// and what is in the NodeRolloutSchedule.Spec
+// +kubebuilder:rbac:groups=navarchos.pusher.com,resources=noderolloutschedules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=navarchos.pusher.com,resources=noderolloutschedules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=navarchos.pusher.com,resources=noderollouts,verbs=get;list;watch;create;update;patch;delete
+func (r *ReconcileNodeRolloutSchedule) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the NodeRolloutSchedule instance
+	instance := &navarchosv1alpha1.NodeRolloutSchedule{}
+	err := r.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	result, err := r.handler.Handle(instance)
+	if err != nil {
+		// Ensure we attempt to update the status even when the handler fails
+		statusErr := status.UpdateStatus(r.Client, instance, result)
+		if statusErr != nil {
+			log.Printf("error updating status: %v", statusErr)
+		}
+
+		return reconcile.Result{}, fmt.Errorf("error handling rollout schedule %s: %+v", instance.GetName(), err)
+	}
+	err = status.UpdateStatus(r.Client, instance, result)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("error updating status: %v", err)
+	}
+
+	return reconcile.Result{RequeueAfter: result.RequeueAfter}, nil
+}
@@ -0,0 +1,133 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UpdateStatus merges the status in the existing instance with the information
+// provided in the Result and then updates the instance if there is any
+// difference between the new and updated status
+func UpdateStatus(c client.Client, instance *navarchosv1alpha1.NodeRolloutSchedule, result *Result) error {
+	status := instance.Status
+
+	setActive(&status, result)
+	setLastSchedule(&status, result)
+	status.NextScheduleTime = result.NextScheduleTime
+
+	err := setRolloutScheduledCondition(&status, result)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(status, instance.Status) {
+		copy := instance.DeepCopy()
+		copy.Status = status
+
+		err := c.Update(context.TODO(), copy)
+		if err != nil {
+			return fmt.Errorf("error updating status: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// setActive sets the Active NodeRollouts and their count
+func setActive(status *navarchosv1alpha1.NodeRolloutScheduleStatus, result *Result) {
+	status.Active = result.Active
+	status.ActiveCount = len(result.Active)
+}
+
+// setLastSchedule sets the LastScheduleTime and LastRollout when they are set
+// in the Result
+func setLastSchedule(status *navarchosv1alpha1.NodeRolloutScheduleStatus, result *Result) {
+	if result.LastScheduleTime != nil {
+		status.LastScheduleTime = result.LastScheduleTime
+	}
+	if result.LastRollout != "" {
+		status.LastRollout = result.LastRollout
+	}
+}
+
+// setRolloutScheduledCondition sets the RolloutScheduled condition to True
+// when the RolloutScheduledReason is RolloutCreated and to False for any other
+// reason, using the RolloutScheduledError as the message
+func setRolloutScheduledCondition(status *navarchosv1alpha1.NodeRolloutScheduleStatus, result *Result) error {
+	if result.RolloutScheduledError != nil && result.RolloutScheduledReason == "" {
+		return fmt.Errorf("if RolloutScheduledError is set, RolloutScheduledReason must also be set")
+	}
+	if result.RolloutScheduledReason == "" {
+		return nil
+	}
+
+	condition := newNodeRolloutScheduleCondition(navarchosv1alpha1.RolloutScheduledType, corev1.ConditionTrue, result.RolloutScheduledReason, "")
+	if result.RolloutScheduledReason != "RolloutCreated" {
+		condition.Status = corev1.ConditionFalse
+	}
+	if result.RolloutScheduledError != nil {
+		condition.Message = result.RolloutScheduledError.Error()
+	}
+	setNodeRolloutScheduleCondition(status, condition)
+	return nil
+}
+
+// newNodeRolloutScheduleCondition creates a new NodeRolloutScheduleCondition
+func newNodeRolloutScheduleCondition(condType navarchosv1alpha1.NodeRolloutScheduleConditionType, status corev1.ConditionStatus, reason navarchosv1alpha1.NodeRolloutScheduleConditionReason, message string) navarchosv1alpha1.NodeRolloutScheduleCondition {
+	return navarchosv1alpha1.NodeRolloutScheduleCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// getNodeRolloutScheduleCondition returns the condition with the provided type
+func getNodeRolloutScheduleCondition(status navarchosv1alpha1.NodeRolloutScheduleStatus, condType navarchosv1alpha1.NodeRolloutScheduleConditionType) *navarchosv1alpha1.NodeRolloutScheduleCondition {
+	for i := range status.Conditions {
+		c := status.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// setNodeRolloutScheduleCondition updates the NodeRolloutSchedule to include
+// the provided condition. If the condition that we are about to add already
+// exists and has the same status, reason and message then we are not going to
+// update
+func setNodeRolloutScheduleCondition(status *navarchosv1alpha1.NodeRolloutScheduleStatus, condition navarchosv1alpha1.NodeRolloutScheduleCondition) {
+	currentCond := getNodeRolloutScheduleCondition(*status, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	newConditions := filterOutCondition(status.Conditions, condition.Type)
+	status.Conditions = append(newConditions, condition)
+}
+
+// filterOutCondition returns a new slice of NodeRolloutSchedule conditions
+// without conditions with the provided types
+func filterOutCondition(conditions []navarchosv1alpha1.NodeRolloutScheduleCondition, condType navarchosv1alpha1.NodeRolloutScheduleConditionType) []navarchosv1alpha1.NodeRolloutScheduleCondition {
+	var newConditions []navarchosv1alpha1.NodeRolloutScheduleCondition
+	for _, c := range conditions {
+		if c.Type == condType {
+			continue
+		}
+		newConditions = append(newConditions, c)
+	}
+	return newConditions
+}
@@ -0,0 +1,169 @@
+package status
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"github.com/pusher/navarchos/test/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("NodeRolloutSchedule Status Suite", func() {
+	var c client.Client
+	var m utils.Matcher
+
+	var nodeRolloutSchedule *navarchosv1alpha1.NodeRolloutSchedule
+	var result *Result
+
+	const timeout = time.Second * 5
+	const consistentlyTimeout = time.Second
+
+	BeforeEach(func() {
+		var err error
+		c, err = client.New(cfg, client.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		m = utils.Matcher{Client: c}
+
+		nodeRolloutSchedule = utils.ExampleNodeRolloutSchedule.DeepCopy()
+		m.Create(nodeRolloutSchedule).Should(Succeed())
+
+		result = &Result{}
+	})
+
+	AfterEach(func() {
+		utils.DeleteAll(cfg, timeout,
+			&navarchosv1alpha1.NodeRolloutScheduleList{},
+		)
+	})
+
+	Context("UpdateStatus", func() {
+		var updateErr error
+
+		JustBeforeEach(func() {
+			updateErr = UpdateStatus(c, nodeRolloutSchedule, result)
+		})
+
+		Context("when Active is set in the Result", func() {
+			var active []string
+
+			BeforeEach(func() {
+				active = []string{"example-1", "example-2"}
+				result.Active = active
+			})
+
+			It("sets the Active field", func() {
+				m.Eventually(nodeRolloutSchedule, timeout).Should(utils.WithField("Status.Active", Equal(active)))
+			})
+
+			It("sets the ActiveCount field", func() {
+				m.Eventually(nodeRolloutSchedule, timeout).Should(utils.WithField("Status.ActiveCount", Equal(len(active))))
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("when the LastScheduleTime and LastRollout are set in the Result", func() {
+			var last metav1.Time
+
+			BeforeEach(func() {
+				last = metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+				result.LastScheduleTime = &last
+				result.LastRollout = "example-1"
+			})
+
+			It("sets the LastScheduleTime field", func() {
+				m.Eventually(nodeRolloutSchedule, timeout).Should(utils.WithField("Status.LastScheduleTime", Equal(&last)))
+			})
+
+			It("sets the LastRollout field", func() {
+				m.Eventually(nodeRolloutSchedule, timeout).Should(utils.WithField("Status.LastRollout", Equal("example-1")))
+			})
+		})
+
+		Context("when an existing LastRollout is set and the Result does not set one", func() {
+			BeforeEach(func() {
+				m.Update(nodeRolloutSchedule, func(obj utils.Object) utils.Object {
+					nrs, _ := obj.(*navarchosv1alpha1.NodeRolloutSchedule)
+					nrs.Status.LastRollout = "example-1"
+					return nrs
+				}, timeout).Should(Succeed())
+			})
+
+			It("does not clear the LastRollout field", func() {
+				m.Consistently(nodeRolloutSchedule, consistentlyTimeout).Should(utils.WithField("Status.LastRollout", Equal("example-1")))
+			})
+		})
+
+		Context("when the NextScheduleTime is set in the Result", func() {
+			var next metav1.Time
+
+			BeforeEach(func() {
+				next = metav1.NewTime(time.Now().Add(time.Hour).Truncate(time.Second))
+				result.NextScheduleTime = &next
+			})
+
+			It("sets the NextScheduleTime field", func() {
+				m.Eventually(nodeRolloutSchedule, timeout).Should(utils.WithField("Status.NextScheduleTime", Equal(&next)))
+			})
+		})
+
+		Context("when the RolloutScheduledReason is RolloutCreated", func() {
+			BeforeEach(func() {
+				result.RolloutScheduledReason = "RolloutCreated"
+			})
+
+			It("sets the RolloutScheduled condition to True", func() {
+				m.Eventually(nodeRolloutSchedule, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.RolloutScheduledType)),
+							utils.WithField("Status", Equal(corev1.ConditionTrue)),
+							utils.WithField("Reason", Equal(result.RolloutScheduledReason)),
+						)),
+					),
+				)
+			})
+		})
+
+		Context("when the RolloutScheduledError is set in the Result", func() {
+			BeforeEach(func() {
+				result.RolloutScheduledReason = "ConcurrentRolloutRunning"
+				result.RolloutScheduledError = errors.New("NodeRollout example-1 is still running")
+			})
+
+			It("sets the RolloutScheduled condition to False", func() {
+				m.Eventually(nodeRolloutSchedule, timeout).Should(
+					utils.WithField("Status.Conditions",
+						ContainElement(SatisfyAll(
+							utils.WithField("Type", Equal(navarchosv1alpha1.RolloutScheduledType)),
+							utils.WithField("Status", Equal(corev1.ConditionFalse)),
+							utils.WithField("Reason", Equal(result.RolloutScheduledReason)),
+							utils.WithField("Message", Equal(result.RolloutScheduledError.Error())),
+						)),
+					),
+				)
+			})
+
+			It("does not cause an error", func() {
+				Expect(updateErr).To(BeNil())
+			})
+		})
+
+		Context("if only RolloutScheduledError is set", func() {
+			BeforeEach(func() {
+				result.RolloutScheduledError = errors.New("error")
+			})
+
+			It("causes an error", func() {
+				Expect(updateErr).To(MatchError("if RolloutScheduledError is set, RolloutScheduledReason must also be set"))
+			})
+		})
+	})
+})
@@ -0,0 +1,42 @@
+package status
+
+import (
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Result is used as the basis to updating the status of the
+// NodeRolloutSchedule. It contains information gathered during a single run of
+// the reconcile loop.
+type Result struct {
+	// This should list the names of the NodeRollouts created by the schedule
+	// that have not yet finished.
+	// This list replaces the existing status list.
+	Active []string
+
+	// LastScheduleTime is a timestamp for when a NodeRollout was last due.
+	// If LastScheduleTime == nil, don't update it.
+	LastScheduleTime *metav1.Time
+
+	// LastRollout is the name of the NodeRollout created by the schedule.
+	// If LastRollout is empty, don't update it.
+	LastRollout string
+
+	// NextScheduleTime is a timestamp for when the next NodeRollout is due.
+	// If NextScheduleTime == nil, it is cleared.
+	NextScheduleTime *metav1.Time
+
+	// This is the short reason description for the RolloutScheduled
+	// condition. RolloutCreated sets the condition to True, any other reason
+	// sets it to False.
+	RolloutScheduledReason navarchosv1alpha1.NodeRolloutScheduleConditionReason
+
+	// This should contain any errors related to scheduling the NodeRollout.
+	RolloutScheduledError error
+
+	// This allows the Handler to requeue the NodeRolloutSchedule after a
+	// delay, so that it is reconciled when the next NodeRollout is due.
+	RequeueAfter time.Duration
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	rolloutsDesc = prometheus.NewDesc(
+		"navarchos_noderollouts",
+		"Number of NodeRollouts by phase",
+		[]string{"phase"}, nil,
+	)
+
+	replacementsDesc = prometheus.NewDesc(
+		"navarchos_nodereplacements",
+		"Number of NodeReplacements by phase and priority",
+		[]string{"phase", "priority"}, nil,
+	)
+)
+
+// collector counts the NodeRollouts and NodeReplacements in the cluster each
+// time the metrics are scraped
+type collector struct {
+	client client.Client
+}
+
+// NewCollector returns a prometheus.Collector reporting the number of
+// NodeRollouts by phase and NodeReplacements by phase and priority. The
+// resources are listed with the given client when the metrics are scraped
+func NewCollector(c client.Client) prometheus.Collector {
+	return &collector{client: c}
+}
+
+// Describe implements the prometheus.Collector interface
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rolloutsDesc
+	ch <- replacementsDesc
+}
+
+// Collect implements the prometheus.Collector interface. If the resources
+// cannot be listed an invalid metric is reported instead
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	rollouts := &navarchosv1alpha1.NodeRolloutList{}
+	if err := c.client.List(context.Background(), rollouts); err != nil {
+		ch <- prometheus.NewInvalidMetric(rolloutsDesc, fmt.Errorf("error listing NodeRollouts: %v", err))
+	} else {
+		collectRollouts(ch, rollouts.Items)
+	}
+
+	replacements := &navarchosv1alpha1.NodeReplacementList{}
+	if err := c.client.List(context.Background(), replacements); err != nil {
+		ch <- prometheus.NewInvalidMetric(replacementsDesc, fmt.Errorf("error listing NodeReplacements: %v", err))
+	} else {
+		collectReplacements(ch, replacements.Items)
+	}
+}
+
+// collectRollouts reports the number of NodeRollouts in each phase
+func collectRollouts(ch chan<- prometheus.Metric, rollouts []navarchosv1alpha1.NodeRollout) {
+	counts := make(map[navarchosv1alpha1.NodeRolloutPhase]int)
+	for _, rollout := range rollouts {
+		counts[rollout.Status.Phase]++
+	}
+	for phase, count := range counts {
+		ch <- prometheus.MustNewConstMetric(rolloutsDesc, prometheus.GaugeValue, float64(count), string(phase))
+	}
+}
+
+// replacementKey groups NodeReplacements by phase and priority
+type replacementKey struct {
+	phase    navarchosv1alpha1.NodeReplacementPhase
+	priority int
+}
+
+// collectReplacements reports the number of NodeReplacements for each
+// combination of phase and priority. NodeReplacements without a priority
+// count as priority 0
+func collectReplacements(ch chan<- prometheus.Metric, replacements []navarchosv1alpha1.NodeReplacement) {
+	counts := make(map[replacementKey]int)
+	for _, replacement := range replacements {
+		key := replacementKey{phase: replacement.Status.Phase}
+		if priority := replacement.Spec.ReplacementSpec.Priority; priority != nil {
+			key.priority = *priority
+		}
+		counts[key]++
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(replacementsDesc, prometheus.GaugeValue, float64(count), string(key.phase), strconv.Itoa(key.priority))
+	}
+}
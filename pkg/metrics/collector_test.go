@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+)
+
+// gaugeValues drains the channel and returns the value of each metric keyed by
+// its label values joined with a slash
+func gaugeValues(ch chan prometheus.Metric) map[string]float64 {
+	close(ch)
+	values := make(map[string]float64)
+	for metric := range ch {
+		m := &dto.Metric{}
+		Expect(metric.Write(m)).To(Succeed())
+		key := ""
+		for i, label := range m.GetLabel() {
+			if i > 0 {
+				key += "/"
+			}
+			key += label.GetValue()
+		}
+		values[key] = m.GetGauge().GetValue()
+	}
+	return values
+}
+
+var _ = Describe("collector", func() {
+	var ch chan prometheus.Metric
+
+	BeforeEach(func() {
+		ch = make(chan prometheus.Metric, 10)
+	})
+
+	Context("collectRollouts", func() {
+		BeforeEach(func() {
+			rollouts := []navarchosv1alpha1.NodeRollout{
+				{Status: navarchosv1alpha1.NodeRolloutStatus{Phase: navarchosv1alpha1.RolloutPhaseInProgress}},
+				{Status: navarchosv1alpha1.NodeRolloutStatus{Phase: navarchosv1alpha1.RolloutPhaseCompleted}},
+				{Status: navarchosv1alpha1.NodeRolloutStatus{Phase: navarchosv1alpha1.RolloutPhaseCompleted}},
+			}
+			collectRollouts(ch, rollouts)
+		})
+
+		It("counts the NodeRollouts by phase", func() {
+			Expect(gaugeValues(ch)).To(Equal(map[string]float64{
+				"InProgress": 1,
+				"Completed":  2,
+			}))
+		})
+	})
+
+	Context("collectReplacements", func() {
+		BeforeEach(func() {
+			high := 10
+			replacements := []navarchosv1alpha1.NodeReplacement{
+				{Status: navarchosv1alpha1.NodeReplacementStatus{Phase: navarchosv1alpha1.ReplacementPhaseNew}},
+				{Status: navarchosv1alpha1.NodeReplacementStatus{Phase: navarchosv1alpha1.ReplacementPhaseNew}},
+				{
+					Spec: navarchosv1alpha1.NodeReplacementSpec{
+						ReplacementSpec: navarchosv1alpha1.ReplacementSpec{Priority: &high},
+					},
+					Status: navarchosv1alpha1.NodeReplacementStatus{Phase: navarchosv1alpha1.ReplacementPhaseNew},
+				},
+				{
+					Spec: navarchosv1alpha1.NodeReplacementSpec{
+						ReplacementSpec: navarchosv1alpha1.ReplacementSpec{Priority: &high},
+					},
+					Status: navarchosv1alpha1.NodeReplacementStatus{Phase: navarchosv1alpha1.ReplacementPhaseInProgress},
+				},
+			}
+			collectReplacements(ch, replacements)
+		})
+
+		It("counts the NodeReplacements by phase and priority", func() {
+			Expect(gaugeValues(ch)).To(Equal(map[string]float64{
+				"New/0":         2,
+				"New/10":        1,
+				"InProgress/10": 1,
+			}))
+		})
+	})
+})
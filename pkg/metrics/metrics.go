@@ -0,0 +1,85 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// DrainDuration is a histogram of the time taken to drain the pods of a
+	// node, from the start of the first eviction wave until the final wave
+	// completed, labelled by whether the drain succeeded or was given up on
+	DrainDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "navarchos_nodereplacement_drain_duration_seconds",
+		Help:    "Time taken to evict or delete the pods of a node, across every eviction wave and attempt",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"result"})
+
+	// CordonToCompleteDuration is a histogram of the time between cordoning a
+	// node and its NodeReplacement finishing, labelled by the final phase
+	CordonToCompleteDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "navarchos_nodereplacement_cordon_to_complete_seconds",
+		Help:    "Time between cordoning a node and its NodeReplacement finishing",
+		Buckets: prometheus.ExponentialBuckets(30, 2, 12),
+	}, []string{"phase"})
+
+	// EvictedPods counts the pods evicted or deleted by the controller
+	EvictedPods = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "navarchos_evicted_pods_total",
+		Help: "Total number of pods evicted or deleted while draining nodes",
+	}, []string{"namespace"})
+
+	// FailedPods counts the pods the controller failed to evict
+	FailedPods = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "navarchos_failed_pods_total",
+		Help: "Total number of pods that could not be evicted while draining nodes",
+	}, []string{"namespace"})
+
+	// IgnoredPods counts the pods the controller does not evict
+	IgnoredPods = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "navarchos_ignored_pods_total",
+		Help: "Total number of pods ignored while draining nodes",
+	}, []string{"namespace"})
+
+	// Requeues counts the NodeReplacements requeued, labelled by a short
+	// reason
+	Requeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "navarchos_nodereplacement_requeues_total",
+		Help: "Total number of times a NodeReplacement was requeued",
+	}, []string{"reason"})
+
+	// GCDeletionErrors counts the errors deleting finished NodeRollouts,
+	// labelled by the controller garbage collecting them
+	GCDeletionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "navarchos_gc_deletion_errors_total",
+		Help: "Total number of errors deleting finished NodeRollouts",
+	}, []string{"controller"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		DrainDuration,
+		CordonToCompleteDuration,
+		EvictedPods,
+		FailedPods,
+		IgnoredPods,
+		Requeues,
+		GCDeletionErrors,
+	)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pusher/navarchos/pkg/provider"
+)
+
+// Name is the name the fake Provider is registered under
+const Name = "fake"
+
+// Register makes the fake Provider available under Name. It reports unknown
+// instances as terminated, so it must only be registered by tests and never
+// by the controller binary
+func Register() {
+	provider.Register(Name, func() (provider.Provider, error) {
+		return NewProvider(), nil
+	})
+}
+
+// Provider is an in-memory implementation of provider.Provider. Instances must
+// be added with AddInstance before they can be terminated. Terminating an
+// instance moves it to the Terminating state, it stays there until
+// CompleteTermination is called for it.
+type Provider struct {
+	sync.RWMutex
+	instances map[string]provider.InstanceState
+
+	// TerminateErr, if set, is returned by every call to TerminateInstance
+	TerminateErr error
+}
+
+var _ provider.Provider = &Provider{}
+
+// NewProvider creates a new, empty, fake Provider
+func NewProvider() *Provider {
+	return &Provider{
+		instances: make(map[string]provider.InstanceState),
+	}
+}
+
+// AddInstance adds a running instance with the given providerID
+func (p *Provider) AddInstance(providerID string) {
+	p.Lock()
+	defer p.Unlock()
+	p.instances[providerID] = provider.InstanceStateRunning
+}
+
+// CompleteTermination moves a terminating instance to the Terminated state
+func (p *Provider) CompleteTermination(providerID string) error {
+	p.Lock()
+	defer p.Unlock()
+	state, ok := p.instances[providerID]
+	if !ok {
+		return fmt.Errorf("instance %q not found", providerID)
+	}
+	if state != provider.InstanceStateTerminating {
+		return fmt.Errorf("instance %q is not terminating", providerID)
+	}
+	p.instances[providerID] = provider.InstanceStateTerminated
+	return nil
+}
+
+// TerminateInstance implements the provider.Provider interface
+func (p *Provider) TerminateInstance(providerID string) error {
+	p.Lock()
+	defer p.Unlock()
+	if p.TerminateErr != nil {
+		return p.TerminateErr
+	}
+
+	state, ok := p.instances[providerID]
+	if !ok {
+		return fmt.Errorf("instance %q not found", providerID)
+	}
+	if state == provider.InstanceStateRunning {
+		p.instances[providerID] = provider.InstanceStateTerminating
+	}
+	return nil
+}
+
+// InstanceState implements the provider.Provider interface
+func (p *Provider) InstanceState(providerID string) (provider.InstanceState, error) {
+	p.RLock()
+	defer p.RUnlock()
+	state, ok := p.instances[providerID]
+	if !ok {
+		return provider.InstanceStateTerminated, nil
+	}
+	return state, nil
+}
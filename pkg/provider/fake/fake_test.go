@@ -0,0 +1,77 @@
+package fake
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pusher/navarchos/pkg/provider"
+)
+
+var _ = Describe("fake provider", func() {
+	var p *Provider
+	const providerID = "fake:///instance-1"
+
+	BeforeEach(func() {
+		p = NewProvider()
+		p.AddInstance(providerID)
+	})
+
+	It("is registered as a provider", func() {
+		registered, err := provider.New(Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(registered).To(BeAssignableToTypeOf(&Provider{}))
+	})
+
+	It("reports added instances as running", func() {
+		Expect(p.InstanceState(providerID)).To(Equal(provider.InstanceStateRunning))
+	})
+
+	It("reports unknown instances as terminated", func() {
+		Expect(p.InstanceState("fake:///unknown")).To(Equal(provider.InstanceStateTerminated))
+	})
+
+	Context("TerminateInstance", func() {
+		var terminateErr error
+
+		JustBeforeEach(func() {
+			terminateErr = p.TerminateInstance(providerID)
+		})
+
+		It("moves the instance to terminating", func() {
+			Expect(p.InstanceState(providerID)).To(Equal(provider.InstanceStateTerminating))
+		})
+
+		It("does not return an error", func() {
+			Expect(terminateErr).ToNot(HaveOccurred())
+		})
+
+		It("can be called again while the instance is terminating", func() {
+			Expect(p.TerminateInstance(providerID)).To(Succeed())
+			Expect(p.InstanceState(providerID)).To(Equal(provider.InstanceStateTerminating))
+		})
+
+		It("moves the instance to terminated once termination completes", func() {
+			Expect(p.CompleteTermination(providerID)).To(Succeed())
+			Expect(p.InstanceState(providerID)).To(Equal(provider.InstanceStateTerminated))
+		})
+
+		Context("when TerminateErr is set", func() {
+			BeforeEach(func() {
+				p.TerminateErr = errors.New("api unavailable")
+			})
+
+			It("returns the error", func() {
+				Expect(terminateErr).To(MatchError("api unavailable"))
+			})
+
+			It("does not change the instance state", func() {
+				Expect(p.InstanceState(providerID)).To(Equal(provider.InstanceStateRunning))
+			})
+		})
+	})
+
+	It("returns an error when terminating an unknown instance", func() {
+		Expect(p.TerminateInstance("fake:///unknown")).ToNot(Succeed())
+	})
+})
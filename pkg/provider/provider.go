@@ -0,0 +1,94 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// InstanceState describes the lifecycle state of the instance backing a Node
+type InstanceState string
+
+// The following InstanceStates enumerate all possible InstanceStates
+const (
+	InstanceStateRunning     InstanceState = "Running"
+	InstanceStateTerminating InstanceState = "Terminating"
+	InstanceStateTerminated  InstanceState = "Terminated"
+)
+
+// Provider is an interface to the infrastructure hosting the cluster's Nodes.
+// Instances are identified by the Node's spec.providerID.
+type Provider interface {
+	// TerminateInstance requests that the instance with the given providerID be
+	// terminated. Calling it for an instance that is already terminating must
+	// not return an error.
+	TerminateInstance(providerID string) error
+
+	// InstanceState returns the current state of the instance with the given
+	// providerID. Instances that no longer exist should be reported as
+	// InstanceStateTerminated.
+	InstanceState(providerID string) (InstanceState, error)
+}
+
+// Constructor builds a Provider
+type Constructor func() (Provider, error)
+
+var (
+	constructorsMutex sync.RWMutex
+	constructors      = make(map[string]Constructor)
+)
+
+// Register makes a Provider available by the given name. It is intended to be
+// called from the init function of the package implementing the Provider.
+func Register(name string, constructor Constructor) {
+	constructorsMutex.Lock()
+	defer constructorsMutex.Unlock()
+	if _, exists := constructors[name]; exists {
+		panic(fmt.Sprintf("provider %q registered twice", name))
+	}
+	constructors[name] = constructor
+}
+
+// New returns the Provider registered with the given name. An empty name
+// returns a nil Provider, which disables instance termination.
+func New(name string) (Provider, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	constructorsMutex.RLock()
+	constructor, ok := constructors[name]
+	constructorsMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q, must be one of %v", name, Names())
+	}
+	return constructor()
+}
+
+// Names returns the sorted names of all registered providers
+func Names() []string {
+	constructorsMutex.RLock()
+	defer constructorsMutex.RUnlock()
+	names := []string{}
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// MaxSearchYears is how far into the future Next looks for a matching time.
+// It mirrors the limit built into github.com/robfig/cron, which gives up on
+// expressions such as "0 0 30 2 *" after five years
+const MaxSearchYears = 5
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	schedule cron.Schedule
+}
+
+// Parse parses a standard five field cron expression. Names of months and
+// days of the week, ranges, lists, steps and the "@yearly" family of macros
+// are supported
+func Parse(spec string) (*Schedule, error) {
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", spec, err)
+	}
+	return &Schedule{schedule: s}, nil
+}
+
+// Next returns the first time matching the schedule strictly after t, in the
+// location of t. It returns the zero time if nothing matches within the next
+// MaxSearchYears years
+func (s *Schedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t)
+}
@@ -0,0 +1,65 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cron schedules", func() {
+	// A Friday
+	now := time.Date(2019, time.October, 18, 13, 7, 30, 0, time.UTC)
+
+	nextCases := []struct {
+		description string
+		spec        string
+		expected    time.Time
+	}{
+		{"every 15 minutes", "*/15 * * * *", time.Date(2019, time.October, 18, 13, 15, 0, 0, time.UTC)},
+		{"every night", "0 22 * * *", time.Date(2019, time.October, 18, 22, 0, 0, 0, time.UTC)},
+		{"on weekends", "0 0 * * SAT,SUN", time.Date(2019, time.October, 19, 0, 0, 0, 0, time.UTC)},
+		{"monthly", "@monthly", time.Date(2019, time.November, 1, 0, 0, 0, 0, time.UTC)},
+		{"on either the day of month or the day of week", "0 0 13 * FRI", time.Date(2019, time.October, 25, 0, 0, 0, 0, time.UTC)},
+		{"with a stepped value during working days", "5/20 9-17 * * MON-FRI", time.Date(2019, time.October, 18, 13, 25, 0, 0, time.UTC)},
+	}
+	for _, c := range nextCases {
+		c := c
+		It(fmt.Sprintf("returns the next time %s", c.description), func() {
+			s, err := Parse(c.spec)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Next(now)).To(Equal(c.expected))
+		})
+	}
+
+	It("returns the zero time if nothing matches", func() {
+		s, err := Parse("0 0 30 2 *")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Next(now).IsZero()).To(BeTrue())
+	})
+
+	It("evaluates the schedule in the location of the time", func() {
+		loc, err := time.LoadLocation("America/New_York")
+		Expect(err).ToNot(HaveOccurred())
+		s, err := Parse("0 22 * * *")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Next(now.In(loc))).To(Equal(time.Date(2019, time.October, 18, 22, 0, 0, 0, loc)))
+	})
+
+	invalidCases := map[string]string{
+		"too few fields":         "* * * *",
+		"a value out of range":   "60 * * * *",
+		"a day of month of zero": "* * 0 * *",
+		"a step of zero":         "*/0 * * * *",
+		"an unknown name":        "* * * * FUN",
+		"a reversed range":       "5-1 * * * *",
+	}
+	for description, spec := range invalidCases {
+		spec := spec
+		It(fmt.Sprintf("rejects an expression with %s", description), func() {
+			_, err := Parse(spec)
+			Expect(err).To(HaveOccurred())
+		})
+	}
+})
@@ -0,0 +1,69 @@
+/*
+Copyright 2019 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+)
+
+// Windows reports whether any of the maintenance windows is open at the given
+// time, and when the next window starts after it. The next start is the zero
+// time if none of the windows start again within the next MaxSearchYears years
+func Windows(windows []navarchosv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	var open bool
+	var next time.Time
+	for _, window := range windows {
+		windowOpen, windowNext, err := nextWindow(window, now)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		open = open || windowOpen
+		if !windowNext.IsZero() && (next.IsZero() || windowNext.Before(next)) {
+			next = windowNext
+		}
+	}
+	return open, next, nil
+}
+
+// nextWindow reports whether the maintenance window is open at the given time
+// and when it next starts after it
+func nextWindow(window navarchosv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	s, err := Parse(window.Schedule)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid maintenance window schedule %q: %v", window.Schedule, err)
+	}
+	if window.Duration.Duration <= 0 {
+		return false, time.Time{}, fmt.Errorf("invalid maintenance window duration %q: must be positive", window.Duration.Duration)
+	}
+	tz := window.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid maintenance window time zone %q: %v", tz, err)
+	}
+
+	now = now.In(loc)
+	// The window is open if it started within the last Duration
+	start := s.Next(now.Add(-window.Duration.Duration))
+	open := !start.IsZero() && !start.After(now)
+	return open, s.Next(now), nil
+}
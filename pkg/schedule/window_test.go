@@ -0,0 +1,110 @@
+package schedule
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	navarchosv1alpha1 "github.com/pusher/navarchos/pkg/apis/navarchos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("maintenance windows", func() {
+	var windows []navarchosv1alpha1.MaintenanceWindow
+	var now time.Time
+	var open bool
+	var next time.Time
+	var windowsErr error
+
+	BeforeEach(func() {
+		// Nights from 10pm to 6am in London
+		windows = []navarchosv1alpha1.MaintenanceWindow{
+			{
+				Schedule: "0 22 * * *",
+				Duration: metav1.Duration{Duration: 8 * time.Hour},
+				TimeZone: "Europe/London",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		open, next, windowsErr = Windows(windows, now)
+	})
+
+	Context("during the day", func() {
+		BeforeEach(func() {
+			// 2pm BST
+			now = time.Date(2019, time.October, 18, 13, 0, 0, 0, time.UTC)
+		})
+
+		It("is closed", func() {
+			Expect(windowsErr).ToNot(HaveOccurred())
+			Expect(open).To(BeFalse())
+		})
+
+		It("returns the start of the window that evening", func() {
+			Expect(next.Equal(time.Date(2019, time.October, 18, 21, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+	})
+
+	Context("during the night", func() {
+		BeforeEach(func() {
+			// 3am BST
+			now = time.Date(2019, time.October, 18, 2, 0, 0, 0, time.UTC)
+		})
+
+		It("is open", func() {
+			Expect(windowsErr).ToNot(HaveOccurred())
+			Expect(open).To(BeTrue())
+		})
+	})
+
+	Context("when the window has just closed", func() {
+		BeforeEach(func() {
+			// 6am BST
+			now = time.Date(2019, time.October, 18, 5, 0, 0, 0, time.UTC)
+		})
+
+		It("is closed", func() {
+			Expect(open).To(BeFalse())
+		})
+	})
+
+	Context("with several windows", func() {
+		BeforeEach(func() {
+			now = time.Date(2019, time.October, 18, 13, 0, 0, 0, time.UTC)
+			windows = append(windows, navarchosv1alpha1.MaintenanceWindow{
+				Schedule: "0 12 * * FRI",
+				Duration: metav1.Duration{Duration: 2 * time.Hour},
+			})
+		})
+
+		It("is open if any window is open", func() {
+			Expect(open).To(BeTrue())
+		})
+
+		It("returns the earliest next start", func() {
+			Expect(next.Equal(time.Date(2019, time.October, 18, 21, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+	})
+
+	Context("with an invalid time zone", func() {
+		BeforeEach(func() {
+			windows[0].TimeZone = "Nowhere/Special"
+		})
+
+		It("returns an error", func() {
+			Expect(windowsErr).To(HaveOccurred())
+		})
+	})
+
+	Context("with a duration of zero", func() {
+		BeforeEach(func() {
+			windows[0].Duration = metav1.Duration{}
+		})
+
+		It("returns an error", func() {
+			Expect(windowsErr).To(HaveOccurred())
+		})
+	})
+})
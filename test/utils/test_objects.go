@@ -81,6 +81,42 @@ var ExampleNodeRollout = &navarchosv1alpha1.NodeRollout{
 	},
 }
 
+// ExampleNodeRolloutSchedule represents an example NodeRolloutSchedule for use
+// in tests
+var ExampleNodeRolloutSchedule = &navarchosv1alpha1.NodeRolloutSchedule{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "example",
+	},
+	Spec: navarchosv1alpha1.NodeRolloutScheduleSpec{
+		Schedule: "0 2 * * *",
+		RolloutTemplate: navarchosv1alpha1.NodeRolloutTemplateSpec{
+			Labels: map[string]string{
+				"schedule": "example",
+			},
+			Spec: *ExampleNodeRollout.Spec.DeepCopy(),
+		},
+	},
+}
+
+// ExampleNodeFleetPolicy represents an example NodeFleetPolicy for use in
+// tests
+var ExampleNodeFleetPolicy = &navarchosv1alpha1.NodeFleetPolicy{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "example",
+	},
+	Spec: navarchosv1alpha1.NodeFleetPolicySpec{
+		NodeSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"node-role.kubernetes.io/worker": "true",
+			},
+		},
+		KubeletVersion: "v1.14.3",
+		Replacement: navarchosv1alpha1.ReplacementSpec{
+			Priority: intPtr(10),
+		},
+	},
+}
+
 // ExampleNodeMaster1 is an example Node for use in tests
 var ExampleNodeMaster1 = &corev1.Node{
 	ObjectMeta: metav1.ObjectMeta{